@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/auth"
+	"github.com/Nizom98/wallet/internal/buisness/dataset"
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+const (
+	exportCommand  = "export"
+	importCommand  = "import"
+	datasetTimeout = 10 * time.Minute
+)
+
+// runExport команда export: выгрузка всех кошельков работающего сервиса в файл.
+// Файл проверяется по контрольным суммам до записи, stdout - если файл не задан.
+func runExport(args []string) {
+	flags := flag.NewFlagSet(exportCommand, flag.ExitOnError)
+	addr := flags.String("addr", "http://127.0.0.1"+appAddr, "service address")
+	apiKey := flags.String("api-key", os.Getenv("WALLET_API_KEY"), "API key with dataset:export permission")
+	out := flags.String("o", "", "output file, stdout if empty")
+	_ = flags.Parse(args)
+
+	data, err := requestExport(*addr, *apiKey)
+	if err != nil {
+		exitWith(err)
+	}
+	_, summary, err := dataset.Read(bytes.NewReader(data))
+	if err != nil {
+		exitWith(fmt.Errorf("export is corrupted: %w", err))
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = writeFileAtomic(*out, data)
+	}
+	if err != nil {
+		exitWith(err)
+	}
+	fmt.Fprintf(os.Stderr, "exported %d wallets, %d entries\n", summary.Wallets, summary.Entries)
+}
+
+// runImport команда import: загрузка файла выгрузки в работающий сервис.
+// Файл проверяется локально, затем сервис проверяет его повторно и загружает целиком.
+func runImport(args []string) {
+	flags := flag.NewFlagSet(importCommand, flag.ExitOnError)
+	addr := flags.String("addr", "http://127.0.0.1"+appAddr, "service address")
+	apiKey := flags.String("api-key", os.Getenv("WALLET_API_KEY"), "API key with dataset:import permission")
+	in := flags.String("i", "", "dataset file, stdin if empty")
+	_ = flags.Parse(args)
+
+	var (
+		data []byte
+		err  error
+	)
+	if *in == "" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		exitWith(fmt.Errorf("cannot read dataset: %w", err))
+	}
+
+	ds, _, err := dataset.Read(bytes.NewReader(data))
+	if err == nil {
+		err = dataset.Validate(ds)
+	}
+	if err != nil {
+		exitWith(err)
+	}
+
+	summary, err := requestImport(*addr, *apiKey, data)
+	if err != nil {
+		exitWith(err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(summary)
+}
+
+// requestExport получаем выгрузку с сервиса addr.
+func requestExport(addr, apiKey string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, addr+"/admin/export/", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(auth.HeaderAPIKey, apiKey)
+
+	client := &http.Client{Timeout: datasetTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot request export: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("export failed (status %d): %s", resp.StatusCode, errMessage(resp.Body))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read export: %w", err)
+	}
+	return data, nil
+}
+
+// requestImport загружаем выгрузку data в сервис addr.
+func requestImport(addr, apiKey string, data []byte) (*models.DatasetSummary, error) {
+	req, err := http.NewRequest(http.MethodPost, addr+"/admin/import/", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(auth.HeaderAPIKey, apiKey)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	client := &http.Client{Timeout: datasetTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot request import: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Success    bool                   `json:"success"`
+		ErrMessage string                 `json:"err_message"`
+		Data       *models.DatasetSummary `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if !body.Success || body.Data == nil {
+		return nil, fmt.Errorf("import failed (status %d): %s", resp.StatusCode, body.ErrMessage)
+	}
+	return body.Data, nil
+}
+
+// errMessage текст ошибки из ответа сервиса.
+func errMessage(r io.Reader) string {
+	var body struct {
+		ErrMessage string `json:"err_message"`
+	}
+	err := json.NewDecoder(r).Decode(&body)
+	if err != nil {
+		return err.Error()
+	}
+	return body.ErrMessage
+}
+
+// writeFileAtomic пишем файл через временный файл, чтобы не оставить обрезанную выгрузку.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write file: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func exitWith(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -1,14 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"net/http"
+	"os"
+	"time"
 
+	"github.com/Nizom98/wallet/internal/access"
 	"github.com/Nizom98/wallet/internal/api/rest"
+	"github.com/Nizom98/wallet/internal/auth"
+	"github.com/Nizom98/wallet/internal/buisness/audit"
+	"github.com/Nizom98/wallet/internal/buisness/dataset"
+	"github.com/Nizom98/wallet/internal/buisness/fee"
 	"github.com/Nizom98/wallet/internal/buisness/notify"
+	"github.com/Nizom98/wallet/internal/buisness/reconcile"
+	"github.com/Nizom98/wallet/internal/buisness/schedule"
+	"github.com/Nizom98/wallet/internal/buisness/statement"
 	"github.com/Nizom98/wallet/internal/buisness/wallet"
 	"github.com/Nizom98/wallet/internal/clients/nsq"
+	"github.com/Nizom98/wallet/internal/clients/tracing"
+	"github.com/Nizom98/wallet/internal/config"
+	"github.com/Nizom98/wallet/internal/models"
 	"github.com/Nizom98/wallet/internal/repository"
 	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,35 +35,208 @@ const (
 	nsqTarget = "127.0.0.1:9999"
 	appAddr   = ":80"
 	logLevel  = log.DebugLevel
+
+	serviceName = "wallet"
+
+	defaultConfigPath = "config.json"
+
+	defaultSchedulePoll = time.Minute
+	defaultReconcile    = time.Hour
+	defaultSnapshot     = time.Hour
+	defaultHoldExpiry   = time.Minute
+
+	// persistenceWAL режим хранения кошельков снимками и журналом изменений(по умолчанию)
+	persistenceWAL = "wal"
+	// persistenceEvents режим хранения кошельков потоком событий
+	persistenceEvents = "events"
+	// persistenceRedis режим хранения кошельков в Redis, общем для экземпляров сервиса
+	persistenceRedis = "redis"
+
+	// feeWalletName и systemOwner кошелек сбора комиссий, создаваемый при запуске
+	feeWalletName = "fees"
+	systemOwner   = "system"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case reconcileCommand:
+			runReconcile(os.Args[2:])
+			return
+		case exportCommand:
+			runExport(os.Args[2:])
+			return
+		case importCommand:
+			runImport(os.Args[2:])
+			return
+		case rebuildCommand:
+			runRebuild(os.Args[2:])
+			return
+		}
+	}
+
+	configPath := flag.String("config", defaultConfigPath, "path to json config")
+	flag.Parse()
+
 	log.SetLevel(logLevel)
 
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		panic(err)
+	}
+
+	tracer, err := tracing.NewProvider(serviceName, cfg.Tracing.Exporter, os.Stdout)
+	if err != nil {
+		panic(err)
+	}
+	defer tracer.Stop(context.Background())
+
 	nsq, err := nsq.NewClient(nsqTopic, nsqTarget)
 	if err != nil {
 		panic(err)
 	}
 	defer nsq.Stop()
 
-	repoWallet := repository.NewRepo()
-	manWallet := wallet.NewManager(repoWallet)
-	manNotify := notify.NewManager(nsq, manWallet)
+	auditStore, err := repository.NewAuditStore(cfg.Audit.LogFile)
+	if err != nil {
+		panic(err)
+	}
+	defer auditStore.Close()
+	auditRecorder := audit.NewRecorder(auditStore)
+
+	policy, err := access.LoadPolicy(cfg.Access.PolicyFile, access.WithDeniedRecorder(auditRecorder))
+	if err != nil {
+		panic(err)
+	}
+
+	// shared хранилище общее для нескольких экземпляров сервиса
+	shared := cfg.Persistence.Mode == persistenceRedis
+	if shared && len(cfg.Fees.Operations) > 0 && cfg.Fees.WalletID == "" {
+		panic(errSharedFeeWallet)
+	}
+
+	repoWallet, closeWallets, err := openWallets(cfg.Persistence)
+	if err != nil {
+		panic(err)
+	}
+	defer closeWallets()
+	feeRules := cfg.Fees
+	if len(feeRules.Operations) > 0 && feeRules.WalletID == "" {
+		feeRules.WalletID = feeWallet(repoWallet)
+	}
+	fees, err := fee.NewEngine(feeRules)
+	if err != nil {
+		panic(err)
+	}
+
+	manWallet := wallet.NewManager(repoWallet,
+		wallet.WithPolicy(policy),
+		wallet.WithLimits(cfg.Limits),
+		wallet.WithAmountPolicy(cfg.Amounts),
+		wallet.WithFees(fees),
+	)
+	manAudit := audit.NewManager(auditRecorder, manWallet, repoWallet)
+	manNotify := notify.NewManager(nsq, manAudit)
+
+	authn, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		panic(err)
+	}
+
+	scheduleOpts := []schedule.Option{
+		schedule.WithPolicy(policy),
+		schedule.WithPrincipals(authn),
+		schedule.WithFailureNotifier(manNotify),
+	}
+	if cfg.Scheduler.MaxRetries > 0 {
+		scheduleOpts = append(scheduleOpts, schedule.WithRetry(cfg.Scheduler.MaxRetries, time.Duration(cfg.Scheduler.RetryBackoffSeconds)*time.Second))
+	}
+	schedules, err := openSchedules(cfg.Persistence, repoWallet)
+	if err != nil {
+		panic(err)
+	}
+	scheduler := schedule.NewScheduler(schedules, manNotify, repoWallet, scheduleOpts...)
+	schedulePoll := defaultSchedulePoll
+	if cfg.Scheduler.PollSeconds > 0 {
+		schedulePoll = time.Duration(cfg.Scheduler.PollSeconds) * time.Second
+	}
+	ctxJobs, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	// при общем хранилище каждую попытку перевода выполняет один экземпляр
+	go scheduler.Run(ctxJobs, schedulePoll)
+
+	if runner, ok := repoWallet.(snapshotRunner); ok && cfg.Persistence.Dir != "" && cfg.Persistence.SnapshotIntervalSeconds >= 0 {
+		snapshotEvery := defaultSnapshot
+		if cfg.Persistence.SnapshotIntervalSeconds > 0 {
+			snapshotEvery = time.Duration(cfg.Persistence.SnapshotIntervalSeconds) * time.Second
+		}
+		go runner.RunSnapshots(ctxJobs, snapshotEvery)
+	}
+
+	// снятие истекших блокировок идемпотентно, поэтому при общем хранилище идет во всех экземплярах
+	if expirer, ok := repoWallet.(holdExpirer); ok {
+		go expirer.RunHoldExpiry(ctxJobs, defaultHoldExpiry)
+	}
 
-	handler, err := rest.NewHandler(manNotify, repoWallet)
+	reconciler := reconcile.NewReconciler(repoWallet)
+	if shared && cfg.Reconcile.IntervalSeconds >= 0 {
+		log.Warn("periodic reconcile is disabled for shared storage: run it on request")
+	} else if cfg.Reconcile.IntervalSeconds >= 0 {
+		reconcileEvery := defaultReconcile
+		if cfg.Reconcile.IntervalSeconds > 0 {
+			reconcileEvery = time.Duration(cfg.Reconcile.IntervalSeconds) * time.Second
+		}
+		go reconciler.RunEvery(ctxJobs, reconcileEvery, cfg.Reconcile.Correct)
+	}
+
+	statements := statement.NewGenerator(manNotify, repoWallet, statement.WithAmountPolicy(cfg.Amounts))
+	backup := dataset.NewBackup(repoWallet)
+
+	handler, err := rest.NewHandler(manNotify, repoWallet, authn, policy, auditStore, scheduler, reconciler, statements, backup)
 	if err != nil {
 		panic(err)
 	}
 
+	// secured идентификатор запроса, трассировка, лог, аутентификация и проверка права perm для маршрута.
+	secured := func(perm models.Permission, next http.HandlerFunc) http.HandlerFunc {
+		return handler.MiddlewareRequestID(handler.MiddlewareTrace(handler.MiddlewareLog(handler.MiddlewareAuth(handler.MiddlewareAccess(perm, next)))))
+	}
+	// securedDataset как secured, но без тела запроса в логе: в нем все данные кошельков.
+	securedDataset := func(perm models.Permission, next http.HandlerFunc) http.HandlerFunc {
+		return handler.MiddlewareRequestID(handler.MiddlewareTrace(handler.MiddlewareLogNoBody(handler.MiddlewareAuth(handler.MiddlewareAccess(perm, next)))))
+	}
+
 	r := mux.NewRouter()
-	r.HandleFunc("/wallet/", handler.MiddlewareLog(handler.WalletCreateHandler)).Methods(http.MethodPost)
-	r.HandleFunc("/wallets/{id}/", handler.MiddlewareLog(handler.WalletByIDHandler)).Methods(http.MethodGet)
-	r.HandleFunc("/wallets/", handler.MiddlewareLog(handler.WalletListHandler)).Methods(http.MethodGet)
-	r.HandleFunc("/wallets/{id}/", handler.MiddlewareLog(handler.WalletUpdateHandler)).Methods(http.MethodPut)
-	r.HandleFunc("/wallets/{id}/", handler.MiddlewareLog(handler.WalletDeactivateHandler)).Methods(http.MethodDelete)
-	r.HandleFunc("/wallets/{id}/deposit/", handler.MiddlewareLog(handler.WalletDepositHandler)).Methods(http.MethodPost)
-	r.HandleFunc("/wallets/{id}/withdraw/", handler.MiddlewareLog(handler.WalletWithdrawHandler)).Methods(http.MethodPost)
-	r.HandleFunc("/wallets/{id}/transfer/", handler.MiddlewareLog(handler.WalletTransferHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/wallet/", secured(models.PermWalletCreate, handler.WalletCreateHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/wallets/bulk/", secured(models.PermWalletCreate, handler.WalletCreateBulkHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/wallets/{id}/", secured(models.PermWalletRead, handler.WalletByIDHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/wallets/", secured(models.PermWalletList, handler.WalletListHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/wallets/{id}/balance/", secured(models.PermWalletRead, handler.WalletBalanceHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/wallets/{id}/statement/", secured(models.PermWalletRead, handler.WalletStatementHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/balances/", secured(models.PermWalletList, handler.BalancesAtHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/wallets/{id}/", secured(models.PermWalletRename, handler.WalletUpdateHandler)).Methods(http.MethodPut)
+	r.HandleFunc("/wallets/{id}/", secured(models.PermWalletDeactivate, handler.WalletDeactivateHandler)).Methods(http.MethodDelete)
+	r.HandleFunc("/wallets/{id}/deposit/", secured(models.PermWalletDeposit, handler.WalletDepositHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/wallets/{id}/withdraw/", secured(models.PermWalletWithdraw, handler.WalletWithdrawHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/wallets/{id}/transfer/", secured(models.PermWalletTransfer, handler.WalletTransferHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/wallets/{id}/credit-limit/", secured(models.PermWalletCredit, handler.WalletCreditLimitHandler)).Methods(http.MethodPut)
+	r.HandleFunc("/wallets/{id}/holds/", secured(models.PermWalletHold, handler.HoldCreateHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/holds/{id}/capture/", secured(models.PermWalletHold, handler.HoldCaptureHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/holds/{id}/release/", secured(models.PermWalletHold, handler.HoldReleaseHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/operations/{id}/reverse/", secured(models.PermWalletReverse, handler.OperationReverseHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/transfers/batch/", secured(models.PermWalletTransfer, handler.TransferBatchHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/reconcile/", secured(models.PermReconcile, handler.ReconcileReportHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/reconcile/", secured(models.PermReconcile, handler.ReconcileRunHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/export/", securedDataset(models.PermDatasetExport, handler.DatasetExportHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/import/", securedDataset(models.PermDatasetImport, handler.DatasetImportHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/rebuild/", secured(models.PermRebuild, handler.RebuildHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/audit/", secured(models.PermAuditRead, handler.AuditListHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/audit/verify/", secured(models.PermAuditRead, handler.AuditVerifyHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/schedules/", secured(models.PermWalletTransfer, handler.ScheduleCreateHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/schedules/", secured(models.PermWalletTransfer, handler.ScheduleListHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/schedules/{id}/pause/", secured(models.PermWalletTransfer, handler.SchedulePauseHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/schedules/{id}/resume/", secured(models.PermWalletTransfer, handler.ScheduleResumeHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/schedules/{id}/", secured(models.PermWalletTransfer, handler.ScheduleCancelHandler)).Methods(http.MethodDelete)
 
 	log.Infof("app started on: %s", appAddr)
 	defer func() {
@@ -54,3 +245,122 @@ func main() {
 	err = http.ListenAndServe(appAddr, r)
 	panic(err)
 }
+
+var (
+	// errSharedFeeWallet кошелек комиссий нельзя создавать при запуске: экземпляры создали бы каждый свой.
+	errSharedFeeWallet = errors.New("fees.wallet_id is required when persistence.mode is redis")
+	// errEventsDir поток событий хранится только на диске.
+	errEventsDir = errors.New("persistence.dir is required when persistence.mode is events")
+)
+
+// walletStore хранилище кошельков сервиса.
+type walletStore interface {
+	models.WalletRepository
+	models.DatasetStore
+}
+
+// holdExpirer хранилище, периодически снимающее истекшие блокировки средств.
+type holdExpirer interface {
+	RunHoldExpiry(ctx context.Context, every time.Duration)
+}
+
+// snapshotRunner хранилище, периодически сохраняющее снимки на диск.
+type snapshotRunner interface {
+	RunSnapshots(ctx context.Context, every time.Duration)
+}
+
+// openWallets хранилище кошельков по настройкам хранения и функция его закрытия.
+// Без каталога и Redis кошельки хранятся только в памяти(только в режиме wal).
+func openWallets(cfg config.Persistence) (walletStore, func() error, error) {
+	switch cfg.Mode {
+	case "", persistenceWAL, persistenceEvents, persistenceRedis:
+	default:
+		return nil, nil, fmt.Errorf("unknown persistence.mode %q: expected %s, %s or %s", cfg.Mode, persistenceWAL, persistenceEvents, persistenceRedis)
+	}
+
+	if cfg.Mode == persistenceRedis {
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		err := client.Ping(context.Background()).Err()
+		if err != nil {
+			client.Close()
+			return nil, nil, fmt.Errorf("cannot connect to redis %s: %w", cfg.Redis.Addr, err)
+		}
+		repo := repository.NewRedisRepo(client, repository.RedisOptions{
+			Prefix:     cfg.Redis.Prefix,
+			MaxRetries: cfg.Redis.MaxRetries,
+		})
+		log.Infof("wallets are stored in redis %s", cfg.Redis.Addr)
+		return repo, client.Close, nil
+	}
+
+	if cfg.Mode == persistenceEvents {
+		if cfg.Dir == "" {
+			return nil, nil, errEventsDir
+		}
+		store, err := repository.OpenFileEventStore(cfg.Dir, repository.FsyncPolicy(cfg.Fsync))
+		if err != nil {
+			return nil, nil, err
+		}
+		repo, err := repository.NewEventRepo(store)
+		if err != nil {
+			store.Close()
+			return nil, nil, err
+		}
+		log.Infof("wallets rebuilt from events in %s: %d", cfg.Dir, len(repo.All()))
+		return repo, store.Close, nil
+	}
+
+	if cfg.Dir == "" {
+		return repository.NewRepo(), func() error { return nil }, nil
+	}
+
+	repo, err := repository.OpenRepo(repository.PersistOptions{
+		Dir:           cfg.Dir,
+		Fsync:         repository.FsyncPolicy(cfg.Fsync),
+		FsyncInterval: time.Duration(cfg.FsyncIntervalMs) * time.Millisecond,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Infof("wallets restored from %s: %d", cfg.Dir, len(repo.All()))
+	return repo, repo.Close, nil
+}
+
+// openSchedules хранилище регулярных переводов рядом с кошельками:
+// в Redis, в каталоге хранения или, без каталога, только в памяти.
+func openSchedules(cfg config.Persistence, repoWallet walletStore) (models.ScheduleStore, error) {
+	if repo, ok := repoWallet.(*repository.RedisRepository); ok {
+		return repo.Schedules(), nil
+	}
+	if cfg.Dir == "" {
+		return repository.NewScheduleStore(), nil
+	}
+
+	store, err := repository.OpenScheduleStore(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := store.List(models.ScheduleFilter{})
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("schedules restored from %s: %d", cfg.Dir, len(schedules))
+	return store, nil
+}
+
+// feeWallet кошелек сбора комиссий: сохраненный с прошлого запуска или новый.
+func feeWallet(repo models.WalletRepository) string {
+	for _, w := range repo.All() {
+		if w.Owner() == systemOwner && w.Name() == feeWalletName {
+			return w.ID()
+		}
+	}
+
+	id := repo.Create(feeWalletName, 0, true, systemOwner, nil).ID()
+	log.Infof("fee wallet created: %s", id)
+	return id
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/api/rest"
+	"github.com/Nizom98/wallet/internal/auth"
+)
+
+const (
+	rebuildCommand = "rebuild"
+	rebuildTimeout = 10 * time.Minute
+)
+
+// runRebuild команда rebuild: перестроение проекций работающего сервиса по всему потоку событий.
+// Проекции хранятся в памяти процесса сервиса, поэтому перестроение выполняется сервисом
+// через административный маршрут.
+func runRebuild(args []string) {
+	flags := flag.NewFlagSet(rebuildCommand, flag.ExitOnError)
+	addr := flags.String("addr", "http://127.0.0.1"+appAddr, "service address")
+	apiKey := flags.String("api-key", os.Getenv("WALLET_API_KEY"), "API key with projections:rebuild permission")
+	_ = flags.Parse(args)
+
+	result, err := requestRebuild(*addr, *apiKey)
+	if err != nil {
+		exitWith(err)
+	}
+	fmt.Fprintf(os.Stderr, "projections rebuilt: %d wallets\n", result.Wallets)
+}
+
+// requestRebuild перестраиваем проекции на сервисе addr.
+func requestRebuild(addr, apiKey string) (*rest.RebuildResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, addr+"/admin/rebuild/", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(auth.HeaderAPIKey, apiKey)
+
+	client := &http.Client{Timeout: rebuildTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot request rebuild: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Success    bool                  `json:"success"`
+		ErrMessage string                `json:"err_message"`
+		Data       *rest.RebuildResponse `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if !body.Success || body.Data == nil {
+		return nil, fmt.Errorf("rebuild failed (status %d): %s", resp.StatusCode, body.ErrMessage)
+	}
+	return body.Data, nil
+}
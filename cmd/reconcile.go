@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/auth"
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+const (
+	reconcileCommand = "reconcile"
+	reconcileTimeout = 5 * time.Minute
+	// exitMismatches код выхода команды, если найдены неисправленные расхождения.
+	exitMismatches = 2
+)
+
+// runReconcile команда reconcile: сверка балансов работающего сервиса.
+// Кошельки хранятся в памяти процесса сервиса, поэтому сверка выполняется сервисом
+// через административный маршрут, а команда печатает отчет в stdout.
+func runReconcile(args []string) {
+	flags := flag.NewFlagSet(reconcileCommand, flag.ExitOnError)
+	addr := flags.String("addr", "http://127.0.0.1"+appAddr, "service address")
+	apiKey := flags.String("api-key", os.Getenv("WALLET_API_KEY"), "API key with reconcile:run permission")
+	correct := flags.Bool("correct", false, "record correction entries for mismatches")
+	_ = flags.Parse(args)
+
+	report, err := requestReconcile(*addr, *apiKey, *correct)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(report)
+	if len(report.Mismatches) > 0 && !report.Corrected {
+		os.Exit(exitMismatches)
+	}
+}
+
+// requestReconcile запускаем сверку на сервисе addr.
+func requestReconcile(addr, apiKey string, correct bool) (*models.ReconcileReport, error) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/admin/reconcile/?correct=%t", addr, correct), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(auth.HeaderAPIKey, apiKey)
+
+	client := &http.Client{Timeout: reconcileTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot request reconciliation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Success    bool                    `json:"success"`
+		ErrMessage string                  `json:"err_message"`
+		Data       *models.ReconcileReport `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if !body.Success || body.Data == nil {
+		return nil, fmt.Errorf("reconciliation failed (status %d): %s", resp.StatusCode, body.ErrMessage)
+	}
+	return body.Data, nil
+}
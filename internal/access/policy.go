@@ -0,0 +1,118 @@
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Nizom98/wallet/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// deniedRecorder журнал отказов в доступе.
+type deniedRecorder interface {
+	RecordDenied(ctx context.Context, perm models.Permission)
+}
+
+// Policy ролевая модель доступа: роль -> набор прав.
+type Policy struct {
+	roles    map[string]map[models.Permission]struct{}
+	recorder deniedRecorder
+}
+
+// Option дополнительная настройка политики.
+type Option func(policy *Policy)
+
+// WithDeniedRecorder записывать отказы в доступе в журнал аудита.
+func WithDeniedRecorder(recorder deniedRecorder) Option {
+	return func(policy *Policy) {
+		policy.recorder = recorder
+	}
+}
+
+// policyFile формат файла политики.
+type policyFile struct {
+	Roles map[string][]models.Permission `json:"roles"`
+}
+
+// NewPolicy конструктор политики.
+// roles - права для каждой роли, право models.PermAll дает все права.
+func NewPolicy(roles map[string][]models.Permission, opts ...Option) *Policy {
+	policy := &Policy{
+		roles: make(map[string]map[models.Permission]struct{}, len(roles)),
+	}
+	for role, perms := range roles {
+		set := make(map[models.Permission]struct{}, len(perms))
+		for _, perm := range perms {
+			set[perm] = struct{}{}
+		}
+		policy.roles[role] = set
+	}
+	for _, opt := range opts {
+		opt(policy)
+	}
+
+	return policy
+}
+
+// LoadPolicy читаем политику из json файла.
+func LoadPolicy(path string, opts ...Option) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy %s: %w", path, err)
+	}
+
+	var file policyFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse policy %s: %w", path, err)
+	}
+
+	return NewPolicy(file.Roles, opts...), nil
+}
+
+// Allowed есть ли у клиента право perm хотя бы через одну из его ролей.
+func (policy *Policy) Allowed(principal *models.Principal, perm models.Permission) bool {
+	if principal == nil {
+		return false
+	}
+	for _, role := range principal.Roles {
+		perms, ok := policy.roles[role]
+		if !ok {
+			continue
+		}
+		if _, ok := perms[perm]; ok {
+			return true
+		}
+		if _, ok := perms[models.PermAll]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check проверяем право клиента из контекста.
+// Отказ записывается в лог и журнал аудита, возвращается ошибка models.ErrForbidden.
+func (policy *Policy) Check(ctx context.Context, perm models.Permission) error {
+	principal := models.PrincipalFromContext(ctx)
+	if policy.Allowed(principal, perm) {
+		return nil
+	}
+
+	principalID := ""
+	if principal != nil {
+		principalID = principal.ID
+	}
+	log.WithFields(log.Fields{
+		"AUDIT":      "access_denied",
+		"PRINCIPAL":  principalID,
+		"PERMISSION": perm,
+	}).Warnf("access denied")
+	if policy.recorder != nil {
+		policy.recorder.RecordDenied(ctx, perm)
+	}
+
+	return fmt.Errorf("permission %s: %w", perm, models.ErrForbidden)
+}
@@ -0,0 +1,52 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowed(t *testing.T) {
+	policy := NewPolicy(map[string][]models.Permission{
+		"customer": {models.PermWalletRead, models.PermWalletDeposit},
+		"admin":    {models.PermAll},
+	})
+	customer := &models.Principal{ID: "c", Roles: []string{"customer"}}
+	admin := &models.Principal{ID: "a", Roles: []string{"admin"}}
+	nobody := &models.Principal{ID: "n", Roles: []string{"unknown"}}
+
+	assert.True(t, policy.Allowed(customer, models.PermWalletRead))
+	assert.False(t, policy.Allowed(customer, models.PermWalletDeactivate))
+	assert.True(t, policy.Allowed(admin, models.PermWalletDeactivate))
+	assert.False(t, policy.Allowed(nobody, models.PermWalletRead))
+	assert.False(t, policy.Allowed(nil, models.PermWalletRead))
+}
+
+func TestCheck(t *testing.T) {
+	policy := NewPolicy(map[string][]models.Permission{
+		"customer": {models.PermWalletRead},
+	})
+	ctx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "c", Roles: []string{"customer"}})
+
+	assert.Nil(t, policy.Check(ctx, models.PermWalletRead))
+	assert.True(t, errors.Is(policy.Check(ctx, models.PermWalletRename), models.ErrForbidden))
+	assert.True(t, errors.Is(policy.Check(context.Background(), models.PermWalletRead), models.ErrForbidden))
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	err := os.WriteFile(path, []byte(`{"roles": {"auditor": ["wallet:read", "wallet:any_owner"]}}`), 0o600)
+	assert.Nil(t, err)
+
+	policy, err := LoadPolicy(path)
+	assert.Nil(t, err)
+
+	auditor := &models.Principal{ID: "a", Roles: []string{"auditor"}}
+	assert.True(t, policy.Allowed(auditor, models.PermWalletAnyOwner))
+	assert.False(t, policy.Allowed(auditor, models.PermWalletWithdraw))
+}
@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+// AuditListHandler выборка журнала аудита.
+// Параметры: actor, action, wallet_id, from, to(RFC3339), after(seq последней полученной записи), limit.
+func (h *Handler) AuditListHandler(w http.ResponseWriter, req *http.Request) {
+	filter, err := parseAuditFilter(req)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	printOk(w, h.auditStore.Query(filter))
+}
+
+// AuditVerifyHandler проверка целостности цепочки журнала аудита, файл журнала перечитывается заново.
+func (h *Handler) AuditVerifyHandler(w http.ResponseWriter, _ *http.Request) {
+	resp := &AuditVerifyResponse{
+		Valid:   true,
+		Records: len(h.auditStore.Query(models.AuditFilter{})),
+	}
+	err := h.auditStore.Verify()
+	if err != nil {
+		resp.Valid = false
+		resp.Error = err.Error()
+	}
+
+	printOk(w, resp)
+}
+
+func parseAuditFilter(req *http.Request) (models.AuditFilter, error) {
+	query := req.URL.Query()
+	filter := models.AuditFilter{
+		Actor:    query.Get("actor"),
+		Action:   query.Get("action"),
+		WalletID: query.Get("wallet_id"),
+		Limit:    auditDefaultLimit,
+	}
+
+	var err error
+	if v := query.Get("from"); v != "" {
+		filter.From, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		filter.To, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	if v := query.Get("after"); v != "" {
+		filter.AfterSeq, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid after: %w", err)
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		filter.Limit, err = strconv.Atoi(v)
+		if err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", v)
+		}
+		if filter.Limit > auditMaxLimit {
+			filter.Limit = auditMaxLimit
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// WalletBalanceHandler баланс кошелька на момент времени.
+// Параметры: at(RFC3339), без него - текущий баланс по журналу операций.
+func (h *Handler) WalletBalanceHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if id == "" {
+		http.Error(w, "empty id", http.StatusBadRequest)
+		return
+	}
+
+	at, err := parseAt(req)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	balance, err := h.manWallet.BalanceAt(req.Context(), id, at)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, convertToBalanceResponse(balance))
+}
+
+// BalancesAtHandler балансы всех доступных кошельков на момент времени для отчетов на конец периода.
+// Параметры: at(RFC3339), без него - текущие балансы по журналу операций.
+func (h *Handler) BalancesAtHandler(w http.ResponseWriter, req *http.Request) {
+	at, err := parseAt(req)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	balances, err := h.manWallet.BalancesAt(req.Context(), at)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	resp := make([]*BalanceResponse, 0, len(balances))
+	for _, balance := range balances {
+		resp = append(resp, convertToBalanceResponse(balance))
+	}
+	printOk(w, resp)
+}
+
+func parseAt(req *http.Request) (time.Time, error) {
+	v := req.URL.Query().Get("at")
+	if v == "" {
+		return time.Now().UTC(), nil
+	}
+	at, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid at: %w", err)
+	}
+	return at.UTC(), nil
+}
+
+func convertToBalanceResponse(balance models.WalletBalance) *BalanceResponse {
+	return &BalanceResponse{
+		WalletID: balance.WalletID,
+		Balance:  balance.Balance,
+		At:       balance.At,
+	}
+}
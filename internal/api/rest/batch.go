@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// TransferBatchHandler пакетный перевод, применяется целиком или не применяется вовсе.
+func (h *Handler) TransferBatchHandler(w http.ResponseWriter, req *http.Request) {
+	dec := json.NewDecoder(req.Body)
+	var data TransferBatchRequest
+	err := dec.Decode(&data)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	legs := make([]models.TransferLeg, 0, len(data.Transfers))
+	for _, leg := range data.Transfers {
+		legs = append(legs, models.TransferLeg{
+			FromID: leg.From,
+			ToID:   leg.To,
+			Amount: leg.Amount,
+		})
+	}
+
+	result, err := h.manWallet.TransferBatch(req.Context(), legs)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	resp := &TransferBatchResponse{
+		OperationID: result.OperationID,
+		Transfers:   make([]TransferBatchResult, 0, len(legs)),
+		Total:       result.Amount,
+		Fee:         result.Fee,
+	}
+	for i, leg := range data.Transfers {
+		resp.Transfers = append(resp.Transfers, TransferBatchResult{
+			From:   leg.From,
+			To:     leg.To,
+			Amount: leg.Amount,
+			Fee:    result.Fees[i],
+		})
+	}
+	printOk(w, resp)
+}
@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// WalletCreateBulkHandler пакетное создание кошельков с результатом по каждому элементу.
+// Если хотя бы один элемент некорректен, ничего не создается, а ошибки возвращаются по элементам.
+func (h *Handler) WalletCreateBulkHandler(w http.ResponseWriter, req *http.Request) {
+	dec := json.NewDecoder(req.Body)
+	var data CreateWalletBulkRequest
+	err := dec.Decode(&data)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items := make([]models.NewWallet, 0, len(data.Wallets))
+	for _, item := range data.Wallets {
+		items = append(items, models.NewWallet{
+			Name:     item.Name,
+			Metadata: item.Metadata,
+		})
+	}
+
+	results, err := h.manWallet.CreateBulk(req.Context(), items)
+	if err != nil && results == nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	resp := make([]*CreateWalletBulkItem, 0, len(results))
+	for _, res := range results {
+		item := &CreateWalletBulkItem{}
+		if res.Err != nil {
+			item.Error = res.Err.Error()
+		}
+		if res.Wallet != nil {
+			item.Wallet = convertToWalletListResponse([]models.Walleter{res.Wallet})[0]
+		}
+		resp = append(resp, item)
+	}
+
+	if err != nil {
+		w.WriteHeader(errorStatus(err))
+		json.NewEncoder(w).Encode(
+			&StatusResponse{
+				Success:    false,
+				ErrMessage: err.Error(),
+				Data:       resp,
+			},
+		)
+		return
+	}
+
+	printOk(w, resp)
+}
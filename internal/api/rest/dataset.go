@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DatasetExportHandler выгрузка всех кошельков с журналом операций файлом JSON Lines.
+// Выгрузка собирается целиком до ответа, поэтому ошибка не обрывает файл на середине.
+func (h *Handler) DatasetExportHandler(w http.ResponseWriter, _ *http.Request) {
+	var buf bytes.Buffer
+	summary, err := h.backup.Export(&buf)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	filename := fmt.Sprintf("wallets-%s.jsonl", summary.CreatedAt.Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		log.Errorf("dataset export NOT written: %s", err.Error())
+	}
+}
+
+// DatasetImportHandler загрузка выгрузки из тела запроса: все кошельки или ни одного.
+func (h *Handler) DatasetImportHandler(w http.ResponseWriter, req *http.Request) {
+	summary, err := h.backup.Import(req.Body)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, summary)
+}
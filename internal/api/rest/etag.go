@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// formatETag версия кошелька в виде строгого ETag.
+func formatETag(version uint64) string {
+	return strconv.Quote(strconv.FormatUint(version, 10))
+}
+
+// parseIfMatch ожидаемая версия кошелька из заголовка If-Match.
+// Отсутствующий заголовок или "*" означают обновление без проверки версии(nil).
+func parseIfMatch(req *http.Request) (*uint64, error) {
+	header := strings.TrimSpace(req.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	if strings.Contains(header, ",") {
+		return nil, fmt.Errorf("If-Match: only a single ETag is supported: %w", models.ErrInvalidArgument)
+	}
+	if strings.HasPrefix(header, "W/") {
+		return nil, fmt.Errorf("If-Match: weak ETag is not supported: %w", models.ErrInvalidArgument)
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return nil, fmt.Errorf("If-Match: malformed ETag %s: %w", header, models.ErrInvalidArgument)
+	}
+	version, err := strconv.ParseUint(unquoted, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("If-Match: unknown ETag %s: %w", header, models.ErrVersionMismatch)
+	}
+
+	return &version, nil
+}
@@ -0,0 +1,31 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPut, "/wallets/id/", nil)
+
+	version, err := parseIfMatch(req)
+	assert.Nil(t, err)
+	assert.Nil(t, version)
+
+	req.Header.Set("If-Match", formatETag(7))
+	version, err = parseIfMatch(req)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), *version)
+
+	req.Header.Set("If-Match", `"abc"`)
+	_, err = parseIfMatch(req)
+	assert.True(t, errors.Is(err, models.ErrVersionMismatch))
+
+	req.Header.Set("If-Match", `7`)
+	_, err = parseIfMatch(req)
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+}
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+)
+
+const (
+	walletsDefaultLimit = 50
+	walletsMaxLimit     = 500
+)
+
+func parseWalletFilter(req *http.Request) (models.WalletFilter, error) {
+	query := req.URL.Query()
+	filter := models.WalletFilter{
+		NamePrefix:   query.Get("name_prefix"),
+		NameContains: query.Get("name_contains"),
+		Sort:         query.Get("sort"),
+		Cursor:       query.Get("cursor"),
+		Limit:        walletsDefaultLimit,
+	}
+
+	switch query.Get("status") {
+	case "":
+	case "active":
+		filter.Status = utils.Ptr[bool](true)
+	case "inactive":
+		filter.Status = utils.Ptr[bool](false)
+	default:
+		return filter, fmt.Errorf("invalid status %q", query.Get("status"))
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return filter, fmt.Errorf("invalid order %q", query.Get("order"))
+	}
+
+	if v := query.Get("min_balance"); v != "" {
+		min, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid min_balance: %w", err)
+		}
+		filter.MinBalance = &min
+	}
+	if v := query.Get("max_balance"); v != "" {
+		max, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid max_balance: %w", err)
+		}
+		filter.MaxBalance = &max
+	}
+	if v := query.Get("updated_since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid updated_since: %w", err)
+		}
+		filter.UpdatedSince = since
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", v)
+		}
+		if limit > walletsMaxLimit {
+			limit = walletsMaxLimit
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
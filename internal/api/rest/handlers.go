@@ -2,15 +2,45 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/Nizom98/wallet/internal/models"
 	"github.com/gorilla/mux"
 	"net/http"
 )
 
-func NewHandler(manWallet models.WalletManager, repoWallet models.WalletRepository) (*Handler, error) {
+func NewHandler(manWallet models.WalletManager, repoWallet models.WalletRepository, authn authenticator, policy authorizer, auditStore models.AuditStore, scheduler scheduler, reconciler reconciler, statements statementGenerator, backup datasetBackup) (*Handler, error) {
+	if authn == nil {
+		return nil, fmt.Errorf("empty authenticator")
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("empty access policy")
+	}
+	if auditStore == nil {
+		return nil, fmt.Errorf("empty audit store")
+	}
+	if scheduler == nil {
+		return nil, fmt.Errorf("empty scheduler")
+	}
+	if reconciler == nil {
+		return nil, fmt.Errorf("empty reconciler")
+	}
+	if statements == nil {
+		return nil, fmt.Errorf("empty statement generator")
+	}
+	if backup == nil {
+		return nil, fmt.Errorf("empty dataset backup")
+	}
 	return &Handler{
 		manWallet:  manWallet,
 		repoWallet: repoWallet,
+		authn:      authn,
+		policy:     policy,
+		auditStore: auditStore,
+		scheduler:  scheduler,
+		reconciler: reconciler,
+		statements: statements,
+		backup:     backup,
 	}, nil
 }
 
@@ -22,9 +52,9 @@ func (h *Handler) WalletCreateHandler(w http.ResponseWriter, req *http.Request)
 		printError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	wallet, err := h.manWallet.Create(request.Name)
+	wallet, err := h.manWallet.Create(req.Context(), request.Name)
 	if err != nil {
-		printError(w, err.Error(), http.StatusInternalServerError)
+		printError(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -34,9 +64,11 @@ func (h *Handler) WalletCreateHandler(w http.ResponseWriter, req *http.Request)
 	}
 
 	resp := &CreateWalletResponse{
-		ID:     wallet.ID(),
-		Name:   wallet.Name(),
-		Status: active,
+		ID:        wallet.ID(),
+		Name:      wallet.Name(),
+		Status:    active,
+		Owner:     wallet.Owner(),
+		CreatedAt: wallet.CreatedAt(),
 	}
 	printOk(w, resp)
 }
@@ -48,20 +80,37 @@ func (h *Handler) WalletByIDHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	wallet, err := h.manWallet.ByID(id)
+	wallet, err := h.manWallet.ByID(req.Context(), id)
 	if err != nil {
-		printError(w, err.Error(), http.StatusInternalServerError)
+		printError(w, err.Error(), errorStatus(err))
 		return
 	}
 
 	resp := convertToWalletListResponse([]models.Walleter{wallet})
+	w.Header().Set("ETag", formatETag(wallet.Version()))
 	printOk(w, resp[0])
 }
 
-func (h *Handler) WalletListHandler(w http.ResponseWriter, _ *http.Request) {
-	wallets := h.manWallet.List()
+// WalletListHandler страница списка кошельков.
+// Параметры: limit, cursor, status(active, inactive), name_prefix, name_contains,
+// min_balance, max_balance, updated_since(RFC3339), sort(created, updated, name, balance), order(asc, desc).
+func (h *Handler) WalletListHandler(w http.ResponseWriter, req *http.Request) {
+	filter, err := parseWalletFilter(req)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.manWallet.List(req.Context(), filter)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
 
-	resp := convertToWalletListResponse(wallets)
+	resp := &WalletPageResponse{
+		Wallets:    convertToWalletListResponse(page.Wallets),
+		NextCursor: page.NextCursor,
+	}
 	printOk(w, resp)
 }
 
@@ -80,13 +129,13 @@ func (h *Handler) WalletDepositHandler(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	err = h.manWallet.IncreaseBalanceBy(id, data.Amount)
+	result, err := h.manWallet.IncreaseBalanceBy(req.Context(), id, data.Amount)
 	if err != nil {
-		printError(w, err.Error(), http.StatusInternalServerError)
+		printError(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	printOk(w, data)
+	printOk(w, convertToOperationResponse(result, ""))
 }
 
 func (h *Handler) WalletWithdrawHandler(w http.ResponseWriter, req *http.Request) {
@@ -104,13 +153,13 @@ func (h *Handler) WalletWithdrawHandler(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	err = h.manWallet.DecreaseBalanceBy(id, data.Amount)
+	result, err := h.manWallet.DecreaseBalanceBy(req.Context(), id, data.Amount)
 	if err != nil {
-		printError(w, err.Error(), http.StatusInternalServerError)
+		printError(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	printOk(w, data)
+	printOk(w, convertToOperationResponse(result, ""))
 }
 
 func (h *Handler) WalletTransferHandler(w http.ResponseWriter, req *http.Request) {
@@ -128,13 +177,13 @@ func (h *Handler) WalletTransferHandler(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	err = h.manWallet.TransferBalance(id, data.TransferTo, data.Amount)
+	result, err := h.manWallet.TransferBalance(req.Context(), id, data.TransferTo, data.Amount)
 	if err != nil {
-		printError(w, err.Error(), http.StatusInternalServerError)
+		printError(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	printOk(w, data)
+	printOk(w, convertToOperationResponse(result, data.TransferTo))
 }
 
 func (h *Handler) WalletDeactivateHandler(w http.ResponseWriter, req *http.Request) {
@@ -144,9 +193,15 @@ func (h *Handler) WalletDeactivateHandler(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	err := h.manWallet.DeactivateByID(id)
+	version, err := parseIfMatch(req)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = h.manWallet.DeactivateByID(req.Context(), id, version)
 	if err != nil {
-		printError(w, err.Error(), http.StatusInternalServerError)
+		printError(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -168,9 +223,46 @@ func (h *Handler) WalletUpdateHandler(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	err = h.manWallet.UpdateName(id, data.Name)
+	version, err := parseIfMatch(req)
 	if err != nil {
-		printError(w, err.Error(), http.StatusInternalServerError)
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = h.manWallet.UpdateName(req.Context(), id, data.Name, version)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, data)
+}
+
+// WalletCreditLimitHandler изменение овердрафта кошелька(только администратор).
+func (h *Handler) WalletCreditLimitHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if id == "" {
+		http.Error(w, "empty id", http.StatusBadRequest)
+		return
+	}
+
+	dec := json.NewDecoder(req.Body)
+	var data WalletCreditLimitRequest
+	err := dec.Decode(&data)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(req)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = h.manWallet.SetCreditLimit(req.Context(), id, data.CreditLimit, version)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -186,16 +278,51 @@ func convertToWalletListResponse(inp []models.Walleter) []*WalletListResponse {
 			active = "inactive"
 		}
 		out = append(out, &WalletListResponse{
-			ID:      w.ID(),
-			Name:    w.Name(),
-			Balance: w.Balance(),
-			Status:  active,
+			ID:          w.ID(),
+			Name:        w.Name(),
+			Balance:     w.Balance(),
+			Available:   w.Available(),
+			CreditLimit: w.CreditLimit(),
+			Status:      active,
+			Owner:       w.Owner(),
+			CreatedAt:   w.CreatedAt(),
+			UpdatedAt:   w.UpdatedAt(),
+			Version:     w.Version(),
+			Metadata:    w.Metadata(),
 		})
 	}
 
 	return out
 }
 
+func convertToOperationResponse(result models.OperationResult, transferTo string) *OperationResponse {
+	return &OperationResponse{
+		OperationID: result.OperationID,
+		Amount:      result.Amount,
+		Fee:         result.Fee,
+		TransferTo:  transferTo,
+		Reverses:    result.Reverses,
+	}
+}
+
+// errorStatus http статус ответа для ошибки бизнес логики.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, models.ErrLimitExceeded):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func printError(w http.ResponseWriter, err string, status int) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(
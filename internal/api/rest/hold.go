@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// HoldCreateHandler блокировка средств кошелька.
+func (h *Handler) HoldCreateHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if id == "" {
+		http.Error(w, "empty id", http.StatusBadRequest)
+		return
+	}
+
+	dec := json.NewDecoder(req.Body)
+	var data HoldCreateRequest
+	err := dec.Decode(&data)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hold, err := h.manWallet.CreateHold(req.Context(), id, data.Amount, time.Duration(data.TTLSeconds)*time.Second)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, convertToHoldResponse(hold))
+}
+
+// HoldCaptureHandler захват блокировки списанием или переводом.
+func (h *Handler) HoldCaptureHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if id == "" {
+		http.Error(w, "empty id", http.StatusBadRequest)
+		return
+	}
+
+	dec := json.NewDecoder(req.Body)
+	var data HoldCaptureRequest
+	err := dec.Decode(&data)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hold, err := h.manWallet.CaptureHold(req.Context(), id, data.Amount, data.TransferTo)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, convertToHoldResponse(hold))
+}
+
+// HoldReleaseHandler освобождение блокировки без списания.
+func (h *Handler) HoldReleaseHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if id == "" {
+		http.Error(w, "empty id", http.StatusBadRequest)
+		return
+	}
+
+	hold, err := h.manWallet.ReleaseHold(req.Context(), id)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, convertToHoldResponse(hold))
+}
+
+func convertToHoldResponse(hold models.Hold) *HoldResponse {
+	return &HoldResponse{
+		ID:          hold.ID,
+		WalletID:    hold.WalletID,
+		Amount:      hold.Amount,
+		Status:      string(hold.Status),
+		Captured:    hold.Captured,
+		CapturedTo:  hold.CapturedTo,
+		CreatedAt:   hold.CreatedAt,
+		ExpiresAt:   hold.ExpiresAt,
+		Fee:         hold.Fee,
+		OperationID: hold.OperationID,
+	}
+}
@@ -2,14 +2,33 @@ package rest
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// MiddlewareLog логируем метод, путь, время выполнения запроса.
+const (
+	headerRequestID = "X-Request-ID"
+	// maxLogBody сколько байт тела запроса попадает в лог.
+	maxLogBody = 1024
+)
+
+var tracer = otel.Tracer("github.com/Nizom98/wallet/internal/api/rest")
+
+// MiddlewareLog логируем метод, путь, время выполнения запроса и начало тела запроса(не больше maxLogBody байт).
 func (h *Handler) MiddlewareLog(next func(w http.ResponseWriter, req *http.Request)) func(w http.ResponseWriter, req *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
 		var body []byte
@@ -20,7 +39,7 @@ func (h *Handler) MiddlewareLog(next func(w http.ResponseWriter, req *http.Reque
 				"URI":       req.RequestURI,
 				"METHOD":    req.Method,
 				"WORK_TIME": time.Since(startTime),
-				"BODY":      string(body),
+				"BODY":      logBody(body),
 			})
 			entry.Debugf("request logging")
 		}()
@@ -35,3 +54,136 @@ func (h *Handler) MiddlewareLog(next func(w http.ResponseWriter, req *http.Reque
 	}
 
 }
+
+// MiddlewareLogNoBody логируем запрос как MiddlewareLog, но без тела.
+// Для маршрутов, тело которых велико или содержит все данные кошельков(выгрузка и загрузка).
+func (h *Handler) MiddlewareLogNoBody(next func(w http.ResponseWriter, req *http.Request)) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		startTime := time.Now()
+
+		defer func() {
+			entry := log.WithFields(log.Fields{
+				"URI":       req.RequestURI,
+				"METHOD":    req.Method,
+				"WORK_TIME": time.Since(startTime),
+			})
+			entry.Debugf("request logging")
+		}()
+
+		next(w, req)
+	}
+}
+
+// logBody тело запроса для лога, обрезанное до maxLogBody байт.
+func logBody(body []byte) string {
+	if len(body) <= maxLogBody {
+		return string(body)
+	}
+	return fmt.Sprintf("%s...(%d bytes)", body[:maxLogBody], len(body))
+}
+
+// MiddlewareTrace открываем серверный спан на каждый запрос.
+// Контекст трассировки клиента(заголовок traceparent) продолжается, если передан.
+func (h *Handler) MiddlewareTrace(next func(w http.ResponseWriter, req *http.Request)) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		route := req.URL.Path
+		if cur := mux.CurrentRoute(req); cur != nil {
+			if tpl, err := cur.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		ctx, span := tracer.Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(req.Method),
+				semconv.HTTPRoute(route),
+				semconv.HTTPTarget(req.RequestURI),
+			),
+		)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, req.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPStatusCode(rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	}
+}
+
+// statusRecorder запоминаем код ответа для спана запроса.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// MiddlewareAuth пропускаем только аутентифицированные запросы(API ключ или JWT).
+// Клиент сохраняется в контексте запроса, иначе отвечаем 401.
+func (h *Handler) MiddlewareAuth(next func(w http.ResponseWriter, req *http.Request)) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		principal, err := h.authn.Authenticate(req)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"URI":    req.RequestURI,
+				"METHOD": req.Method,
+			}).Warnf("request rejected: %s", err.Error())
+			w.Header().Set("WWW-Authenticate", `Bearer realm="wallet"`)
+			printError(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		trace.SpanFromContext(req.Context()).SetAttributes(semconv.EnduserID(principal.ID))
+		next(w, req.WithContext(models.ContextWithPrincipal(req.Context(), principal)))
+	}
+}
+
+// MiddlewareAccess пропускаем запрос, только если у клиента есть право perm.
+// Должен вызываться после MiddlewareAuth, иначе клиент в контексте отсутствует и доступ запрещен.
+func (h *Handler) MiddlewareAccess(perm models.Permission, next func(w http.ResponseWriter, req *http.Request)) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		err := h.policy.Check(req.Context(), perm)
+		if err != nil {
+			printError(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		next(w, req)
+	}
+}
+
+// MiddlewareRequestID сохраняем в контексте идентификатор запроса и адрес клиента.
+// Идентификатор берется из заголовка X-Request-ID или генерируется, и возвращается в ответе.
+func (h *Handler) MiddlewareRequestID(next func(w http.ResponseWriter, req *http.Request)) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(headerRequestID)
+		if id == "" {
+			id = genRequestID()
+		}
+		clientIP, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			clientIP = req.RemoteAddr
+		}
+
+		w.Header().Set(headerRequestID, id)
+		meta := &models.RequestMeta{
+			ID:       id,
+			ClientIP: clientIP,
+		}
+		next(w, req.WithContext(models.ContextWithRequestMeta(req.Context(), meta)))
+	}
+}
+
+func genRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
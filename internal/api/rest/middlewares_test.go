@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareLog_body(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(level)
+
+	h := &Handler{}
+	var got string
+	next := func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		got = string(body)
+	}
+
+	small := `{"name":"wallet"}`
+	h.MiddlewareLog(next)(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(small)))
+	assert.Equal(t, small, got)
+	assert.Equal(t, small, hook.LastEntry().Data["BODY"])
+
+	large := strings.Repeat("x", maxLogBody+10)
+	h.MiddlewareLog(next)(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(large)))
+	assert.Equal(t, large, got)
+	assert.Equal(t, large[:maxLogBody]+"...(1034 bytes)", hook.LastEntry().Data["BODY"])
+
+	h.MiddlewareLogNoBody(next)(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/admin/import/", strings.NewReader(small)))
+	assert.Equal(t, small, got)
+	assert.NotContains(t, hook.LastEntry().Data, "BODY")
+}
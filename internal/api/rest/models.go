@@ -1,27 +1,99 @@
 package rest
 
-import "github.com/Nizom98/wallet/internal/models"
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+type authenticator interface {
+	Authenticate(req *http.Request) (*models.Principal, error)
+}
+
+type authorizer interface {
+	Check(ctx context.Context, perm models.Permission) error
+}
+
+// scheduler регулярные переводы клиента.
+type scheduler interface {
+	Create(ctx context.Context, inp models.NewSchedule) (models.Schedule, error)
+	List(ctx context.Context, filter models.ScheduleFilter) ([]models.Schedule, error)
+	Pause(ctx context.Context, id string) (models.Schedule, error)
+	Resume(ctx context.Context, id string) (models.Schedule, error)
+	Cancel(ctx context.Context, id string) (models.Schedule, error)
+}
+
+// reconciler сверка балансов кошельков с журналом операций.
+type reconciler interface {
+	Run(ctx context.Context, correct bool) (*models.ReconcileReport, error)
+	Last() *models.ReconcileReport
+}
+
+// statementGenerator выписки по кошелькам за период.
+type statementGenerator interface {
+	Generate(ctx context.Context, walletID string, from, to time.Time) (*models.Statement, error)
+}
+
+// datasetBackup выгрузка и загрузка всех кошельков с журналом операций.
+type datasetBackup interface {
+	Export(w io.Writer) (models.DatasetSummary, error)
+	Import(r io.Reader) (models.DatasetSummary, error)
+}
 
 type Handler struct {
 	manWallet  models.WalletManager
 	repoWallet models.WalletRepository
+	authn      authenticator
+	policy     authorizer
+	auditStore models.AuditStore
+	scheduler  scheduler
+	reconciler reconciler
+	statements statementGenerator
+	backup     datasetBackup
 }
 
 type CreateWalletRequest struct {
-	Name string `json:"name"`
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type CreateWalletBulkRequest struct {
+	Wallets []CreateWalletRequest `json:"wallets"`
+}
+
+type CreateWalletBulkItem struct {
+	Wallet *WalletListResponse `json:"wallet,omitempty"`
+	Error  string              `json:"error,omitempty"`
 }
 
 type CreateWalletResponse struct {
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type WalletListResponse struct {
-	ID      string  `json:"id"`
-	Name    string  `json:"name"`
-	Balance float64 `json:"balance"`
-	Status  string  `json:"status"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Balance     float64           `json:"balance"`
+	Available   float64           `json:"available_balance"`
+	CreditLimit float64           `json:"credit_limit"`
+	Status      string            `json:"status"`
+	Owner       string            `json:"owner"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Version     uint64            `json:"version"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+type WalletPageResponse struct {
+	Wallets    []*WalletListResponse `json:"wallets"`
+	NextCursor string                `json:"next_cursor,omitempty"`
 }
 
 type StatusResponse struct {
@@ -39,6 +111,129 @@ type WalletTransferRequest struct {
 	TransferTo string  `json:"transfer_to"`
 }
 
+// OperationResponse результат пополнения, снятия или перевода.
+type OperationResponse struct {
+	OperationID string  `json:"operation_id"`
+	Amount      float64 `json:"amount"`
+	// Fee комиссия, списанная сверх суммы операции.
+	Fee        float64 `json:"fee"`
+	TransferTo string  `json:"transfer_to,omitempty"`
+	// Reverses сторнированная операция.
+	Reverses string `json:"reverses,omitempty"`
+}
+
+type ReverseRequest struct {
+	// Amount 0 - сторнирование всего несторнированного остатка.
+	Amount float64 `json:"amount,omitempty"`
+}
+
+type TransferBatchLeg struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Amount float64 `json:"amount"`
+}
+
+type TransferBatchRequest struct {
+	Transfers []TransferBatchLeg `json:"transfers"`
+}
+
+// TransferBatchResponse результат пакетного перевода.
+type TransferBatchResponse struct {
+	OperationID string                `json:"operation_id"`
+	Transfers   []TransferBatchResult `json:"transfers"`
+	// Total сумма переводов без комиссий.
+	Total float64 `json:"total"`
+	// Fee сумма комиссий, списанных сверх Total.
+	Fee float64 `json:"fee"`
+}
+
+// TransferBatchResult перевод пакета с его комиссией.
+type TransferBatchResult struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Amount float64 `json:"amount"`
+	Fee    float64 `json:"fee"`
+}
+
+// RebuildResponse результат перестроения проекций.
+type RebuildResponse struct {
+	// Wallets число кошельков после перестроения.
+	Wallets int `json:"wallets"`
+}
+
+type HoldCreateRequest struct {
+	Amount float64 `json:"amount"`
+	// TTLSeconds срок блокировки, 0 - срок по умолчанию.
+	TTLSeconds int64 `json:"ttl_seconds,omitempty"`
+}
+
+type HoldCaptureRequest struct {
+	// Amount 0 - захват всей суммы блокировки.
+	Amount     float64 `json:"amount,omitempty"`
+	TransferTo string  `json:"transfer_to,omitempty"`
+}
+
+type HoldResponse struct {
+	ID         string    `json:"id"`
+	WalletID   string    `json:"wallet_id"`
+	Amount     float64   `json:"amount"`
+	Status     string    `json:"status"`
+	Captured   float64   `json:"captured,omitempty"`
+	CapturedTo string    `json:"captured_to,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Fee комиссия, списанная при захвате.
+	Fee float64 `json:"fee,omitempty"`
+	// OperationID операция журнала, которой записан захват.
+	OperationID string `json:"operation_id,omitempty"`
+}
+
+type ScheduleCreateRequest struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Amount float64 `json:"amount"`
+	// IntervalSeconds период перевода, задается вместо cron.
+	IntervalSeconds int64 `json:"interval_seconds,omitempty"`
+	// Cron расписание(минута час день месяц день_недели) по UTC.
+	Cron string `json:"cron,omitempty"`
+	// StartAt время первого перевода по интервалу.
+	StartAt time.Time `json:"start_at"`
+}
+
+type ScheduleResponse struct {
+	ID              string     `json:"id"`
+	Owner           string     `json:"owner"`
+	From            string     `json:"from"`
+	To              string     `json:"to"`
+	Amount          float64    `json:"amount"`
+	IntervalSeconds int64      `json:"interval_seconds,omitempty"`
+	Cron            string     `json:"cron,omitempty"`
+	Status          string     `json:"status"`
+	NextRun         time.Time  `json:"next_run"`
+	Attempt         int        `json:"attempt,omitempty"`
+	LastRun         *time.Time `json:"last_run,omitempty"`
+	LastOperationID string     `json:"last_operation_id,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	Failures        int        `json:"failures"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type BalanceResponse struct {
+	WalletID string    `json:"wallet_id"`
+	Balance  float64   `json:"balance"`
+	At       time.Time `json:"at"`
+}
+
+type WalletCreditLimitRequest struct {
+	CreditLimit float64 `json:"credit_limit"`
+}
+
 type WalletUpdateNameRequest struct {
 	Name string `json:"name"`
 }
+
+type AuditVerifyResponse struct {
+	Valid   bool   `json:"valid"`
+	Records int    `json:"records"`
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,26 @@
+package rest
+
+import "net/http"
+
+// projectionRebuilder хранилище, проекции которого строятся заново по потоку событий.
+type projectionRebuilder interface {
+	Rebuild() error
+}
+
+// RebuildHandler строим проекции кошельков заново по всему потоку событий.
+// Доступно только при хранении кошельков потоком событий.
+func (h *Handler) RebuildHandler(w http.ResponseWriter, _ *http.Request) {
+	rebuilder, ok := h.repoWallet.(projectionRebuilder)
+	if !ok {
+		printError(w, "rebuild is supported only when persistence.mode is events", http.StatusNotImplemented)
+		return
+	}
+
+	err := rebuilder.Rebuild()
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, &RebuildResponse{Wallets: len(h.repoWallet.All())})
+}
@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ReconcileReportHandler результат последней сверки балансов.
+func (h *Handler) ReconcileReportHandler(w http.ResponseWriter, _ *http.Request) {
+	report := h.reconciler.Last()
+	if report == nil {
+		printError(w, "reconciliation has not run yet", http.StatusNotFound)
+		return
+	}
+
+	printOk(w, report)
+}
+
+// ReconcileRunHandler запуск сверки балансов.
+// Параметры: correct(true - записать исправления журнала по расхождениям).
+func (h *Handler) ReconcileRunHandler(w http.ResponseWriter, req *http.Request) {
+	correct := false
+	if v := req.URL.Query().Get("correct"); v != "" {
+		var err error
+		correct, err = strconv.ParseBool(v)
+		if err != nil {
+			printError(w, "invalid correct: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.reconciler.Run(req.Context(), correct)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, report)
+}
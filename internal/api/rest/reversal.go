@@ -0,0 +1,33 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// OperationReverseHandler полное или частичное сторнирование операции журнала(только администратор).
+func (h *Handler) OperationReverseHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if id == "" {
+		http.Error(w, "empty id", http.StatusBadRequest)
+		return
+	}
+
+	dec := json.NewDecoder(req.Body)
+	var data ReverseRequest
+	err := dec.Decode(&data)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.manWallet.ReverseOperation(req.Context(), id, data.Amount)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, convertToOperationResponse(result, ""))
+}
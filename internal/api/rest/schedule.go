@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// ScheduleCreateHandler создание регулярного перевода по интервалу или cron расписанию.
+func (h *Handler) ScheduleCreateHandler(w http.ResponseWriter, req *http.Request) {
+	dec := json.NewDecoder(req.Body)
+	var data ScheduleCreateRequest
+	err := dec.Decode(&data)
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.scheduler.Create(req.Context(), models.NewSchedule{
+		FromID:   data.From,
+		ToID:     data.To,
+		Amount:   data.Amount,
+		Interval: time.Duration(data.IntervalSeconds) * time.Second,
+		Cron:     data.Cron,
+		StartAt:  data.StartAt,
+	})
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, convertToScheduleResponse(schedule))
+}
+
+// ScheduleListHandler регулярные переводы клиента.
+// Параметры: status(active, paused, cancelled).
+func (h *Handler) ScheduleListHandler(w http.ResponseWriter, req *http.Request) {
+	filter := models.ScheduleFilter{
+		Status: models.ScheduleStatus(req.URL.Query().Get("status")),
+	}
+
+	schedules, err := h.scheduler.List(req.Context(), filter)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	resp := make([]*ScheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		resp = append(resp, convertToScheduleResponse(schedule))
+	}
+	printOk(w, resp)
+}
+
+// SchedulePauseHandler приостановка регулярного перевода.
+func (h *Handler) SchedulePauseHandler(w http.ResponseWriter, req *http.Request) {
+	h.scheduleAction(w, req, h.scheduler.Pause)
+}
+
+// ScheduleResumeHandler возобновление приостановленного перевода.
+func (h *Handler) ScheduleResumeHandler(w http.ResponseWriter, req *http.Request) {
+	h.scheduleAction(w, req, h.scheduler.Resume)
+}
+
+// ScheduleCancelHandler отмена регулярного перевода.
+func (h *Handler) ScheduleCancelHandler(w http.ResponseWriter, req *http.Request) {
+	h.scheduleAction(w, req, h.scheduler.Cancel)
+}
+
+func (h *Handler) scheduleAction(w http.ResponseWriter, req *http.Request, action func(ctx context.Context, id string) (models.Schedule, error)) {
+	id := mux.Vars(req)["id"]
+	if id == "" {
+		http.Error(w, "empty id", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := action(req.Context(), id)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	printOk(w, convertToScheduleResponse(schedule))
+}
+
+func convertToScheduleResponse(schedule models.Schedule) *ScheduleResponse {
+	resp := &ScheduleResponse{
+		ID:              schedule.ID,
+		Owner:           schedule.Owner,
+		From:            schedule.FromID,
+		To:              schedule.ToID,
+		Amount:          schedule.Amount,
+		IntervalSeconds: int64(schedule.Interval / time.Second),
+		Cron:            schedule.Cron,
+		Status:          string(schedule.Status),
+		NextRun:         schedule.NextRun,
+		Attempt:         schedule.Attempt,
+		LastOperationID: schedule.LastOperationID,
+		LastError:       schedule.LastError,
+		Failures:        schedule.Failures,
+		CreatedAt:       schedule.CreatedAt,
+	}
+	if !schedule.LastRun.IsZero() {
+		lastRun := schedule.LastRun
+		resp.LastRun = &lastRun
+	}
+	return resp
+}
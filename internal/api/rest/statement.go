@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/buisness/statement"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// WalletStatementHandler выписка по кошельку за период файлом в выбранном формате.
+// Параметры: from, to(RFC3339, по умолчанию с начала текущего месяца до текущего момента), format(json, csv, text).
+func (h *Handler) WalletStatementHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if id == "" {
+		http.Error(w, "empty id", http.StatusBadRequest)
+		return
+	}
+
+	query := req.URL.Query()
+	format, err := statement.ParseFormat(query.Get("format"))
+	if err != nil {
+		printError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+	if v := query.Get("from"); v != "" {
+		from, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			printError(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		to, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			printError(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	st, err := h.statements.Generate(req.Context(), id, from, to)
+	if err != nil {
+		printError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s-%s.%s", st.WalletID, st.From.Format("20060102"), st.To.Format("20060102"), statementExt(format))
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	err = statement.Write(w, st, format)
+	if err != nil {
+		log.Errorf("statement of wallet %s NOT written: %s", id, err.Error())
+	}
+}
+
+func statementExt(format statement.Format) string {
+	if format == statement.FormatText {
+		return "txt"
+	}
+	return string(format)
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/config"
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// HeaderAPIKey заголовок со статическим ключом доступа.
+const HeaderAPIKey = "X-API-Key"
+
+var (
+	// ErrUnauthenticated запрос без валидных учетных данных.
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	errNoCredentials = errors.New("no credentials")
+	errUnknownKey    = errors.New("unknown api key")
+	errJWTDisabled   = errors.New("jwt authentication is not configured")
+	errNoExpiry      = errors.New("token has no expiration time")
+
+	// errUnknownPrincipal клиент без API ключа в настройках: ключ отозван или клиент аутентифицируется по JWT
+	errUnknownPrincipal = fmt.Errorf("principal has no api key: %w", models.ErrForbidden)
+)
+
+// apiKey статический ключ, хранится только sha256 хеш.
+type apiKey struct {
+	hash      []byte
+	principal *models.Principal
+}
+
+// Authenticator проверяет учетные данные запроса(API ключ или JWT).
+type Authenticator struct {
+	apiKeys  []apiKey
+	jwtKeys  map[string]crypto.PublicKey
+	issuer   string
+	audience string
+}
+
+// NewAuthenticator конструктор аутентификатора.
+// Ключи API задаются хешами, JWT проверяются по локальному JWKS файлу.
+func NewAuthenticator(cfg config.Auth) (*Authenticator, error) {
+	authn := &Authenticator{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+	}
+
+	for _, key := range cfg.APIKeys {
+		hash, err := hex.DecodeString(key.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %q: hash must be hex encoded sha256", key.ID)
+		}
+		if key.ID == "" {
+			return nil, fmt.Errorf("api key with empty id")
+		}
+		authn.apiKeys = append(authn.apiKeys, apiKey{
+			hash: hash,
+			principal: &models.Principal{
+				ID:     key.ID,
+				Roles:  key.Roles,
+				Method: models.MethodAPIKey,
+			},
+		})
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		authn.jwtKeys = keys
+	}
+
+	return authn, nil
+}
+
+// HashAPIKey хеш ключа в том виде, в котором он хранится в настройках.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate определяем клиента по заголовкам запроса.
+// Любая ошибка оборачивает ErrUnauthenticated.
+func (authn *Authenticator) Authenticate(req *http.Request) (*models.Principal, error) {
+	if key := req.Header.Get(HeaderAPIKey); key != "" {
+		principal, err := authn.byAPIKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, err.Error())
+		}
+		return principal, nil
+	}
+
+	header := req.Header.Get("Authorization")
+	if strings.HasPrefix(header, "Bearer ") {
+		principal, err := authn.byJWT(strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, err.Error())
+		}
+		return principal, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, errNoCredentials.Error())
+}
+
+// byAPIKey ищем ключ по хешу, сравнение за константное время.
+func (authn *Authenticator) byAPIKey(key string) (*models.Principal, error) {
+	sum := sha256.Sum256([]byte(key))
+
+	var found *models.Principal
+	for _, k := range authn.apiKeys {
+		if subtle.ConstantTimeCompare(sum[:], k.hash) == 1 {
+			found = k.principal
+		}
+	}
+	if found == nil {
+		return nil, errUnknownKey
+	}
+
+	return found, nil
+}
+
+// Principal клиент API ключа с идентификатором id и его текущими ролями из настроек.
+// Роли клиентов JWT известны только из токена, поэтому для них вернется ошибка:
+// их роли сохраняет сам потребитель вместе с тем, что клиент создал.
+func (authn *Authenticator) Principal(id string) (*models.Principal, error) {
+	for _, k := range authn.apiKeys {
+		if k.principal.ID == id {
+			return k.principal, nil
+		}
+	}
+	return nil, fmt.Errorf("principal %s: %w", id, errUnknownPrincipal)
+}
+
+// claims поля JWT, которые нас интересуют.
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// byJWT проверяем подпись, срок действия, iss и aud токена.
+// Токен без exp не принимается: он никогда не истекает.
+func (authn *Authenticator) byJWT(token string) (*models.Principal, error) {
+	if authn.jwtKeys == nil {
+		return nil, errJWTDisabled
+	}
+
+	parsed := &claims{}
+	_, err := jwt.ParseWithClaims(token, parsed, authn.keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.VerifyExpiresAt(time.Now(), true) {
+		return nil, errNoExpiry
+	}
+
+	if authn.issuer != "" && !parsed.VerifyIssuer(authn.issuer, true) {
+		return nil, fmt.Errorf("unexpected issuer %q", parsed.Issuer)
+	}
+	if authn.audience != "" && !parsed.VerifyAudience(authn.audience, true) {
+		return nil, fmt.Errorf("unexpected audience")
+	}
+	if parsed.Subject == "" {
+		return nil, fmt.Errorf("empty subject")
+	}
+
+	return &models.Principal{
+		ID:     parsed.Subject,
+		Roles:  parsed.Roles,
+		Method: models.MethodJWT,
+	}, nil
+}
+
+func (authn *Authenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := authn.jwtKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	return key, nil
+}
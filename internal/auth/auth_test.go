@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/config"
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKid = "test_kid"
+
+func TestAuthenticate_apiKey(t *testing.T) {
+	authn, err := NewAuthenticator(config.Auth{
+		APIKeys: []config.APIKey{{ID: "client_1", Hash: HashAPIKey("secret"), Roles: []string{"customer"}}},
+	})
+	assert.Nil(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/wallets/", nil)
+	req.Header.Set(HeaderAPIKey, "secret")
+	principal, err := authn.Authenticate(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "client_1", principal.ID)
+	assert.Equal(t, []string{"customer"}, principal.Roles)
+
+	req.Header.Set(HeaderAPIKey, "wrong")
+	_, err = authn.Authenticate(req)
+	assert.True(t, errors.Is(err, ErrUnauthenticated))
+}
+
+func TestAuthenticator_Principal(t *testing.T) {
+	authn, err := NewAuthenticator(config.Auth{
+		APIKeys: []config.APIKey{{ID: "client_1", Hash: HashAPIKey("secret"), Roles: []string{"customer"}}},
+	})
+	assert.Nil(t, err)
+
+	principal, err := authn.Principal("client_1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"customer"}, principal.Roles)
+
+	_, err = authn.Principal("revoked")
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+}
+
+func TestAuthenticate_noCredentials(t *testing.T) {
+	authn, err := NewAuthenticator(config.Auth{})
+	assert.Nil(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/wallets/", nil)
+	_, err = authn.Authenticate(req)
+	assert.True(t, errors.Is(err, ErrUnauthenticated))
+}
+
+func TestAuthenticate_jwt(t *testing.T) {
+	key, jwksFile := newTestJWKS(t)
+	authn, err := NewAuthenticator(config.Auth{JWKSFile: jwksFile, Issuer: "test_issuer"})
+	assert.Nil(t, err)
+
+	token := signTestToken(t, key, jwt.MapClaims{
+		"sub":   "user_1",
+		"iss":   "test_issuer",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin"},
+	})
+	req, _ := http.NewRequest(http.MethodGet, "/wallets/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	principal, err := authn.Authenticate(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "user_1", principal.ID)
+	assert.Equal(t, []string{"admin"}, principal.Roles)
+}
+
+func TestAuthenticate_jwtInvalid(t *testing.T) {
+	key, jwksFile := newTestJWKS(t)
+	authn, err := NewAuthenticator(config.Auth{JWKSFile: jwksFile, Issuer: "test_issuer"})
+	assert.Nil(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	cases := map[string]string{
+		"expired": signTestToken(t, key, jwt.MapClaims{
+			"sub": "user_1", "iss": "test_issuer", "exp": time.Now().Add(-time.Hour).Unix(),
+		}),
+		"no expiry": signTestToken(t, key, jwt.MapClaims{
+			"sub": "user_1", "iss": "test_issuer",
+		}),
+		"wrong issuer": signTestToken(t, key, jwt.MapClaims{
+			"sub": "user_1", "iss": "other", "exp": time.Now().Add(time.Hour).Unix(),
+		}),
+		"wrong signature": signTestToken(t, otherKey, jwt.MapClaims{
+			"sub": "user_1", "iss": "test_issuer", "exp": time.Now().Add(time.Hour).Unix(),
+		}),
+	}
+	for name, token := range cases {
+		req, _ := http.NewRequest(http.MethodGet, "/wallets/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		_, err := authn.Authenticate(req)
+		assert.True(t, errors.Is(err, ErrUnauthenticated), name)
+	}
+}
+
+func newTestJWKS(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	set := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, err := json.Marshal(set)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.Nil(t, os.WriteFile(path, data, 0o600))
+
+	return key, path
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKid
+	signed, err := token.SignedString(key)
+	assert.Nil(t, err)
+	return signed
+}
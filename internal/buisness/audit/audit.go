@@ -0,0 +1,276 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// NewManager конструктор аудита операций над кошельками.
+func NewManager(recorder *Recorder, manWallet models.WalletManager, repoWallet walletGetter) *audit {
+	return &audit{
+		manWallet:  manWallet,
+		recorder:   recorder,
+		repoWallet: repoWallet,
+	}
+}
+
+// Create перехватываем операцию создания и пишем запись аудита.
+func (adt *audit) Create(ctx context.Context, name string) (models.Walleter, error) {
+	wallet, err := adt.manWallet.Create(ctx, name)
+
+	record := &models.AuditRecord{Action: models.AuditActionCreate}
+	if wallet != nil {
+		record.WalletIDs = []string{wallet.ID()}
+		record.After = map[string]*models.AuditWalletState{wallet.ID(): state(wallet)}
+	}
+	adt.finish(ctx, record, err)
+	return wallet, err
+}
+
+// CreateBulk перехватываем пакетное создание и пишем запись аудита на каждый созданный кошелек.
+// Отклоненный пакет записывается одной записью.
+func (adt *audit) CreateBulk(ctx context.Context, items []models.NewWallet) ([]models.BulkCreateResult, error) {
+	results, err := adt.manWallet.CreateBulk(ctx, items)
+	if err != nil {
+		adt.finish(ctx, &models.AuditRecord{Action: models.AuditActionCreate}, err)
+		return results, err
+	}
+
+	for _, res := range results {
+		if res.Wallet == nil {
+			continue
+		}
+		adt.finish(ctx, &models.AuditRecord{
+			Action:    models.AuditActionCreate,
+			WalletIDs: []string{res.Wallet.ID()},
+			After:     map[string]*models.AuditWalletState{res.Wallet.ID(): state(res.Wallet)},
+		}, nil)
+	}
+	return results, nil
+}
+
+// ByID ...
+func (adt *audit) ByID(ctx context.Context, id string) (models.Walleter, error) {
+	return adt.manWallet.ByID(ctx, id)
+}
+
+// List ...
+func (adt *audit) List(ctx context.Context, filter models.WalletFilter) (*models.WalletPage, error) {
+	return adt.manWallet.List(ctx, filter)
+}
+
+// BalanceAt ...
+func (adt *audit) BalanceAt(ctx context.Context, id string, at time.Time) (models.WalletBalance, error) {
+	return adt.manWallet.BalanceAt(ctx, id, at)
+}
+
+// BalancesAt ...
+func (adt *audit) BalancesAt(ctx context.Context, at time.Time) ([]models.WalletBalance, error) {
+	return adt.manWallet.BalancesAt(ctx, at)
+}
+
+// IncreaseBalanceBy перехватываем операцию пополнения и пишем запись аудита.
+func (adt *audit) IncreaseBalanceBy(ctx context.Context, id string, amount float64) (models.OperationResult, error) {
+	record := adt.start(models.AuditActionDeposit, amount, id)
+	result, err := adt.manWallet.IncreaseBalanceBy(ctx, id, amount)
+	adt.finishOperation(ctx, record, result, err)
+	return result, err
+}
+
+// DecreaseBalanceBy перехватываем операцию снятия и пишем запись аудита.
+func (adt *audit) DecreaseBalanceBy(ctx context.Context, id string, amount float64) (models.OperationResult, error) {
+	record := adt.start(models.AuditActionWithdraw, amount, id)
+	result, err := adt.manWallet.DecreaseBalanceBy(ctx, id, amount)
+	adt.finishOperation(ctx, record, result, err)
+	return result, err
+}
+
+// TransferBalance перехватываем операцию перевода и пишем запись аудита.
+func (adt *audit) TransferBalance(ctx context.Context, fromID, toID string, amount float64) (models.OperationResult, error) {
+	record := adt.start(models.AuditActionTransfer, amount, fromID, toID)
+	result, err := adt.manWallet.TransferBalance(ctx, fromID, toID, amount)
+	adt.finishOperation(ctx, record, result, err)
+	return result, err
+}
+
+// TransferBatch перехватываем пакетный перевод и пишем одну запись аудита на весь пакет.
+func (adt *audit) TransferBatch(ctx context.Context, legs []models.TransferLeg) (models.BatchResult, error) {
+	var total float64
+	ids := make([]string, 0, len(legs)*2)
+	seen := make(map[string]struct{}, len(legs)*2)
+	for _, leg := range legs {
+		total += leg.Amount
+		for _, id := range []string{leg.FromID, leg.ToID} {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	record := adt.start(models.AuditActionBatch, total, ids...)
+	result, err := adt.manWallet.TransferBatch(ctx, legs)
+	record.Fee = result.Fee
+	record.OperationID = result.OperationID
+	adt.finish(ctx, record, err)
+	return result, err
+}
+
+// CreateHold перехватываем блокировку средств и пишем запись аудита.
+func (adt *audit) CreateHold(ctx context.Context, walletID string, amount float64, ttl time.Duration) (models.Hold, error) {
+	record := adt.start(models.AuditActionHold, amount, walletID)
+	hold, err := adt.manWallet.CreateHold(ctx, walletID, amount, ttl)
+	record.HoldID = hold.ID
+	adt.finish(ctx, record, err)
+	return hold, err
+}
+
+// CaptureHold перехватываем захват блокировки и пишем запись аудита со списанной суммой.
+func (adt *audit) CaptureHold(ctx context.Context, holdID string, amount float64, toID string) (models.Hold, error) {
+	ids := adt.holdWallets(holdID)
+	if toID != "" {
+		ids = append(ids, toID)
+	}
+	record := adt.start(models.AuditActionCapture, amount, ids...)
+	record.HoldID = holdID
+	hold, err := adt.manWallet.CaptureHold(ctx, holdID, amount, toID)
+	if err == nil {
+		record.Amount = hold.Captured
+		record.Fee = hold.Fee
+		record.OperationID = hold.OperationID
+	}
+	adt.finish(ctx, record, err)
+	return hold, err
+}
+
+// ReleaseHold перехватываем освобождение блокировки и пишем запись аудита.
+func (adt *audit) ReleaseHold(ctx context.Context, holdID string) (models.Hold, error) {
+	record := adt.start(models.AuditActionRelease, 0, adt.holdWallets(holdID)...)
+	record.HoldID = holdID
+	hold, err := adt.manWallet.ReleaseHold(ctx, holdID)
+	if err == nil {
+		record.Amount = hold.Amount
+	}
+	adt.finish(ctx, record, err)
+	return hold, err
+}
+
+// ReverseOperation перехватываем сторнирование и пишем запись аудита по кошелькам исходной операции.
+func (adt *audit) ReverseOperation(ctx context.Context, operationID string, amount float64) (models.OperationResult, error) {
+	record := adt.start(models.AuditActionReverse, amount, adt.operationWallets(operationID)...)
+	record.Reverses = operationID
+	result, err := adt.manWallet.ReverseOperation(ctx, operationID, amount)
+	if err == nil {
+		record.Amount = result.Amount
+	}
+	adt.finishOperation(ctx, record, result, err)
+	return result, err
+}
+
+// DeactivateByID перехватываем операцию деактивации и пишем запись аудита.
+func (adt *audit) DeactivateByID(ctx context.Context, id string, version *uint64) error {
+	record := adt.start(models.AuditActionDeactivate, 0, id)
+	err := adt.manWallet.DeactivateByID(ctx, id, version)
+	adt.finish(ctx, record, err)
+	return err
+}
+
+// SetCreditLimit перехватываем изменение овердрафта и пишем запись аудита.
+func (adt *audit) SetCreditLimit(ctx context.Context, id string, limit float64, version *uint64) error {
+	record := adt.start(models.AuditActionCreditLimit, limit, id)
+	err := adt.manWallet.SetCreditLimit(ctx, id, limit, version)
+	adt.finish(ctx, record, err)
+	return err
+}
+
+// UpdateName перехватываем операцию переименования и пишем запись аудита.
+func (adt *audit) UpdateName(ctx context.Context, id, name string, version *uint64) error {
+	record := adt.start(models.AuditActionRename, 0, id)
+	err := adt.manWallet.UpdateName(ctx, id, name, version)
+	adt.finish(ctx, record, err)
+	return err
+}
+
+// start запись аудита с состоянием кошельков до операции.
+func (adt *audit) start(action string, amount float64, ids ...string) *models.AuditRecord {
+	return &models.AuditRecord{
+		Action:    action,
+		WalletIDs: ids,
+		Amount:    amount,
+		Before:    adt.snapshot(ids),
+	}
+}
+
+// finish дополняем запись состоянием после операции и результатом, пишем в журнал.
+func (adt *audit) finish(ctx context.Context, record *models.AuditRecord, err error) {
+	if record.After == nil {
+		record.After = adt.snapshot(record.WalletIDs)
+	}
+	record.Outcome = outcome(err)
+	if err != nil {
+		record.Error = err.Error()
+	}
+	adt.recorder.record(ctx, record)
+}
+
+// holdWallets кошелек блокировки, пустой список если блокировка не найдена.
+func (adt *audit) holdWallets(holdID string) []string {
+	hold, err := adt.repoWallet.HoldByID(holdID)
+	if err != nil {
+		return nil
+	}
+	return []string{hold.WalletID}
+}
+
+// operationWallets кошельки операции журнала, пустой список если операция не найдена.
+func (adt *audit) operationWallets(operationID string) []string {
+	entries, err := adt.repoWallet.Ledger(models.LedgerFilter{OperationID: operationID})
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if _, ok := seen[entry.WalletID]; !ok {
+			seen[entry.WalletID] = struct{}{}
+			ids = append(ids, entry.WalletID)
+		}
+	}
+	return ids
+}
+
+// finishOperation дополняем запись операции с балансом комиссией и идентификатором операции.
+func (adt *audit) finishOperation(ctx context.Context, record *models.AuditRecord, result models.OperationResult, err error) {
+	record.Fee = result.Fee
+	record.OperationID = result.OperationID
+	adt.finish(ctx, record, err)
+}
+
+// snapshot состояние существующих кошельков из ids.
+func (adt *audit) snapshot(ids []string) map[string]*models.AuditWalletState {
+	states := make(map[string]*models.AuditWalletState, len(ids))
+	for _, id := range ids {
+		wallet, err := adt.repoWallet.ByID(id)
+		if err != nil {
+			continue
+		}
+		states[id] = state(wallet)
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	return states
+}
+
+func state(wallet models.Walleter) *models.AuditWalletState {
+	return &models.AuditWalletState{
+		Name:        wallet.Name(),
+		Balance:     wallet.Balance(),
+		Held:        wallet.Held(),
+		CreditLimit: wallet.CreditLimit(),
+		Status:      wallet.Status(),
+		Owner:       wallet.Owner(),
+	}
+}
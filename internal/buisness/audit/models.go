@@ -0,0 +1,16 @@
+package audit
+
+import "github.com/Nizom98/wallet/internal/models"
+
+type walletGetter interface {
+	ByID(id string) (models.Walleter, error)
+	HoldByID(id string) (models.Hold, error)
+	Ledger(filter models.LedgerFilter) ([]models.LedgerEntry, error)
+}
+
+type audit struct {
+	manWallet models.WalletManager
+	recorder  *Recorder
+	// repoWallet для снимков состояния кошельков до и после операции(без проверок доступа)
+	repoWallet walletGetter
+}
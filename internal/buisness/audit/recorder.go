@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Recorder формирует записи аудита из контекста запроса и пишет их в хранилище.
+type Recorder struct {
+	store models.AuditStore
+	now   func() time.Time
+}
+
+// NewRecorder конструктор записи аудита.
+func NewRecorder(store models.AuditStore) *Recorder {
+	return &Recorder{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// RecordDenied фиксируем отказ в доступе по политике.
+func (rec *Recorder) RecordDenied(ctx context.Context, perm models.Permission) {
+	rec.record(ctx, &models.AuditRecord{
+		Action:  models.AuditActionAccessDenied,
+		Outcome: models.AuditOutcomeDenied,
+		Error:   string(perm),
+	})
+}
+
+// record заполняем клиента, метаданные запроса и время, пишем запись.
+// Ошибка записи не прерывает операцию и пишется в лог.
+func (rec *Recorder) record(ctx context.Context, record *models.AuditRecord) {
+	if principal := models.PrincipalFromContext(ctx); principal != nil {
+		record.Actor = principal.ID
+	}
+	meta := models.RequestMetaFromContext(ctx)
+	record.RequestID = meta.ID
+	record.ClientIP = meta.ClientIP
+	record.Time = rec.now()
+
+	err := rec.store.Append(record)
+	if err != nil {
+		log.Errorf("audit record (action: %s, actor: %s) NOT written: %s", record.Action, record.Actor, err.Error())
+	}
+}
+
+// outcome результат операции для журнала.
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return models.AuditOutcomeSuccess
+	case errors.Is(err, models.ErrForbidden):
+		return models.AuditOutcomeDenied
+	default:
+		return models.AuditOutcomeFailure
+	}
+}
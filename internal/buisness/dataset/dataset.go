@@ -0,0 +1,110 @@
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// Backup выгрузка и загрузка всех кошельков хранилища вместе с журналом операций.
+type Backup struct {
+	store models.DatasetStore
+	now   func() time.Time
+}
+
+// NewBackup конструктор выгрузки и загрузки хранилища store.
+func NewBackup(store models.DatasetStore) *Backup {
+	return &Backup{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Export пишем в w согласованную копию хранилища.
+func (b *Backup) Export(w io.Writer) (models.DatasetSummary, error) {
+	ds, err := b.store.Dump()
+	if err != nil {
+		return models.DatasetSummary{}, fmt.Errorf("cannot dump wallets: %w", err)
+	}
+
+	createdAt := b.now().UTC()
+	err = Write(w, ds, createdAt)
+	if err != nil {
+		return models.DatasetSummary{}, err
+	}
+
+	return models.DatasetSummary{
+		Version:   formatVersion,
+		CreatedAt: createdAt,
+		Wallets:   len(ds.Wallets),
+		Entries:   len(ds.Ledger),
+	}, nil
+}
+
+// Import читаем выгрузку из r, проверяем ее и загружаем в хранилище целиком.
+// Баланс каждого кошелька должен сходиться с суммой его проводок.
+func (b *Backup) Import(r io.Reader) (models.DatasetSummary, error) {
+	ds, summary, err := Read(r)
+	if err != nil {
+		return models.DatasetSummary{}, err
+	}
+	err = Validate(ds)
+	if err != nil {
+		return models.DatasetSummary{}, err
+	}
+
+	err = b.store.Restore(ds)
+	if err != nil {
+		return models.DatasetSummary{}, fmt.Errorf("cannot restore wallets: %w", err)
+	}
+	return summary, nil
+}
+
+// Validate проверяем согласованность выгрузки: уникальность идентификаторов,
+// принадлежность проводок кошелькам выгрузки и баланс каждого кошелька по журналу.
+func Validate(ds *models.Dataset) error {
+	expected := make(map[string]float64, len(ds.Wallets))
+	holds := make(map[string]struct{})
+	for _, dump := range ds.Wallets {
+		if dump.ID == "" {
+			return fmt.Errorf("wallet without id: %w", errInvalidDataset)
+		}
+		if _, ok := expected[dump.ID]; ok {
+			return fmt.Errorf("wallet %s is duplicated: %w", dump.ID, errInvalidDataset)
+		}
+		if dump.Version == 0 {
+			return fmt.Errorf("wallet %s has no version: %w", dump.ID, errInvalidDataset)
+		}
+		expected[dump.ID] = 0
+
+		for _, hold := range dump.Holds {
+			if hold.ID == "" || hold.Amount <= 0 {
+				return fmt.Errorf("wallet %s has invalid hold %q: %w", dump.ID, hold.ID, errInvalidDataset)
+			}
+			if _, ok := holds[hold.ID]; ok {
+				return fmt.Errorf("hold %s is duplicated: %w", hold.ID, errInvalidDataset)
+			}
+			holds[hold.ID] = struct{}{}
+		}
+	}
+
+	for _, entry := range ds.Ledger {
+		if entry.OperationID == "" || entry.Type == "" {
+			return fmt.Errorf("entry of wallet %s without operation: %w", entry.WalletID, errInvalidDataset)
+		}
+		if _, ok := expected[entry.WalletID]; !ok {
+			return fmt.Errorf("operation %s: unknown wallet %s: %w", entry.OperationID, entry.WalletID, errInvalidDataset)
+		}
+		expected[entry.WalletID] += entry.Amount
+	}
+
+	for _, dump := range ds.Wallets {
+		if math.Abs(dump.Balance-expected[dump.ID]) > models.BalanceTolerance {
+			return fmt.Errorf("wallet %s balance %g, ledger %g: %w", dump.ID, dump.Balance, expected[dump.ID], errInvalidDataset)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/buisness/wallet"
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportRepo(t *testing.T) (*repository.WalletRepository, []byte) {
+	repo := repository.NewRepo()
+	ctx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "owner"})
+	a := repo.Create("a", 100, true, "owner", nil)
+	b := repo.Create("b", 0, true, "owner", nil)
+	_, err := wallet.NewManager(repo).TransferBalance(ctx, a.ID(), b.ID(), 40)
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	summary, err := NewBackup(repo).Export(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, summary.Wallets)
+	assert.Equal(t, 3, summary.Entries)
+	return repo, buf.Bytes()
+}
+
+func TestExportImport(t *testing.T) {
+	src, data := exportRepo(t)
+	assert.Equal(t, 7, bytes.Count(data, []byte("\n")))
+
+	dst := repository.NewRepo()
+	summary, err := NewBackup(dst).Import(bytes.NewReader(data))
+	assert.Nil(t, err)
+	assert.Equal(t, formatVersion, summary.Version)
+	assert.Equal(t, src.All(), dst.All())
+
+	srcLedger, _ := src.Ledger(models.LedgerFilter{})
+	dstLedger, _ := dst.Ledger(models.LedgerFilter{})
+	assert.Equal(t, srcLedger, dstLedger)
+}
+
+func TestImport_invalid(t *testing.T) {
+	_, data := exportRepo(t)
+	lines := strings.SplitAfter(string(data), "\n")
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "unknown version", data: strings.Replace(string(data), `"version":1`, `"version":2`, 1)},
+		{name: "tampered line", data: strings.Replace(string(data), `"amount":40`, `"amount":45`, 1)},
+		{name: "truncated", data: strings.Join(lines[:len(lines)-2], "")},
+		{name: "missing line", data: strings.Join(append(append([]string(nil), lines[:2]...), lines[3:]...), "")},
+		{name: "data after end", data: string(data) + lines[1]},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dst := repository.NewRepo()
+			_, err := NewBackup(dst).Import(strings.NewReader(test.data))
+			assert.True(t, errors.Is(err, models.ErrInvalidArgument), err)
+			assert.Empty(t, dst.All())
+		})
+	}
+}
+
+func TestImport_balanceMismatch(t *testing.T) {
+	src, _ := exportRepo(t)
+	id := src.All()[1].ID()
+	// баланс изменен в обход журнала
+	assert.Nil(t, src.UpdateByID(id, models.WalletUpdate{Balance: utils.Ptr[float64](55)}))
+
+	var buf bytes.Buffer
+	_, err := NewBackup(src).Export(&buf)
+	assert.Nil(t, err)
+
+	dst := repository.NewRepo()
+	_, err = NewBackup(dst).Import(&buf)
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+	assert.Empty(t, dst.All())
+}
@@ -0,0 +1,277 @@
+package dataset
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// Файл выгрузки - JSON Lines: заголовок, строки кошельков, строки проводок журнала
+// в порядке записи и завершающая строка. Каждая строка данных содержит sha256 своего поля data,
+// завершающая строка - sha256 всех data подряд, по ней обнаруживается обрезанный файл.
+const (
+	formatName = "wallet-dataset"
+	// formatVersion версия формата, файлы более новых версий не загружаются
+	formatVersion = 1
+
+	kindWallet = "wallet"
+	kindEntry  = "entry"
+	kindEnd    = "end"
+
+	// maxLineSize самая длинная строка файла
+	maxLineSize = 16 << 20
+)
+
+var errInvalidDataset = fmt.Errorf("invalid dataset: %w", models.ErrInvalidArgument)
+
+// header первая строка файла выгрузки.
+type header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Wallets   int       `json:"wallets"`
+	Entries   int       `json:"entries"`
+}
+
+// line строка данных файла выгрузки.
+type line struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data,omitempty"`
+	// SHA256 hex sha256 поля data, у завершающей строки - всех data файла подряд
+	SHA256 string `json:"sha256"`
+}
+
+type walletData struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Balance     float64           `json:"balance"`
+	CreditLimit float64           `json:"credit_limit,omitempty"`
+	Status      bool              `json:"status"`
+	Owner       string            `json:"owner"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Version     uint64            `json:"version"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Holds       []holdData        `json:"holds,omitempty"`
+}
+
+type holdData struct {
+	ID        string    `json:"id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Write пишем выгрузку ds в формате JSON Lines.
+func Write(w io.Writer, ds *models.Dataset, createdAt time.Time) error {
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+	err := enc.Encode(header{
+		Format:    formatName,
+		Version:   formatVersion,
+		CreatedAt: createdAt.UTC(),
+		Wallets:   len(ds.Wallets),
+		Entries:   len(ds.Ledger),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot write header: %w", err)
+	}
+
+	total := sha256.New()
+	for _, dump := range ds.Wallets {
+		err = writeLine(enc, total, kindWallet, toWalletData(dump))
+		if err != nil {
+			return fmt.Errorf("cannot write wallet %s: %w", dump.ID, err)
+		}
+	}
+	for _, entry := range ds.Ledger {
+		err = writeLine(enc, total, kindEntry, entry)
+		if err != nil {
+			return fmt.Errorf("cannot write entry of operation %s: %w", entry.OperationID, err)
+		}
+	}
+
+	err = enc.Encode(line{Kind: kindEnd, SHA256: hex.EncodeToString(total.Sum(nil))})
+	if err != nil {
+		return fmt.Errorf("cannot write end: %w", err)
+	}
+	return out.Flush()
+}
+
+// Read читаем выгрузку и проверяем заголовок, контрольные суммы и число строк.
+func Read(r io.Reader) (*models.Dataset, models.DatasetSummary, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	hdr, err := readHeader(scanner)
+	if err != nil {
+		return nil, models.DatasetSummary{}, err
+	}
+
+	ds := &models.Dataset{
+		Wallets: make([]models.WalletDump, 0, hdr.Wallets),
+		Ledger:  make([]models.LedgerEntry, 0, hdr.Entries),
+	}
+	total := sha256.New()
+	num := 1
+	ended := false
+	for !ended && scanner.Scan() {
+		num++
+		var ln line
+		err = json.Unmarshal(scanner.Bytes(), &ln)
+		if err != nil {
+			return nil, models.DatasetSummary{}, fmt.Errorf("line %d: %s: %w", num, err.Error(), errInvalidDataset)
+		}
+		if ln.Kind == kindEnd {
+			if ln.SHA256 != hex.EncodeToString(total.Sum(nil)) {
+				return nil, models.DatasetSummary{}, fmt.Errorf("line %d: dataset checksum mismatch: %w", num, errInvalidDataset)
+			}
+			ended = true
+			continue
+		}
+
+		err = readLine(ds, ln, total)
+		if err != nil {
+			return nil, models.DatasetSummary{}, fmt.Errorf("line %d: %w", num, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, models.DatasetSummary{}, fmt.Errorf("cannot read dataset: %w", err)
+	}
+	if !ended {
+		return nil, models.DatasetSummary{}, fmt.Errorf("no end line, dataset is truncated: %w", errInvalidDataset)
+	}
+	if scanner.Scan() {
+		return nil, models.DatasetSummary{}, fmt.Errorf("line %d: data after end line: %w", num+1, errInvalidDataset)
+	}
+	if len(ds.Wallets) != hdr.Wallets || len(ds.Ledger) != hdr.Entries {
+		return nil, models.DatasetSummary{}, fmt.Errorf("header declares %d wallets and %d entries, got %d and %d: %w",
+			hdr.Wallets, hdr.Entries, len(ds.Wallets), len(ds.Ledger), errInvalidDataset)
+	}
+
+	return ds, models.DatasetSummary{
+		Version:   hdr.Version,
+		CreatedAt: hdr.CreatedAt,
+		Wallets:   hdr.Wallets,
+		Entries:   hdr.Entries,
+	}, nil
+}
+
+func readHeader(scanner *bufio.Scanner) (header, error) {
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return header{}, fmt.Errorf("cannot read dataset: %w", err)
+		}
+		return header{}, fmt.Errorf("empty file: %w", errInvalidDataset)
+	}
+
+	var hdr header
+	err := json.Unmarshal(scanner.Bytes(), &hdr)
+	if err != nil {
+		return header{}, fmt.Errorf("header: %s: %w", err.Error(), errInvalidDataset)
+	}
+	if hdr.Format != formatName {
+		return header{}, fmt.Errorf("unknown format %q: %w", hdr.Format, errInvalidDataset)
+	}
+	if hdr.Version < 1 || hdr.Version > formatVersion {
+		return header{}, fmt.Errorf("unsupported version %d: %w", hdr.Version, errInvalidDataset)
+	}
+	return hdr, nil
+}
+
+// writeLine пишем строку данных kind и добавляем ее data к общей контрольной сумме total.
+func writeLine(enc *json.Encoder, total hash.Hash, kind string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	total.Write(data)
+	sum := sha256.Sum256(data)
+	return enc.Encode(line{Kind: kind, Data: data, SHA256: hex.EncodeToString(sum[:])})
+}
+
+// readLine проверяем контрольную сумму строки данных и добавляем ее запись в ds.
+func readLine(ds *models.Dataset, ln line, total hash.Hash) error {
+	sum := sha256.Sum256(ln.Data)
+	if ln.SHA256 != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("checksum mismatch: %w", errInvalidDataset)
+	}
+	total.Write(ln.Data)
+
+	switch ln.Kind {
+	case kindWallet:
+		var data walletData
+		err := json.Unmarshal(ln.Data, &data)
+		if err != nil {
+			return fmt.Errorf("wallet: %s: %w", err.Error(), errInvalidDataset)
+		}
+		ds.Wallets = append(ds.Wallets, fromWalletData(data))
+	case kindEntry:
+		var entry models.LedgerEntry
+		err := json.Unmarshal(ln.Data, &entry)
+		if err != nil {
+			return fmt.Errorf("entry: %s: %w", err.Error(), errInvalidDataset)
+		}
+		ds.Ledger = append(ds.Ledger, entry)
+	default:
+		return fmt.Errorf("unknown kind %q: %w", ln.Kind, errInvalidDataset)
+	}
+	return nil
+}
+
+func toWalletData(dump models.WalletDump) walletData {
+	data := walletData{
+		ID:          dump.ID,
+		Name:        dump.Name,
+		Balance:     dump.Balance,
+		CreditLimit: dump.CreditLimit,
+		Status:      dump.Status,
+		Owner:       dump.Owner,
+		CreatedAt:   dump.CreatedAt,
+		UpdatedAt:   dump.UpdatedAt,
+		Version:     dump.Version,
+		Metadata:    dump.Metadata,
+	}
+	for _, hold := range dump.Holds {
+		data.Holds = append(data.Holds, holdData{
+			ID:        hold.ID,
+			Amount:    hold.Amount,
+			CreatedAt: hold.CreatedAt,
+			ExpiresAt: hold.ExpiresAt,
+		})
+	}
+	return data
+}
+
+func fromWalletData(data walletData) models.WalletDump {
+	dump := models.WalletDump{
+		ID:          data.ID,
+		Name:        data.Name,
+		Balance:     data.Balance,
+		CreditLimit: data.CreditLimit,
+		Status:      data.Status,
+		Owner:       data.Owner,
+		CreatedAt:   data.CreatedAt,
+		UpdatedAt:   data.UpdatedAt,
+		Version:     data.Version,
+		Metadata:    data.Metadata,
+	}
+	for _, hold := range data.Holds {
+		dump.Holds = append(dump.Holds, models.Hold{
+			ID:        hold.ID,
+			WalletID:  data.ID,
+			Amount:    hold.Amount,
+			Status:    models.HoldStatusActive,
+			CreatedAt: hold.CreatedAt,
+			ExpiresAt: hold.ExpiresAt,
+		})
+	}
+	return dump
+}
@@ -0,0 +1,95 @@
+package fee
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// Engine расчет комиссий операций по правилам.
+type Engine struct {
+	collector string
+	rules     map[models.OperationType]models.FeeRule
+}
+
+// NewEngine конструктор расчета комиссий.
+// Ступени правил упорядочиваются по верхней границе, ступень без границы должна быть одна.
+func NewEngine(rules models.FeeRules) (*Engine, error) {
+	if len(rules.Operations) > 0 && rules.WalletID == "" {
+		return nil, fmt.Errorf("fee wallet is not set")
+	}
+
+	engine := &Engine{
+		collector: rules.WalletID,
+		rules:     make(map[models.OperationType]models.FeeRule, len(rules.Operations)),
+	}
+	for op, rule := range rules.Operations {
+		if rule.Percent < 0 || rule.Percent > 100 || rule.Flat < 0 || rule.Min < 0 || rule.Max < 0 {
+			return nil, fmt.Errorf("operation %s: negative fee or percent out of range", op)
+		}
+		if rule.Max > 0 && rule.Min > rule.Max {
+			return nil, fmt.Errorf("operation %s: min fee greater than max", op)
+		}
+
+		tiers := append([]models.FeeTier(nil), rule.Tiers...)
+		sort.SliceStable(tiers, func(i, j int) bool {
+			return tierBound(tiers[i]) < tierBound(tiers[j])
+		})
+		for i, tier := range tiers {
+			if tier.UpTo == 0 && i != len(tiers)-1 {
+				return nil, fmt.Errorf("operation %s: only one tier can be unbounded", op)
+			}
+			if tier.Percent < 0 || tier.Percent > 100 || tier.Flat < 0 {
+				return nil, fmt.Errorf("operation %s: tier %d: negative fee or percent out of range", op, i)
+			}
+		}
+		rule.Tiers = tiers
+		engine.rules[op] = rule
+	}
+
+	return engine, nil
+}
+
+// Collector кошелек, на который зачисляются комиссии.
+func (engine *Engine) Collector() string {
+	return engine.collector
+}
+
+// Fee комиссия операции op на сумму amount, 0 если для операции нет правила.
+func (engine *Engine) Fee(op models.OperationType, amount float64) float64 {
+	rule, ok := engine.rules[op]
+	if !ok {
+		return 0
+	}
+
+	flat, percent := rule.Flat, rule.Percent
+	if len(rule.Tiers) > 0 {
+		// сумма больше всех ограниченных ступеней без ступени без границы считается по последней
+		tier := rule.Tiers[len(rule.Tiers)-1]
+		for _, t := range rule.Tiers {
+			if t.UpTo == 0 || amount <= t.UpTo {
+				tier = t
+				break
+			}
+		}
+		flat, percent = tier.Flat, tier.Percent
+	}
+
+	fee := flat + amount*percent/100
+	if fee < rule.Min {
+		fee = rule.Min
+	}
+	if rule.Max > 0 && fee > rule.Max {
+		fee = rule.Max
+	}
+	return fee
+}
+
+// tierBound граница ступени для сортировки, ступень без границы последняя.
+func tierBound(tier models.FeeTier) float64 {
+	if tier.UpTo == 0 {
+		return float64(^uint64(0) >> 1)
+	}
+	return tier.UpTo
+}
@@ -0,0 +1,49 @@
+package fee
+
+import (
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFee(t *testing.T) {
+	engine, err := NewEngine(models.FeeRules{
+		WalletID: "fees",
+		Operations: map[models.OperationType]models.FeeRule{
+			models.OperationWithdraw: {Flat: 1, Percent: 1, Min: 2, Max: 10},
+			models.OperationTransfer: {
+				Tiers: []models.FeeTier{
+					{UpTo: 0, Percent: 0.5},
+					{UpTo: 100, Flat: 1},
+					{UpTo: 1000, Percent: 1},
+				},
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "fees", engine.Collector())
+
+	assert.Equal(t, float64(2), engine.Fee(models.OperationWithdraw, 50))
+	assert.Equal(t, float64(6), engine.Fee(models.OperationWithdraw, 500))
+	assert.Equal(t, float64(10), engine.Fee(models.OperationWithdraw, 5000))
+
+	assert.Equal(t, float64(1), engine.Fee(models.OperationTransfer, 100))
+	assert.Equal(t, float64(5), engine.Fee(models.OperationTransfer, 500))
+	assert.Equal(t, float64(25), engine.Fee(models.OperationTransfer, 5000))
+
+	assert.Equal(t, float64(0), engine.Fee(models.OperationDeposit, 500))
+}
+
+func TestNewEngine_invalid(t *testing.T) {
+	_, err := NewEngine(models.FeeRules{
+		Operations: map[models.OperationType]models.FeeRule{models.OperationWithdraw: {Flat: 1}},
+	})
+	assert.NotNil(t, err)
+
+	_, err = NewEngine(models.FeeRules{
+		WalletID:   "fees",
+		Operations: map[models.OperationType]models.FeeRule{models.OperationWithdraw: {Min: 5, Max: 1}},
+	})
+	assert.NotNil(t, err)
+}
@@ -8,6 +8,12 @@ const (
 	eventWalletDeposited  = "Wallet_Deposited"
 	eventWalletWithdrawn  = "Wallet_Withdrawn"
 	eventWalletTransfered = "Wallet_Transfered"
+	eventBatchTransfered  = "Wallet_BatchTransfered"
+	eventHoldCreated      = "Wallet_HoldCreated"
+	eventHoldCaptured     = "Wallet_HoldCaptured"
+	eventHoldReleased     = "Wallet_HoldReleased"
+	eventScheduleFailed   = "Wallet_ScheduleFailed"
+	eventWalletReversed   = "Wallet_Reversed"
 )
 
 type msgSender interface {
@@ -22,4 +28,28 @@ type notify struct {
 type eventData struct {
 	Type   string  `json:"type"`
 	Amount float64 `json:"amount"`
+	// Fee комиссия операции.
+	Fee float64 `json:"fee,omitempty"`
+	// OperationID операция журнала операций.
+	OperationID string `json:"operation_id,omitempty"`
+	// Reverses сторнированная операция для события сторнирования.
+	Reverses string `json:"reverses,omitempty"`
+	// HoldID блокировка средств для событий блокировки.
+	HoldID string `json:"hold_id,omitempty"`
+	// ScheduleID регулярный перевод для события невыполненного перевода.
+	ScheduleID string `json:"schedule_id,omitempty"`
+	// Error причина невыполненного перевода.
+	Error string `json:"error,omitempty"`
+	// Legs переводы пакетного перевода.
+	Legs []eventLeg `json:"legs,omitempty"`
+	// Trace контекст трассировки(traceparent, tracestate) для продолжения трейса консьюмерами.
+	Trace map[string]string `json:"trace,omitempty"`
+}
+
+type eventLeg struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Amount float64 `json:"amount"`
+	// Fee комиссия перевода.
+	Fee float64 `json:"fee,omitempty"`
 }
@@ -1,12 +1,21 @@
 package notify
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/Nizom98/wallet/internal/models"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/Nizom98/wallet/internal/buisness/notify")
+
 // NewManager конструктор уведомителя операций.
 func NewManager(msgClient msgSender, manWallet models.WalletManager) *notify {
 	return &notify{
@@ -16,73 +25,226 @@ func NewManager(msgClient msgSender, manWallet models.WalletManager) *notify {
 }
 
 // Create перехватываем операцию создания и отправляем событие в брокер.
-func (ntf *notify) Create(name string) (models.Walleter, error) {
-	wallet, err := ntf.manWallet.Create(name)
+func (ntf *notify) Create(ctx context.Context, name string) (models.Walleter, error) {
+	wallet, err := ntf.manWallet.Create(ctx, name)
 	if err != nil {
 		return wallet, err
 	}
 
-	ntf.sendEvent(eventWalletCreated, 0)
+	ntf.sendEvent(ctx, eventWalletCreated, 0)
 	return wallet, nil
 }
 
+// CreateBulk перехватываем пакетное создание и отправляем событие создания на каждый кошелек.
+func (ntf *notify) CreateBulk(ctx context.Context, items []models.NewWallet) ([]models.BulkCreateResult, error) {
+	results, err := ntf.manWallet.CreateBulk(ctx, items)
+	if err != nil {
+		return results, err
+	}
+
+	for _, res := range results {
+		if res.Wallet != nil {
+			ntf.sendEvent(ctx, eventWalletCreated, 0)
+		}
+	}
+	return results, nil
+}
+
 // ByID ...
-func (ntf *notify) ByID(id string) (models.Walleter, error) {
-	return ntf.manWallet.ByID(id)
+func (ntf *notify) ByID(ctx context.Context, id string) (models.Walleter, error) {
+	return ntf.manWallet.ByID(ctx, id)
 }
 
 // List ...
-func (ntf *notify) List() []models.Walleter {
-	return ntf.manWallet.List()
+func (ntf *notify) List(ctx context.Context, filter models.WalletFilter) (*models.WalletPage, error) {
+	return ntf.manWallet.List(ctx, filter)
+}
+
+// BalanceAt ...
+func (ntf *notify) BalanceAt(ctx context.Context, id string, at time.Time) (models.WalletBalance, error) {
+	return ntf.manWallet.BalanceAt(ctx, id, at)
+}
+
+// BalancesAt ...
+func (ntf *notify) BalancesAt(ctx context.Context, at time.Time) ([]models.WalletBalance, error) {
+	return ntf.manWallet.BalancesAt(ctx, at)
 }
 
 // IncreaseBalanceBy перехватываем операцию пополнения и отправляем событие в брокер.
-func (ntf *notify) IncreaseBalanceBy(id string, amount float64) error {
-	err := ntf.manWallet.IncreaseBalanceBy(id, amount)
-	ntf.sendEvent(eventWalletDeposited, amount)
-	return err
+func (ntf *notify) IncreaseBalanceBy(ctx context.Context, id string, amount float64) (models.OperationResult, error) {
+	result, err := ntf.manWallet.IncreaseBalanceBy(ctx, id, amount)
+	ntf.sendOperation(ctx, eventWalletDeposited, amount, result)
+	return result, err
 }
 
 // DecreaseBalanceBy перехватываем операцию снятия и отправляем событие в брокер.
-func (ntf *notify) DecreaseBalanceBy(id string, amount float64) error {
-	err := ntf.manWallet.DecreaseBalanceBy(id, amount)
-	ntf.sendEvent(eventWalletWithdrawn, amount)
-	return err
+func (ntf *notify) DecreaseBalanceBy(ctx context.Context, id string, amount float64) (models.OperationResult, error) {
+	result, err := ntf.manWallet.DecreaseBalanceBy(ctx, id, amount)
+	ntf.sendOperation(ctx, eventWalletWithdrawn, amount, result)
+	return result, err
 }
 
 // TransferBalance перехватываем операцию перевода и отправляем событие в брокер.
-func (ntf *notify) TransferBalance(fromID, toID string, amount float64) error {
-	err := ntf.manWallet.TransferBalance(fromID, toID, amount)
-	ntf.sendEvent(eventWalletTransfered, amount)
-	return err
+func (ntf *notify) TransferBalance(ctx context.Context, fromID, toID string, amount float64) (models.OperationResult, error) {
+	result, err := ntf.manWallet.TransferBalance(ctx, fromID, toID, amount)
+	ntf.sendOperation(ctx, eventWalletTransfered, amount, result)
+	return result, err
+}
+
+// TransferBatch перехватываем пакетный перевод и отправляем одно событие со всеми переводами.
+// Событие отправляется только если пакет применен.
+func (ntf *notify) TransferBatch(ctx context.Context, legs []models.TransferLeg) (models.BatchResult, error) {
+	result, err := ntf.manWallet.TransferBatch(ctx, legs)
+	if err != nil {
+		return result, err
+	}
+
+	event := &eventData{
+		Type:        eventBatchTransfered,
+		Amount:      result.Amount,
+		Fee:         result.Fee,
+		OperationID: result.OperationID,
+		Legs:        make([]eventLeg, 0, len(legs)),
+	}
+	for i, leg := range legs {
+		event.Legs = append(event.Legs, eventLeg{From: leg.FromID, To: leg.ToID, Amount: leg.Amount, Fee: result.Fees[i]})
+	}
+	ntf.publish(ctx, event)
+	return result, nil
+}
+
+// CreateHold перехватываем блокировку средств и отправляем событие, если блокировка создана.
+func (ntf *notify) CreateHold(ctx context.Context, walletID string, amount float64, ttl time.Duration) (models.Hold, error) {
+	hold, err := ntf.manWallet.CreateHold(ctx, walletID, amount, ttl)
+	if err != nil {
+		return hold, err
+	}
+
+	ntf.publish(ctx, &eventData{Type: eventHoldCreated, Amount: hold.Amount, HoldID: hold.ID})
+	return hold, nil
+}
+
+// CaptureHold перехватываем захват блокировки и отправляем событие со списанной суммой.
+func (ntf *notify) CaptureHold(ctx context.Context, holdID string, amount float64, toID string) (models.Hold, error) {
+	hold, err := ntf.manWallet.CaptureHold(ctx, holdID, amount, toID)
+	if err != nil {
+		return hold, err
+	}
+
+	ntf.publish(ctx, &eventData{
+		Type:        eventHoldCaptured,
+		Amount:      hold.Captured,
+		Fee:         hold.Fee,
+		OperationID: hold.OperationID,
+		HoldID:      hold.ID,
+	})
+	return hold, nil
+}
+
+// ReleaseHold перехватываем освобождение блокировки и отправляем событие.
+func (ntf *notify) ReleaseHold(ctx context.Context, holdID string) (models.Hold, error) {
+	hold, err := ntf.manWallet.ReleaseHold(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+
+	ntf.publish(ctx, &eventData{Type: eventHoldReleased, Amount: hold.Amount, HoldID: hold.ID})
+	return hold, nil
 }
 
 // DeactivateByID перехватываем операцию деактивации и отправляем событие в брокер.
-func (ntf *notify) DeactivateByID(id string) error {
-	err := ntf.manWallet.DeactivateByID(id)
-	ntf.sendEvent(eventWalletDeleted, 0)
+func (ntf *notify) DeactivateByID(ctx context.Context, id string, version *uint64) error {
+	err := ntf.manWallet.DeactivateByID(ctx, id, version)
+	ntf.sendEvent(ctx, eventWalletDeleted, 0)
 	return err
 }
 
+// ReverseOperation перехватываем сторнирование и отправляем событие, если операция сторнирована.
+func (ntf *notify) ReverseOperation(ctx context.Context, operationID string, amount float64) (models.OperationResult, error) {
+	result, err := ntf.manWallet.ReverseOperation(ctx, operationID, amount)
+	if err != nil {
+		return result, err
+	}
+
+	ntf.publish(ctx, &eventData{
+		Type:        eventWalletReversed,
+		Amount:      result.Amount,
+		Fee:         result.Fee,
+		OperationID: result.OperationID,
+		Reverses:    result.Reverses,
+	})
+	return result, nil
+}
+
+// SetCreditLimit ...
+func (ntf *notify) SetCreditLimit(ctx context.Context, id string, limit float64, version *uint64) error {
+	return ntf.manWallet.SetCreditLimit(ctx, id, limit, version)
+}
+
 // UpdateName ...
-func (ntf *notify) UpdateName(id, name string) error {
-	return ntf.manWallet.UpdateName(id, name)
+func (ntf *notify) UpdateName(ctx context.Context, id, name string, version *uint64) error {
+	return ntf.manWallet.UpdateName(ctx, id, name, version)
 }
 
-// sendEvent отправляем сообщение брокеру.
-// Если возникнет ошибка, то данные запишутся в лог.
-func (ntf *notify) sendEvent(eventType string, amount float64) {
+// ScheduleFailed отправляем событие о регулярном переводе, не выполненном после всех повторов.
+func (ntf *notify) ScheduleFailed(ctx context.Context, schedule models.Schedule, err error) {
 	event := &eventData{
+		Type:       eventScheduleFailed,
+		Amount:     schedule.Amount,
+		ScheduleID: schedule.ID,
+		Legs:       []eventLeg{{From: schedule.FromID, To: schedule.ToID, Amount: schedule.Amount}},
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	ntf.publish(ctx, event)
+}
+
+// sendEvent отправляем сообщение брокеру.
+func (ntf *notify) sendEvent(ctx context.Context, eventType string, amount float64) {
+	ntf.publish(ctx, &eventData{
 		Type:   eventType,
 		Amount: amount,
-	}
+	})
+}
+
+// sendOperation отправляем брокеру событие операции с балансом вместе с комиссией.
+func (ntf *notify) sendOperation(ctx context.Context, eventType string, amount float64, result models.OperationResult) {
+	ntf.publish(ctx, &eventData{
+		Type:        eventType,
+		Amount:      amount,
+		Fee:         result.Fee,
+		OperationID: result.OperationID,
+	})
+}
+
+// publish отправляем событие брокеру.
+// Контекст трассировки передается в самом сообщении(поле trace).
+// Если возникнет ошибка, то данные запишутся в лог.
+func (ntf *notify) publish(ctx context.Context, event *eventData) {
+	ctx, span := tracer.Start(ctx, "notify.sendEvent",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "nsq"),
+			attribute.String("event.type", event.Type),
+		),
+	)
+	defer span.End()
+
+	event.Trace = make(map[string]string)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(event.Trace))
+
 	bytes, err := json.Marshal(event)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		log.Errorf("err while marshaling event (type: %s, amount: %f): %s", event.Type, event.Amount, err.Error())
 		return
 	}
 	err = ntf.msgSender.Write(bytes)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		log.Errorf("event (type: %s, amount: %f) NOT sent to nsq: %s", event.Type, event.Amount, err.Error())
 		return
 	}
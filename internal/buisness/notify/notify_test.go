@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/clients/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+type fakeSender struct {
+	messages [][]byte
+}
+
+func (s *fakeSender) Write(data []byte) error {
+	s.messages = append(s.messages, data)
+	return nil
+}
+
+func TestSendEvent_traceContext(t *testing.T) {
+	_, exporter := tracingtest.NewInMemoryProvider()
+	sender := &fakeSender{}
+	ntf := NewManager(sender, nil)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	ntf.sendEvent(ctx, eventWalletDeposited, 10)
+	parent.End()
+
+	assert.Len(t, sender.messages, 1)
+	var event eventData
+	assert.Nil(t, json.Unmarshal(sender.messages[0], &event))
+	assert.Equal(t, eventWalletDeposited, event.Type)
+	assert.Contains(t, event.Trace["traceparent"], parent.SpanContext().TraceID().String())
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "notify.sendEvent", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+}
@@ -0,0 +1,141 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reconciler сверка балансов кошельков с журналом операций.
+// Баланс каждого кошелька пересчитывается как сумма его проводок и сравнивается с сохраненным.
+type Reconciler struct {
+	repo models.WalletRepository
+	// muLast для конкурентного доступа к last
+	muLast *sync.RWMutex
+	// last результат последней сверки
+	last *models.ReconcileReport
+	now  func() time.Time
+}
+
+// NewReconciler конструктор сверки балансов.
+func NewReconciler(repo models.WalletRepository) *Reconciler {
+	return &Reconciler{
+		repo:   repo,
+		muLast: new(sync.RWMutex),
+		now:    time.Now,
+	}
+}
+
+// Run сверяем все кошельки.
+// Каждый кошелек сверяется в своей транзакции, поэтому баланс и журнал кошелька читаются согласованно.
+// correct - записать по каждому расхождению проводку исправления(OperationCorrection),
+// после которой журнал сходится с сохраненным балансом. Сам баланс не меняется.
+func (rc *Reconciler) Run(ctx context.Context, correct bool) (*models.ReconcileReport, error) {
+	report := &models.ReconcileReport{
+		StartedAt:  rc.now().UTC(),
+		Mismatches: make([]models.BalanceMismatch, 0),
+		Corrected:  correct,
+	}
+
+	for _, wallet := range rc.repo.All() {
+		id := wallet.ID()
+		// found расхождение последнего выполнения fn: транзакция может повторяться,
+		// поэтому в отчет оно попадает только после фиксации
+		var found *models.BalanceMismatch
+		err := rc.repo.Transaction(ctx, []string{id}, func(repo models.WalletRepository) error {
+			found = nil
+			mismatch, err := check(repo, id)
+			if err != nil || mismatch == nil {
+				return err
+			}
+			if correct {
+				mismatch.CorrectionID, err = repo.RecordOperation(models.OperationCorrection, []models.LedgerEntry{
+					{WalletID: id, Amount: mismatch.Difference},
+				})
+				if err != nil {
+					return fmt.Errorf("cannot record correction: %w", err)
+				}
+			}
+			found = mismatch
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("wallet %s: %w", id, err)
+		}
+		if found != nil {
+			report.Mismatches = append(report.Mismatches, *found)
+		}
+		report.Wallets++
+	}
+	report.FinishedAt = rc.now().UTC()
+
+	rc.muLast.Lock()
+	rc.last = report
+	rc.muLast.Unlock()
+	return report, nil
+}
+
+// Last результат последней сверки, nil если сверка еще не запускалась.
+func (rc *Reconciler) Last() *models.ReconcileReport {
+	rc.muLast.RLock()
+	defer rc.muLast.RUnlock()
+
+	return rc.last
+}
+
+// RunEvery сверяем балансы каждые every, пока не отменен ctx.
+// Расхождения и ошибки сверки пишутся в лог.
+func (rc *Reconciler) RunEvery(ctx context.Context, every time.Duration, correct bool) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := rc.Run(ctx, correct)
+			if err != nil {
+				log.Errorf("reconcile failed: %s", err.Error())
+				continue
+			}
+			for _, m := range report.Mismatches {
+				log.Warnf("reconcile: wallet %s balance %f, ledger %f", m.WalletID, m.Stored, m.Expected)
+			}
+			log.Infof("reconcile: %d wallets checked, %d mismatches", report.Wallets, len(report.Mismatches))
+		}
+	}
+}
+
+// check пересчитываем баланс кошелька id по журналу, nil если баланс сходится.
+func check(repo models.WalletRepository, id string) (*models.BalanceMismatch, error) {
+	wallet, err := repo.ByID(id)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := repo.Ledger(models.LedgerFilter{WalletID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	var expected float64
+	for _, entry := range entries {
+		expected += entry.Amount
+	}
+	diff := wallet.Balance() - expected
+	if math.Abs(diff) <= models.BalanceTolerance {
+		return nil, nil
+	}
+
+	return &models.BalanceMismatch{
+		WalletID:   id,
+		Stored:     wallet.Balance(),
+		Expected:   expected,
+		Difference: diff,
+	}, nil
+}
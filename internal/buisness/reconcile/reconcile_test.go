@@ -0,0 +1,90 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/buisness/wallet"
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciler_mismatchAndCorrection(t *testing.T) {
+	repo := repository.NewRepo()
+	ctx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "owner"})
+	a := repo.Create("a", 100, true, "owner", nil)
+	b := repo.Create("b", 0, true, "owner", nil)
+	_, err := wallet.NewManager(repo).TransferBalance(ctx, a.ID(), b.ID(), 40)
+	assert.Nil(t, err)
+
+	rc := NewReconciler(repo)
+	assert.Nil(t, rc.Last())
+	report, err := rc.Run(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Wallets)
+	assert.Empty(t, report.Mismatches)
+
+	// баланс изменен в обход журнала
+	assert.Nil(t, repo.UpdateByID(b.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](55)}))
+	report, err = rc.Run(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Len(t, report.Mismatches, 1)
+	assert.Equal(t, models.BalanceMismatch{WalletID: b.ID(), Stored: 55, Expected: 40, Difference: 15}, report.Mismatches[0])
+	assert.Equal(t, report, rc.Last())
+
+	report, err = rc.Run(context.Background(), true)
+	assert.Nil(t, err)
+	assert.Len(t, report.Mismatches, 1)
+	assert.NotEmpty(t, report.Mismatches[0].CorrectionID)
+
+	got, err := repo.ByID(b.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(55), got.Balance())
+
+	report, err = rc.Run(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Empty(t, report.Mismatches)
+}
+
+// conflictRepo хранилище, в котором первые conflicts транзакций завершаются конфликтом:
+// после выполнения fn другой экземпляр меняет первый кошелек транзакции.
+type conflictRepo struct {
+	models.WalletRepository
+	other     models.WalletRepository
+	conflicts int
+}
+
+func (repo *conflictRepo) Transaction(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) error {
+	return repo.WalletRepository.Transaction(ctx, ids, func(tx models.WalletRepository) error {
+		err := fn(tx)
+		if err != nil || repo.conflicts == 0 {
+			return err
+		}
+		repo.conflicts--
+		return repo.other.UpdateByID(ids[0], models.WalletUpdate{})
+	})
+}
+
+func TestReconciler_retriedTransaction(t *testing.T) {
+	server := miniredis.RunT(t)
+	repos := make([]models.WalletRepository, 2)
+	for i := range repos {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		repos[i] = repository.NewRedisRepo(client, repository.RedisOptions{})
+	}
+	repo := &conflictRepo{WalletRepository: repos[0], other: repos[1]}
+	a := repo.Create("a", 0, true, "owner", nil)
+	assert.Nil(t, repo.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](15)}))
+
+	repo.conflicts = 1
+	report, err := NewReconciler(repo).Run(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, repo.conflicts)
+	assert.Equal(t, 1, report.Wallets)
+	assert.Len(t, report.Mismatches, 1)
+}
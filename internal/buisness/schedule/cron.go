@@ -0,0 +1,183 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// cronSearchYears дальше этого срока следующее время расписания не ищем(например, 30 февраля).
+const cronSearchYears = 5
+
+// cronMacros сокращенные расписания.
+var cronMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// cronSpec разобранное расписание, биты полей - допустимые значения.
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	// domAny, dowAny поле дня не ограничено(*).
+	// Если ограничены оба поля дня, подходит день, совпавший с любым из них.
+	domAny, dowAny bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// parseCron разбираем расписание из пяти полей: минута, час, день месяца, месяц, день недели(0 и 7 - воскресенье).
+// Поле - список через запятую из *, числа или диапазона a-b, к * и диапазону можно добавить шаг /n.
+func parseCron(expr string) (*cronSpec, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron %q: expected %d fields: %w", expr, len(cronFields), models.ErrInvalidArgument)
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, field := range cronFields {
+		var err error
+		bits[i], err = parseCronField(parts[i], field)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+	}
+
+	spec := &cronSpec{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}
+	if spec.dow&(1<<7) != 0 {
+		spec.dow |= 1
+	}
+	return spec, nil
+}
+
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			rng = item[:i]
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s: bad step %q: %w", field.name, item, models.ErrInvalidArgument)
+			}
+		}
+
+		from, to := field.min, field.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			from, err = cronNumber(bounds[0], field)
+			if err != nil {
+				return 0, err
+			}
+			to, err = cronNumber(bounds[1], field)
+			if err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("%s: bad range %q: %w", field.name, rng, models.ErrInvalidArgument)
+			}
+		default:
+			if step != 1 {
+				return 0, fmt.Errorf("%s: step without range %q: %w", field.name, item, models.ErrInvalidArgument)
+			}
+			n, err := cronNumber(rng, field)
+			if err != nil {
+				return 0, err
+			}
+			from, to = n, n
+		}
+
+		for n := from; n <= to; n += step {
+			bits |= 1 << uint(n)
+		}
+	}
+	return bits, nil
+}
+
+func cronNumber(value string, field cronField) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < field.min || n > field.max {
+		return 0, fmt.Errorf("%s: %q out of range %d-%d: %w", field.name, value, field.min, field.max, models.ErrInvalidArgument)
+	}
+	return n, nil
+}
+
+// next первое время расписания строго после after по UTC.
+// Если в ближайшие cronSearchYears лет такого времени нет, вернется false.
+func (spec *cronSpec) next(after time.Time) (time.Time, bool) {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if !has(spec.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !spec.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !has(spec.hour, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !has(spec.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func (spec *cronSpec) dayMatch(t time.Time) bool {
+	dom := has(spec.dom, t.Day())
+	dow := has(spec.dow, int(t.Weekday()))
+	switch {
+	case spec.domAny && spec.dowAny:
+		return true
+	case spec.domAny:
+		return dow
+	case spec.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func has(bits uint64, n int) bool {
+	return bits&(1<<uint(n)) != 0
+}
@@ -0,0 +1,45 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCron_next(t *testing.T) {
+	from := time.Date(2026, 1, 31, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		expr   string
+		expect time.Time
+	}{
+		{expr: "*/15 * * * *", expect: time.Date(2026, 1, 31, 10, 45, 0, 0, time.UTC)},
+		{expr: "0 9 1 * *", expect: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{expr: "@daily", expect: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 12 * * 1-5", expect: time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)},
+		// день месяца или день недели
+		{expr: "0 0 15 * 0", expect: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", expect: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		spec, err := parseCron(c.expr)
+		assert.Nil(t, err, c.expr)
+		got, ok := spec.next(from)
+		assert.True(t, ok, c.expr)
+		assert.Equal(t, c.expect, got, c.expr)
+	}
+
+	spec, err := parseCron("0 0 30 2 *")
+	assert.Nil(t, err)
+	_, ok := spec.next(from)
+	assert.False(t, ok)
+}
+
+func TestCron_invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "5/2 * * * *", "a * * * *"} {
+		_, err := parseCron(expr)
+		assert.True(t, errors.Is(err, models.ErrInvalidArgument), expr)
+	}
+}
@@ -0,0 +1,410 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// minInterval переводы по интервалу не чаще раза в минуту.
+	minInterval = time.Minute
+
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = time.Minute
+
+	// principalMethod способ аутентификации клиента, от имени которого выполняется перевод.
+	principalMethod = "schedule"
+)
+
+var (
+	errNonPositiveAmount = fmt.Errorf("amount must be greater than 0: %w", models.ErrInvalidArgument)
+	errSameWallet        = fmt.Errorf("same wallet: %w", models.ErrInvalidArgument)
+	errEmptyPeriod       = fmt.Errorf("either interval or cron is required: %w", models.ErrInvalidArgument)
+	errBothPeriods       = fmt.Errorf("interval and cron are mutually exclusive: %w", models.ErrInvalidArgument)
+	errShortInterval     = fmt.Errorf("interval is shorter than %s: %w", minInterval, models.ErrInvalidArgument)
+	errNoNextRun         = fmt.Errorf("cron never fires: %w", models.ErrInvalidArgument)
+	errScheduleState     = fmt.Errorf("schedule is not in expected state: %w", models.ErrInvalidArgument)
+)
+
+// walletGetter кошельки для проверки получателя.
+type walletGetter interface {
+	ByID(id string) (models.Walleter, error)
+}
+
+// failureNotifier уведомление о переводе, не выполненном после всех повторов.
+type failureNotifier interface {
+	ScheduleFailed(ctx context.Context, schedule models.Schedule, err error)
+}
+
+// principalResolver текущие роли клиента по идентификатору.
+type principalResolver interface {
+	Principal(id string) (*models.Principal, error)
+}
+
+// authorizer право клиента на чужие кошельки и переводы.
+type authorizer interface {
+	Allowed(principal *models.Principal, perm models.Permission) bool
+}
+
+// Scheduler регулярные переводы: хранит поручения и выполняет наступившие переводы через менеджер кошельков.
+type Scheduler struct {
+	store     models.ScheduleStore
+	manWallet models.WalletManager
+	wallets   walletGetter
+	notifier  failureNotifier
+	policy    authorizer
+	// principals источник текущих ролей владельцев поручений
+	principals principalResolver
+	// maxRetries повторов перевода после ошибки, затем перевод считается невыполненным
+	maxRetries int
+	// retryBackoff пауза перед первым повтором, удваивается с каждым повтором
+	retryBackoff time.Duration
+	now          func() time.Time
+}
+
+// Option дополнительная настройка планировщика.
+type Option func(s *Scheduler)
+
+// WithRetry число повторов перевода и пауза перед первым повтором.
+// Непустая пауза заменяет паузу по умолчанию.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(s *Scheduler) {
+		s.maxRetries = maxRetries
+		if backoff > 0 {
+			s.retryBackoff = backoff
+		}
+	}
+}
+
+// WithFailureNotifier уведомлять о переводах, не выполненных после всех повторов.
+func WithFailureNotifier(notifier failureNotifier) Option {
+	return func(s *Scheduler) {
+		s.notifier = notifier
+	}
+}
+
+// WithPolicy проверять право на чужие поручения по политике доступа.
+// Без политики чужие поручения доступны только администратору.
+func WithPolicy(policy authorizer) Option {
+	return func(s *Scheduler) {
+		s.policy = policy
+	}
+}
+
+// WithPrincipals определять роли владельца поручения при каждом переводе, а не при создании.
+// Поручение клиента, которого principals не знает, не создается, а после отзыва доступа отменяется.
+// Клиенты JWT в principals не хранятся: их поручения выполняются с ролями из токена.
+// Без источника ролей перевод выполняется от клиента без ролей.
+func WithPrincipals(principals principalResolver) Option {
+	return func(s *Scheduler) {
+		s.principals = principals
+	}
+}
+
+// NewScheduler конструктор планировщика регулярных переводов.
+// manWallet - менеджер, через который выполняются переводы(с уведомлениями и аудитом).
+// wallets - хранилище кошельков для проверки получателя.
+func NewScheduler(store models.ScheduleStore, manWallet models.WalletManager, wallets walletGetter, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		store:        store,
+		manWallet:    manWallet,
+		wallets:      wallets,
+		maxRetries:   defaultMaxRetries,
+		retryBackoff: defaultRetryBackoff,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Create создаем регулярный перевод с кошелька клиента.
+// Владение кошельком отправителя проверяется через менеджер кошельков.
+func (s *Scheduler) Create(ctx context.Context, inp models.NewSchedule) (models.Schedule, error) {
+	p := models.PrincipalFromContext(ctx)
+	if p == nil {
+		return models.Schedule{}, fmt.Errorf("no principal: %w", models.ErrForbidden)
+	}
+
+	err := validate(inp)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+	if s.principals != nil && p.Method != models.MethodJWT {
+		_, err = s.principals.Principal(p.ID)
+		if err != nil {
+			return models.Schedule{}, err
+		}
+	}
+	_, err = s.manWallet.ByID(ctx, inp.FromID)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+	_, err = s.wallets.ByID(inp.ToID)
+	if err != nil {
+		return models.Schedule{}, fmt.Errorf("cannot get dest wallet by id %s: %w", inp.ToID, err)
+	}
+
+	now := s.now().UTC()
+	schedule := models.Schedule{
+		Owner:     p.ID,
+		FromID:    inp.FromID,
+		ToID:      inp.ToID,
+		Amount:    inp.Amount,
+		Interval:  inp.Interval,
+		Cron:      inp.Cron,
+		StartAt:   inp.StartAt.UTC(),
+		Status:    models.ScheduleStatusActive,
+		CreatedAt: now,
+	}
+	schedule.OwnerMethod = p.Method
+	if p.Method == models.MethodJWT {
+		schedule.OwnerRoles = append([]string(nil), p.Roles...)
+	}
+	if inp.Interval > 0 && inp.StartAt.IsZero() {
+		schedule.StartAt = now.Add(inp.Interval)
+	}
+	schedule.NextRun, err = nextRun(&schedule, now)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return s.store.Create(schedule)
+}
+
+// List поручения клиента, клиент с правом на чужие кошельки видит все поручения.
+func (s *Scheduler) List(ctx context.Context, filter models.ScheduleFilter) ([]models.Schedule, error) {
+	p := models.PrincipalFromContext(ctx)
+	if p == nil {
+		return nil, fmt.Errorf("no principal: %w", models.ErrForbidden)
+	}
+	if !s.anyOwner(p) {
+		filter.Owner = p.ID
+	}
+	return s.store.List(filter)
+}
+
+// Pause приостанавливаем активное поручение.
+func (s *Scheduler) Pause(ctx context.Context, id string) (models.Schedule, error) {
+	return s.update(ctx, id, func(schedule *models.Schedule) error {
+		if schedule.Status != models.ScheduleStatusActive {
+			return fmt.Errorf("schedule %s is %s: %w", id, schedule.Status, errScheduleState)
+		}
+		schedule.Status = models.ScheduleStatusPaused
+		return nil
+	})
+}
+
+// Resume возобновляем приостановленное поручение.
+// Переводы, пропущенные за время паузы, не выполняются.
+func (s *Scheduler) Resume(ctx context.Context, id string) (models.Schedule, error) {
+	return s.update(ctx, id, func(schedule *models.Schedule) error {
+		if schedule.Status != models.ScheduleStatusPaused {
+			return fmt.Errorf("schedule %s is %s: %w", id, schedule.Status, errScheduleState)
+		}
+		next, err := nextRun(schedule, s.now().UTC())
+		if err != nil {
+			return err
+		}
+		schedule.Status = models.ScheduleStatusActive
+		schedule.NextRun = next
+		schedule.Attempt = 0
+		return nil
+	})
+}
+
+// Cancel отменяем поручение, отмененное поручение не возобновляется.
+func (s *Scheduler) Cancel(ctx context.Context, id string) (models.Schedule, error) {
+	return s.update(ctx, id, func(schedule *models.Schedule) error {
+		if schedule.Status == models.ScheduleStatusCancelled {
+			return fmt.Errorf("schedule %s is %s: %w", id, schedule.Status, errScheduleState)
+		}
+		schedule.Status = models.ScheduleStatusCancelled
+		return nil
+	})
+}
+
+// Run выполняем наступившие переводы каждые every, пока не отменен ctx.
+func (s *Scheduler) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunDue(ctx)
+		}
+	}
+}
+
+// RunDue выполняем переводы, время которых наступило.
+// Перевод с ошибкой повторяется с растущей паузой, после всех повторов
+// отправляется уведомление и поручение ждет следующего перевода по расписанию.
+func (s *Scheduler) RunDue(ctx context.Context) {
+	now := s.now().UTC()
+	due, err := s.store.Due(now)
+	if err != nil {
+		log.Errorf("cannot get due schedules: %s", err.Error())
+		return
+	}
+	for _, schedule := range due {
+		s.execute(ctx, schedule, now)
+	}
+}
+
+// execute выполняем перевод от имени владельца поручения с его текущими ролями.
+// Если владелец неизвестен или доступ запрещен, поручение отменяется.
+func (s *Scheduler) execute(ctx context.Context, schedule models.Schedule, now time.Time) {
+	ctx = models.ContextWithRequestMeta(ctx, &models.RequestMeta{ID: "schedule-" + schedule.ID})
+	owner, errTransfer := s.owner(&schedule)
+
+	var result models.OperationResult
+	if errTransfer == nil {
+		ctx = models.ContextWithPrincipal(ctx, owner)
+		result, errTransfer = s.manWallet.TransferBalance(ctx, schedule.FromID, schedule.ToID, schedule.Amount)
+	}
+
+	var failed bool
+	updated, err := s.store.Update(schedule.ID, func(cur *models.Schedule) error {
+		// fn может выполняться несколько раз, failed определяется последним выполнением
+		failed = false
+		cur.LastRun = now
+		if errTransfer == nil {
+			cur.LastOperationID = result.OperationID
+			cur.LastError = ""
+			cur.Attempt = 0
+			advance(cur, now)
+			return nil
+		}
+
+		cur.LastError = errTransfer.Error()
+		if errors.Is(errTransfer, models.ErrForbidden) {
+			failed = true
+			cur.Failures++
+			cur.Status = models.ScheduleStatusCancelled
+			return nil
+		}
+		if retryable(errTransfer) && cur.Attempt < s.maxRetries {
+			cur.NextRun = now.Add(s.retryBackoff << uint(cur.Attempt))
+			cur.Attempt++
+			return nil
+		}
+		failed = true
+		cur.Failures++
+		cur.Attempt = 0
+		advance(cur, now)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("schedule %s: cannot save run result: %s", schedule.ID, err.Error())
+		return
+	}
+
+	if failed && s.notifier != nil {
+		s.notifier.ScheduleFailed(ctx, updated, errTransfer)
+	}
+}
+
+// owner клиент, от имени которого выполняется перевод поручения.
+// Владелец JWT получает роли из токена, которым создано поручение, владелец API ключа - текущие роли из principals.
+func (s *Scheduler) owner(schedule *models.Schedule) (*models.Principal, error) {
+	p := &models.Principal{ID: schedule.Owner, Method: principalMethod}
+	if schedule.OwnerMethod == models.MethodJWT {
+		p.Roles = append([]string(nil), schedule.OwnerRoles...)
+		return p, nil
+	}
+	if s.principals == nil {
+		return p, nil
+	}
+	current, err := s.principals.Principal(schedule.Owner)
+	if err != nil {
+		return nil, err
+	}
+	p.Roles = append([]string(nil), current.Roles...)
+	return p, nil
+}
+
+// advance переводим поручение на следующий перевод по расписанию.
+// Поручение без следующего перевода отменяется.
+func advance(schedule *models.Schedule, now time.Time) {
+	next, err := nextRun(schedule, now)
+	if err != nil {
+		schedule.Status = models.ScheduleStatusCancelled
+		return
+	}
+	schedule.NextRun = next
+}
+
+// update изменяем поручение клиента.
+func (s *Scheduler) update(ctx context.Context, id string, fn func(schedule *models.Schedule) error) (models.Schedule, error) {
+	p := models.PrincipalFromContext(ctx)
+	if p == nil {
+		return models.Schedule{}, fmt.Errorf("no principal: %w", models.ErrForbidden)
+	}
+
+	return s.store.Update(id, func(schedule *models.Schedule) error {
+		if schedule.Owner != p.ID && !s.anyOwner(p) {
+			return fmt.Errorf("schedule %s: %w", id, models.ErrForbidden)
+		}
+		return fn(schedule)
+	})
+}
+
+// anyOwner может ли клиент работать с чужими поручениями.
+func (s *Scheduler) anyOwner(p *models.Principal) bool {
+	if s.policy == nil {
+		return p.HasRole(models.RoleAdmin)
+	}
+	return s.policy.Allowed(p, models.PermWalletAnyOwner)
+}
+
+// nextRun время первого перевода по расписанию строго после now.
+// Переводы по интервалу отсчитываются от StartAt, поэтому не смещаются из-за повторов и пауз.
+func nextRun(schedule *models.Schedule, now time.Time) (time.Time, error) {
+	if schedule.Cron != "" {
+		spec, err := parseCron(schedule.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		next, ok := spec.next(now)
+		if !ok {
+			return time.Time{}, fmt.Errorf("cron %q: %w", schedule.Cron, errNoNextRun)
+		}
+		return next, nil
+	}
+
+	if now.Before(schedule.StartAt) {
+		return schedule.StartAt, nil
+	}
+	periods := now.Sub(schedule.StartAt)/schedule.Interval + 1
+	return schedule.StartAt.Add(periods * schedule.Interval), nil
+}
+
+func validate(inp models.NewSchedule) error {
+	switch {
+	case inp.Amount <= 0:
+		return errNonPositiveAmount
+	case inp.FromID == inp.ToID:
+		return errSameWallet
+	case inp.Interval == 0 && inp.Cron == "":
+		return errEmptyPeriod
+	case inp.Interval != 0 && inp.Cron != "":
+		return errBothPeriods
+	case inp.Cron == "" && inp.Interval < minInterval:
+		return errShortInterval
+	}
+	return nil
+}
+
+// retryable ошибки доступа и неверные параметры не исправятся повтором.
+func retryable(err error) bool {
+	return !errors.Is(err, models.ErrForbidden) && !errors.Is(err, models.ErrInvalidArgument)
+}
@@ -0,0 +1,245 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/access"
+	"github.com/Nizom98/wallet/internal/buisness/wallet"
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOwner = "test_owner"
+
+type fakeNotifier struct {
+	failed []models.Schedule
+}
+
+func (n *fakeNotifier) ScheduleFailed(_ context.Context, schedule models.Schedule, _ error) {
+	n.failed = append(n.failed, schedule)
+}
+
+// fakePrincipals текущие роли клиентов.
+type fakePrincipals map[string][]string
+
+func (p fakePrincipals) Principal(id string) (*models.Principal, error) {
+	roles, ok := p[id]
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+	return &models.Principal{ID: id, Roles: roles}, nil
+}
+
+func ownerCtx() context.Context {
+	return models.ContextWithPrincipal(context.Background(), &models.Principal{ID: testOwner})
+}
+
+func newTestScheduler(repo *repository.WalletRepository, clock *time.Time, opts ...Option) *Scheduler {
+	s := NewScheduler(repository.NewScheduleStore(), wallet.NewManager(repo), repo, opts...)
+	s.now = func() time.Time { return *clock }
+	return s
+}
+
+func TestScheduler_interval(t *testing.T) {
+	repo := repository.NewRepo()
+	from := repo.Create("from", 250, true, testOwner, nil)
+	to := repo.Create("to", 0, true, "shop", nil)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestScheduler(repo, &clock)
+
+	created, err := s.Create(ownerCtx(), models.NewSchedule{FromID: from.ID(), ToID: to.ID(), Amount: 100, Interval: time.Hour})
+	assert.Nil(t, err)
+	assert.Equal(t, clock.Add(time.Hour), created.NextRun)
+
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 0)
+
+	// пропущенные переводы не догоняются
+	clock = clock.Add(2*time.Hour + time.Minute)
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 100)
+
+	got, err := repo.ByID(from.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(150), got.Balance())
+
+	schedules, err := s.List(ownerCtx(), models.ScheduleFilter{})
+	assert.Nil(t, err)
+	assert.Len(t, schedules, 1)
+	assert.Equal(t, time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC), schedules[0].NextRun)
+	assert.NotEmpty(t, schedules[0].LastOperationID)
+}
+
+func TestScheduler_retryAndNotify(t *testing.T) {
+	repo := repository.NewRepo()
+	from := repo.Create("from", 50, true, testOwner, nil)
+	to := repo.Create("to", 0, true, "shop", nil)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	notifier := &fakeNotifier{}
+	s := newTestScheduler(repo, &clock, WithRetry(2, time.Minute), WithFailureNotifier(notifier))
+
+	created, err := s.Create(ownerCtx(), models.NewSchedule{FromID: from.ID(), ToID: to.ID(), Amount: 100, Cron: "0 9 * * *"})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC), created.NextRun)
+
+	clock = created.NextRun
+	s.RunDue(context.Background())
+	got, _ := repo.ByID(from.ID())
+	assert.Equal(t, float64(50), got.Balance())
+
+	clock = clock.Add(time.Minute)
+	s.RunDue(context.Background())
+	clock = clock.Add(2 * time.Minute)
+	s.RunDue(context.Background())
+	assert.Len(t, notifier.failed, 1)
+	assert.Equal(t, 1, notifier.failed[0].Failures)
+	assert.Equal(t, time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC), notifier.failed[0].NextRun)
+
+	// средства поступили до следующего перевода
+	_, err = wallet.NewManager(repo).IncreaseBalanceBy(ownerCtx(), from.ID(), 50)
+	assert.Nil(t, err)
+	clock = time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 100)
+}
+
+func TestScheduler_pauseCancel(t *testing.T) {
+	repo := repository.NewRepo()
+	from := repo.Create("from", 1000, true, testOwner, nil)
+	to := repo.Create("to", 0, true, "shop", nil)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestScheduler(repo, &clock)
+
+	created, err := s.Create(ownerCtx(), models.NewSchedule{FromID: from.ID(), ToID: to.ID(), Amount: 100, Interval: time.Hour})
+	assert.Nil(t, err)
+
+	strangerCtx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "stranger"})
+	_, err = s.Pause(strangerCtx, created.ID)
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+
+	paused, err := s.Pause(ownerCtx(), created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.ScheduleStatusPaused, paused.Status)
+
+	clock = clock.Add(3 * time.Hour)
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 0)
+
+	resumed, err := s.Resume(ownerCtx(), created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, clock.Add(time.Hour), resumed.NextRun)
+
+	_, err = s.Cancel(ownerCtx(), created.ID)
+	assert.Nil(t, err)
+	_, err = s.Resume(ownerCtx(), created.ID)
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+
+	clock = clock.Add(time.Hour)
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 0)
+}
+
+func TestScheduler_createValidation(t *testing.T) {
+	repo := repository.NewRepo()
+	from := repo.Create("from", 1000, true, testOwner, nil)
+	foreign := repo.Create("foreign", 1000, true, "shop", nil)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestScheduler(repo, &clock)
+
+	_, err := s.Create(ownerCtx(), models.NewSchedule{FromID: from.ID(), ToID: foreign.ID(), Amount: 100, Interval: time.Second})
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+
+	_, err = s.Create(ownerCtx(), models.NewSchedule{FromID: from.ID(), ToID: foreign.ID(), Amount: 100, Interval: time.Hour, Cron: "@daily"})
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+
+	_, err = s.Create(ownerCtx(), models.NewSchedule{FromID: foreign.ID(), ToID: from.ID(), Amount: 100, Interval: time.Hour})
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+}
+
+func TestScheduler_currentRoles(t *testing.T) {
+	repo := repository.NewRepo()
+	from := repo.Create("from", 1000, true, testOwner, nil)
+	to := repo.Create("to", 0, true, "shop", nil)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := access.NewPolicy(map[string][]models.Permission{
+		"customer": {models.PermWalletRead, models.PermWalletTransfer},
+		"reader":   {models.PermWalletRead},
+	})
+	principals := fakePrincipals{testOwner: {"customer"}}
+	notifier := &fakeNotifier{}
+	manWallet := wallet.NewManager(repo, wallet.WithPolicy(policy))
+	s := NewScheduler(repository.NewScheduleStore(), manWallet, repo,
+		WithPolicy(policy), WithPrincipals(principals), WithFailureNotifier(notifier))
+	s.now = func() time.Time { return clock }
+
+	// клиент без ключа поручение не создает
+	strangerCtx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "stranger", Roles: []string{"customer"}})
+	_, err := s.Create(strangerCtx, models.NewSchedule{FromID: from.ID(), ToID: to.ID(), Amount: 100, Interval: time.Hour})
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+
+	customerCtx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: testOwner, Roles: []string{"customer"}})
+	created, err := s.Create(customerCtx, models.NewSchedule{FromID: from.ID(), ToID: to.ID(), Amount: 100, Interval: time.Hour})
+	assert.Nil(t, err)
+	clock = created.NextRun
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 100)
+
+	// право на переводы отозвано после создания поручения
+	principals[testOwner] = []string{"reader"}
+	clock = clock.Add(time.Hour)
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 100)
+	assert.Len(t, notifier.failed, 1)
+	assert.Equal(t, models.ScheduleStatusCancelled, notifier.failed[0].Status)
+
+	principals[testOwner] = []string{"customer"}
+	clock = clock.Add(time.Hour)
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 100)
+}
+
+func TestScheduler_jwtOwner(t *testing.T) {
+	repo := repository.NewRepo()
+	from := repo.Create("from", 1000, true, testOwner, nil)
+	to := repo.Create("to", 0, true, "shop", nil)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := access.NewPolicy(map[string][]models.Permission{
+		"customer": {models.PermWalletRead, models.PermWalletTransfer},
+		"reader":   {models.PermWalletRead},
+	})
+	manWallet := wallet.NewManager(repo, wallet.WithPolicy(policy))
+	s := NewScheduler(repository.NewScheduleStore(), manWallet, repo,
+		WithPolicy(policy), WithPrincipals(fakePrincipals{}))
+	s.now = func() time.Time { return clock }
+
+	// клиента JWT нет среди ключей, его роли берутся из токена
+	jwtCtx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: testOwner, Roles: []string{"customer"}, Method: models.MethodJWT})
+	created, err := s.Create(jwtCtx, models.NewSchedule{FromID: from.ID(), ToID: to.ID(), Amount: 100, Interval: time.Hour})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"customer"}, created.OwnerRoles)
+	clock = created.NextRun
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 100)
+
+	// роли из токена проверяются по политике при каждом переводе
+	readerCtx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: testOwner, Roles: []string{"reader"}, Method: models.MethodJWT})
+	readOnly, err := s.Create(readerCtx, models.NewSchedule{FromID: from.ID(), ToID: to.ID(), Amount: 50, Interval: time.Hour})
+	assert.Nil(t, err)
+	clock = clock.Add(time.Hour)
+	s.RunDue(context.Background())
+	assertBalance(t, repo, to.ID(), 200)
+	got, err := s.store.ByID(readOnly.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.ScheduleStatusCancelled, got.Status)
+}
+
+func assertBalance(t *testing.T, repo *repository.WalletRepository, id string, expect float64) {
+	t.Helper()
+	got, err := repo.ByID(id)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, got.Balance())
+}
@@ -0,0 +1,155 @@
+package statement
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// Format формат файла выписки.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	// FormatText текст для печати с колонками фиксированной ширины.
+	FormatText Format = "text"
+)
+
+const (
+	rowOpening = "opening_balance"
+	rowClosing = "closing_balance"
+	timeLayout = "2006-01-02 15:04:05"
+)
+
+var csvHeader = []string{"time", "operation_id", "type", "counterparty", "fee", "reverses", "amount", "balance"}
+
+// ParseFormat формат по названию, пустое название - json.
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatCSV, FormatText:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("unknown statement format %q: %w", name, models.ErrInvalidArgument)
+	}
+}
+
+// ContentType http тип содержимого файла выписки.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Write пишем выписку в формате f.
+func Write(w io.Writer, st *models.Statement, f Format) error {
+	switch f {
+	case FormatCSV:
+		return WriteCSV(w, st)
+	case FormatText:
+		return WriteText(w, st)
+	default:
+		return WriteJSON(w, st)
+	}
+}
+
+// WriteJSON выписка одним json документом.
+func WriteJSON(w io.Writer, st *models.Statement) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(st)
+}
+
+// WriteCSV выписка в csv: заголовок, строка баланса на начало периода,
+// проводки и строка баланса на конец периода.
+func WriteCSV(w io.Writer, st *models.Statement) error {
+	out := csv.NewWriter(w)
+	rows := [][]string{
+		csvHeader,
+		{formatTime(st.From), "", rowOpening, "", "", "", "", formatAmount(st.OpeningBalance)},
+	}
+	for _, line := range st.Lines {
+		rows = append(rows, []string{
+			formatTime(line.Time),
+			line.OperationID,
+			string(line.Type),
+			line.Counterparty,
+			strconv.FormatBool(line.Fee),
+			line.Reverses,
+			formatAmount(line.Amount),
+			formatAmount(line.Balance),
+		})
+	}
+	rows = append(rows, []string{formatTime(st.To), "", rowClosing, "", "", "", "", formatAmount(st.ClosingBalance)})
+
+	err := out.WriteAll(rows)
+	if err != nil {
+		return fmt.Errorf("cannot write csv statement: %w", err)
+	}
+	return nil
+}
+
+// WriteText выписка для печати.
+func WriteText(w io.Writer, st *models.Statement) error {
+	fmt.Fprintf(w, "Statement of wallet %s (%s)\n", st.WalletName, st.WalletID)
+	fmt.Fprintf(w, "Owner:    %s\n", st.Owner)
+	if st.Currency != "" {
+		fmt.Fprintf(w, "Currency: %s\n", st.Currency)
+	}
+	fmt.Fprintf(w, "Period:   %s - %s UTC\n\n", formatTime(st.From), formatTime(st.To))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Time\tOperation\tType\tCounterparty\tAmount\tBalance\t\n")
+	fmt.Fprintf(tw, "%s\t\t%s\t\t\t%s\t\n", formatTime(st.From), "Opening balance", formatAmount(st.OpeningBalance))
+	for _, line := range st.Lines {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			formatTime(line.Time),
+			line.OperationID,
+			lineType(line),
+			line.Counterparty,
+			formatAmount(line.Amount),
+			formatAmount(line.Balance),
+		)
+	}
+	fmt.Fprintf(tw, "%s\t\t%s\t\t\t%s\t\n", formatTime(st.To), "Closing balance", formatAmount(st.ClosingBalance))
+	err := tw.Flush()
+	if err != nil {
+		return fmt.Errorf("cannot write text statement: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "\nTotal credit: %s\nTotal debit:  %s\n", formatAmount(st.TotalCredit), formatAmount(st.TotalDebit))
+	return err
+}
+
+// lineType тип проводки для печати с пометкой комиссии и сторнирования.
+func lineType(line models.StatementLine) string {
+	switch {
+	case line.Fee:
+		return string(line.Type) + " fee"
+	case line.Reverses != "":
+		return string(line.Type) + " of " + line.Reverses
+	default:
+		return string(line.Type)
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
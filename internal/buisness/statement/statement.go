@@ -0,0 +1,129 @@
+package statement
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// maxPeriod выписка не длиннее года.
+const maxPeriod = 366 * 24 * time.Hour
+
+var (
+	errEmptyPeriod = fmt.Errorf("statement period is empty: %w", models.ErrInvalidArgument)
+	errLongPeriod  = fmt.Errorf("statement period is longer than %s: %w", maxPeriod, models.ErrInvalidArgument)
+)
+
+// ledgerReader проводки журнала операций.
+type ledgerReader interface {
+	Ledger(filter models.LedgerFilter) ([]models.LedgerEntry, error)
+}
+
+// Generator формирование выписок по кошелькам.
+type Generator struct {
+	manWallet models.WalletManager
+	ledger    ledgerReader
+	// amounts валюта и число знаков для округления балансов
+	amounts models.AmountPolicy
+}
+
+// Option дополнительная настройка выписок.
+type Option func(g *Generator)
+
+// WithAmountPolicy указывать в выписке валюту и округлять балансы до знаков валюты.
+func WithAmountPolicy(policy models.AmountPolicy) Option {
+	return func(g *Generator) {
+		g.amounts = policy
+	}
+}
+
+// NewGenerator конструктор выписок.
+// manWallet - проверка доступа к кошельку и баланс на начало периода, ledger - проводки периода.
+func NewGenerator(manWallet models.WalletManager, ledger ledgerReader, opts ...Option) *Generator {
+	g := &Generator{
+		manWallet: manWallet,
+		ledger:    ledger,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Generate выписка по кошельку за период [from, to): баланс на начало периода,
+// каждая проводка с контрагентом и балансом после нее, баланс на конец периода.
+func (g *Generator) Generate(ctx context.Context, walletID string, from, to time.Time) (*models.Statement, error) {
+	from, to = from.UTC(), to.UTC()
+	if !from.Before(to) {
+		return nil, errEmptyPeriod
+	}
+	if to.Sub(from) > maxPeriod {
+		return nil, errLongPeriod
+	}
+
+	wallet, err := g.manWallet.ByID(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &models.Statement{
+		WalletID:   wallet.ID(),
+		WalletName: wallet.Name(),
+		Owner:      wallet.Owner(),
+		Currency:   g.amounts.Currency,
+		From:       from,
+		To:         to,
+		Lines:      make([]models.StatementLine, 0),
+	}
+	// кошелек, созданный в периоде, начинает его с нулевым балансом
+	if !wallet.CreatedAt().After(from) {
+		opening, err := g.manWallet.BalanceAt(ctx, walletID, from)
+		if err != nil {
+			return nil, err
+		}
+		st.OpeningBalance = opening.Balance
+	}
+
+	entries, err := g.ledger.Ledger(models.LedgerFilter{WalletID: walletID, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+
+	balance := st.OpeningBalance
+	for _, entry := range entries {
+		balance = g.round(balance + entry.Amount)
+		if entry.Amount > 0 {
+			st.TotalCredit += entry.Amount
+		} else {
+			st.TotalDebit -= entry.Amount
+		}
+		st.Lines = append(st.Lines, models.StatementLine{
+			Time:         entry.Time,
+			OperationID:  entry.OperationID,
+			Type:         entry.Type,
+			Counterparty: entry.Counterparty,
+			Fee:          entry.Fee,
+			Reverses:     entry.Reverses,
+			Amount:       entry.Amount,
+			Balance:      balance,
+		})
+	}
+	st.ClosingBalance = balance
+	st.TotalCredit = g.round(st.TotalCredit)
+	st.TotalDebit = g.round(st.TotalDebit)
+
+	return st, nil
+}
+
+// round округляем до знаков валюты, без правила валюты сумма не меняется.
+func (g *Generator) round(amount float64) float64 {
+	decimals := g.amounts.Decimals
+	if decimals == nil {
+		return amount
+	}
+	scale := math.Pow10(*decimals)
+	return math.Round(amount*scale) / scale
+}
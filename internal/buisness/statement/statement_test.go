@@ -0,0 +1,89 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/buisness/wallet"
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	repo := repository.NewRepo()
+	ctx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "owner"})
+	man := wallet.NewManager(repo)
+	a := repo.Create("a", 100, true, "owner", nil)
+	b := repo.Create("b", 0, true, "shop", nil)
+
+	_, err := man.IncreaseBalanceBy(ctx, a.ID(), 20)
+	assert.Nil(t, err)
+	time.Sleep(time.Millisecond)
+	from := time.Now()
+	time.Sleep(time.Millisecond)
+	transfer, err := man.TransferBalance(ctx, a.ID(), b.ID(), 50)
+	assert.Nil(t, err)
+	_, err = man.DecreaseBalanceBy(ctx, a.ID(), 10)
+	assert.Nil(t, err)
+	time.Sleep(time.Millisecond)
+	to := time.Now()
+	time.Sleep(time.Millisecond)
+	_, err = man.IncreaseBalanceBy(ctx, a.ID(), 1000)
+	assert.Nil(t, err)
+
+	gen := NewGenerator(man, repo)
+	st, err := gen.Generate(ctx, a.ID(), from, to)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(120), st.OpeningBalance)
+	assert.Equal(t, float64(60), st.ClosingBalance)
+	assert.Equal(t, float64(60), st.TotalDebit)
+	assert.Len(t, st.Lines, 2)
+	assert.Equal(t, transfer.OperationID, st.Lines[0].OperationID)
+	assert.Equal(t, b.ID(), st.Lines[0].Counterparty)
+	assert.Equal(t, float64(70), st.Lines[0].Balance)
+
+	var buf bytes.Buffer
+	assert.Nil(t, Write(&buf, st, FormatCSV))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 5)
+	assert.Equal(t, []string{rowClosing, "60"}, []string{rows[4][2], rows[4][7]})
+
+	buf.Reset()
+	assert.Nil(t, Write(&buf, st, FormatText))
+	assert.True(t, strings.Contains(buf.String(), "Opening balance"))
+	assert.True(t, strings.Contains(buf.String(), transfer.OperationID))
+
+	_, err = gen.Generate(ctx, b.ID(), from, to)
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+	_, err = gen.Generate(ctx, a.ID(), to, from)
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+}
+
+func TestGenerate_walletCreatedInPeriod(t *testing.T) {
+	repo := repository.NewRepo()
+	ctx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "owner"})
+	from := time.Now().Add(-time.Hour)
+	a := repo.Create("a", 100, true, "owner", nil)
+
+	st, err := NewGenerator(wallet.NewManager(repo), repo).Generate(ctx, a.ID(), from, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), st.OpeningBalance)
+	assert.Equal(t, float64(100), st.ClosingBalance)
+	assert.Equal(t, models.OperationOpening, st.Lines[0].Type)
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("")
+	assert.Nil(t, err)
+	assert.Equal(t, FormatJSON, f)
+
+	_, err = ParseFormat("pdf")
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+}
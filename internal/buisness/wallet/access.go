@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// authorizer политика доступа на основе ролей.
+type authorizer interface {
+	Allowed(principal *models.Principal, perm models.Permission) bool
+	Check(ctx context.Context, perm models.Permission) error
+}
+
+// principal клиент, от имени которого выполняется операция.
+// Без клиента операции с кошельками запрещены.
+func principal(ctx context.Context) (*models.Principal, error) {
+	p := models.PrincipalFromContext(ctx)
+	if p == nil {
+		return nil, fmt.Errorf("no principal: %w", models.ErrForbidden)
+	}
+	return p, nil
+}
+
+// authorize проверяем право клиента на операцию.
+// Если политика не задана, ограничения по ролям не применяются.
+func (man *manager) authorize(ctx context.Context, perm models.Permission) error {
+	if man.policy == nil {
+		return nil
+	}
+	return man.policy.Check(ctx, perm)
+}
+
+// authorizeAdmin проверяем право клиента на административную операцию.
+// Если политика не задана, операция доступна только администратору.
+func (man *manager) authorizeAdmin(ctx context.Context, perm models.Permission) error {
+	if man.policy != nil {
+		return man.policy.Check(ctx, perm)
+	}
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.HasRole(models.RoleAdmin) {
+		return fmt.Errorf("%s requires admin role: %w", perm, models.ErrForbidden)
+	}
+	return nil
+}
+
+// anyOwner может ли клиент работать с чужими кошельками.
+// Без политики такое право есть только у администратора.
+func (man *manager) anyOwner(p *models.Principal) bool {
+	if man.policy == nil {
+		return p.HasRole(models.RoleAdmin)
+	}
+	return man.policy.Allowed(p, models.PermWalletAnyOwner)
+}
+
+// checkOwner клиент может работать только со своими кошельками.
+func (man *manager) checkOwner(ctx context.Context, wallet models.Walleter) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if wallet.Owner() == p.ID || man.anyOwner(p) {
+		return nil
+	}
+	return fmt.Errorf("wallet %s: %w", wallet.ID(), models.ErrForbidden)
+}
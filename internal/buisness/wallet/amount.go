@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// precisionEpsilon допустимая погрешность float64 при проверке знаков после запятой.
+const precisionEpsilon = 1e-6
+
+var errNonPositiveAmount = fmt.Errorf("amount must be greater than 0: %w", models.ErrInvalidArgument)
+
+// WithAmountPolicy проверять суммы операций и балансы кошельков по правилам валюты сервиса.
+func WithAmountPolicy(policy models.AmountPolicy) Option {
+	return func(man *manager) {
+		man.amounts = policy
+	}
+}
+
+// amountRules правила валюты кошельков.
+func (man *manager) amountRules() models.AmountRules {
+	return man.amounts.AmountRules
+}
+
+// validateAmount сумма операции положительна и соответствует правилам валюты.
+// Применяется одинаково к пополнению, снятию, переводу и блокировке средств.
+func (man *manager) validateAmount(amount float64) error {
+	if amount <= 0 {
+		return errNonPositiveAmount
+	}
+
+	rules := man.amountRules()
+	currency := man.amounts.Currency
+	if rules.MinAmount > 0 && amount < rules.MinAmount {
+		return fmt.Errorf("amount %g is less than minimum %g %s: %w", amount, rules.MinAmount, currency, models.ErrInvalidArgument)
+	}
+	if rules.MaxAmount > 0 && amount > rules.MaxAmount {
+		return fmt.Errorf("amount %g is greater than maximum %g %s: %w", amount, rules.MaxAmount, currency, models.ErrInvalidArgument)
+	}
+	if rules.Decimals != nil {
+		scaled := amount * math.Pow10(*rules.Decimals)
+		if math.Abs(scaled-math.Round(scaled)) > precisionEpsilon {
+			return fmt.Errorf("amount %g has more than %d decimal places for %s: %w", amount, *rules.Decimals, currency, models.ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+// roundAmount округляем сумму до допустимого числа знаков валюты.
+func (man *manager) roundAmount(amount float64) float64 {
+	rules := man.amountRules()
+	if rules.Decimals == nil {
+		return amount
+	}
+	scale := math.Pow10(*rules.Decimals)
+	return math.Round(amount*scale) / scale
+}
+
+// checkBalanceCeiling баланс кошелька после зачисления не превышает максимальный баланс валюты.
+func (man *manager) checkBalanceCeiling(walletID string, balance float64) error {
+	rules := man.amountRules()
+	if rules.MaxBalance > 0 && balance > rules.MaxBalance {
+		return &models.LimitError{
+			Scope:    man.amounts.Currency,
+			WalletID: walletID,
+			Rule:     "max_balance",
+			Limit:    rules.MaxBalance,
+			Value:    balance,
+		}
+	}
+	return nil
+}
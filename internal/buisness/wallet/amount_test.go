@@ -0,0 +1,44 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func testAmountPolicy() models.AmountPolicy {
+	return models.AmountPolicy{
+		Currency:    "USD",
+		AmountRules: models.AmountRules{MinAmount: 0.01, MaxAmount: 1000, Decimals: utils.Ptr[int](2), MaxBalance: 1500},
+	}
+}
+
+func TestValidateAmount(t *testing.T) {
+	man := NewManager(nil, WithAmountPolicy(testAmountPolicy()))
+
+	assert.Nil(t, man.validateAmount(0.1+0.2))
+	assert.Nil(t, man.validateAmount(19.99))
+	assert.True(t, errors.Is(man.validateAmount(0), errNonPositiveAmount))
+	assert.True(t, errors.Is(man.validateAmount(0.001), models.ErrInvalidArgument))
+	assert.True(t, errors.Is(man.validateAmount(1000.01), models.ErrInvalidArgument))
+	assert.True(t, errors.Is(man.validateAmount(1.005), models.ErrInvalidArgument))
+}
+
+func TestTransferBalance_balanceCeiling(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo, WithAmountPolicy(testAmountPolicy()))
+	from := repo.Create("from", 1000, true, testOwner, nil)
+	to := repo.Create("to", 1000, true, "other", nil)
+
+	_, err := man.TransferBalance(ownerCtx(), from.ID(), to.ID(), 600)
+	var limitErr *models.LimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "max_balance", limitErr.Rule)
+
+	assertBalance(t, repo, from.ID(), 1000)
+	assertBalance(t, repo, to.ID(), 1000)
+}
@@ -0,0 +1,132 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxBatchLegs ограничение числа переводов в одном пакете.
+const maxBatchLegs = 1000
+
+var (
+	errEmptyBatch    = errors.New("empty batch")
+	errBatchTooLarge = fmt.Errorf("batch cannot contain more than %d transfers", maxBatchLegs)
+)
+
+// TransferBatch пакетный перевод(один ко многим или многие ко многим).
+// Все переводы проверяются заранее, средств каждого кошелька-источника должно хватать
+// на сумму всех его переводов вместе с комиссиями. Переводы применяются в одной транзакции: либо все, либо ни одного.
+func (man *manager) TransferBatch(ctx context.Context, legs []models.TransferLeg) (_ models.BatchResult, err error) {
+	ctx, span := startSpan(ctx, "wallet.TransferBatch", attribute.Int("wallet.legs", len(legs)))
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletTransfer)
+	if err != nil {
+		return models.BatchResult{}, err
+	}
+
+	if len(legs) == 0 {
+		return models.BatchResult{}, errEmptyBatch
+	}
+	if len(legs) > maxBatchLegs {
+		return models.BatchResult{}, errBatchTooLarge
+	}
+
+	// debits сумма списаний с комиссиями по каждому кошельку-источнику
+	debits := make(map[string]float64)
+	// counts число списаний по каждому кошельку-источнику
+	counts := make(map[string]int)
+	// fees комиссия каждого перевода
+	fees := make([]float64, len(legs))
+	result := models.BatchResult{Fees: fees}
+	ids := make([]string, 0, len(legs)*2+1)
+	for i, leg := range legs {
+		if leg.FromID == leg.ToID {
+			return models.BatchResult{}, fmt.Errorf("transfer %d: %w", i, errSameWallet)
+		}
+		err = man.validateAmount(leg.Amount)
+		if err != nil {
+			return models.BatchResult{}, fmt.Errorf("transfer %d: %w", i, err)
+		}
+		err = man.checkAmount(leg.FromID, leg.Amount)
+		if err != nil {
+			return models.BatchResult{}, fmt.Errorf("transfer %d: %w", i, err)
+		}
+		fee, feeWalletID := man.fee(models.OperationTransfer, leg.FromID, leg.Amount)
+		if fee > 0 {
+			fees[i] = fee
+			ids = append(ids, feeWalletID)
+		}
+		result.Amount += leg.Amount
+		result.Fee += fee
+		debits[leg.FromID] += leg.Amount + fee
+		counts[leg.FromID]++
+		ids = append(ids, leg.FromID, leg.ToID)
+	}
+
+	errTx := man.repo.Transaction(ctx, ids, func(repo models.WalletRepository) error {
+		for fromID, total := range debits {
+			fromWallet, err := repo.ByID(fromID)
+			if err != nil {
+				return fmt.Errorf("cannot get source wallet by id %s: %w", fromID, err)
+			}
+			err = man.checkOwner(ctx, fromWallet)
+			if err != nil {
+				return err
+			}
+			if fromWallet.Available() < total {
+				return fmt.Errorf("wallet %s: %w", fromWallet.ID(), errNotEnoughBalance)
+			}
+			err = man.checkOutgoing(repo, fromID, total, counts[fromID])
+			if err != nil {
+				return err
+			}
+		}
+
+		entries := make([]models.LedgerEntry, 0, len(legs)*2)
+		for i, leg := range legs {
+			fromWallet, err := repo.ByID(leg.FromID)
+			if err != nil {
+				return fmt.Errorf("transfer %d: cannot get source wallet by id %s: %w", i, leg.FromID, err)
+			}
+			toWallet, err := repo.ByID(leg.ToID)
+			if err != nil {
+				return fmt.Errorf("transfer %d: cannot get dest wallet by id %s: %w", i, leg.ToID, err)
+			}
+
+			err = repo.UpdateByID(leg.FromID, models.WalletUpdate{Balance: utils.Ptr[float64](fromWallet.Balance() - leg.Amount)})
+			if err != nil {
+				return fmt.Errorf("transfer %d: cannot update source wallet: %w", i, err)
+			}
+			err = man.checkBalanceCeiling(leg.ToID, toWallet.Balance()+leg.Amount)
+			if err != nil {
+				return fmt.Errorf("transfer %d: %w", i, err)
+			}
+			err = repo.UpdateByID(leg.ToID, models.WalletUpdate{Balance: utils.Ptr[float64](toWallet.Balance() + leg.Amount)})
+			if err != nil {
+				return fmt.Errorf("transfer %d: cannot update dest wallet: %w", i, err)
+			}
+
+			feeEntries, err := man.chargeFee(repo, leg.FromID, man.feeCollector(leg.FromID), fees[i])
+			if err != nil {
+				return fmt.Errorf("transfer %d: %w", i, err)
+			}
+			entries = append(entries, transferEntries(leg.FromID, leg.ToID, leg.Amount)...)
+			entries = append(entries, feeEntries...)
+		}
+
+		opID, err := repo.RecordOperation(models.OperationBatch, entries)
+		result.OperationID = opID
+		return err
+	})
+	if errTx != nil {
+		return models.BatchResult{}, errTx
+	}
+
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferBatch_applied(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	payroll := repo.Create("payroll", 300, true, testOwner, nil)
+	alice := repo.Create("alice", 0, true, "alice", nil)
+	bob := repo.Create("bob", 0, true, "bob", nil)
+
+	_, err := man.TransferBatch(ownerCtx(), []models.TransferLeg{
+		{FromID: payroll.ID(), ToID: alice.ID(), Amount: 100},
+		{FromID: payroll.ID(), ToID: bob.ID(), Amount: 200},
+	})
+	assert.Nil(t, err)
+
+	assertBalance(t, repo, payroll.ID(), 0)
+	assertBalance(t, repo, alice.ID(), 100)
+	assertBalance(t, repo, bob.ID(), 200)
+}
+
+func TestTransferBatch_notEnoughForTotal(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	payroll := repo.Create("payroll", 250, true, testOwner, nil)
+	alice := repo.Create("alice", 0, true, "alice", nil)
+	bob := repo.Create("bob", 0, true, "bob", nil)
+
+	_, err := man.TransferBatch(ownerCtx(), []models.TransferLeg{
+		{FromID: payroll.ID(), ToID: alice.ID(), Amount: 100},
+		{FromID: payroll.ID(), ToID: bob.ID(), Amount: 200},
+	})
+	assert.True(t, errors.Is(err, errNotEnoughBalance))
+
+	assertBalance(t, repo, payroll.ID(), 250)
+	assertBalance(t, repo, alice.ID(), 0)
+}
+
+func TestTransferBatch_rollbackOnFailedLeg(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	payroll := repo.Create("payroll", 300, true, testOwner, nil)
+	alice := repo.Create("alice", 0, true, "alice", nil)
+
+	_, err := man.TransferBatch(ownerCtx(), []models.TransferLeg{
+		{FromID: payroll.ID(), ToID: alice.ID(), Amount: 100},
+		{FromID: payroll.ID(), ToID: "unknown", Amount: 100},
+	})
+	assert.NotNil(t, err)
+
+	assertBalance(t, repo, payroll.ID(), 300)
+	assertBalance(t, repo, alice.ID(), 0)
+}
+
+func TestTransferBatch_invalidLeg(t *testing.T) {
+	man := NewManager(nil)
+
+	_, err := man.TransferBatch(ownerCtx(), []models.TransferLeg{
+		{FromID: "a", ToID: "b", Amount: 10},
+		{FromID: "a", ToID: "a", Amount: 10},
+	})
+	assert.True(t, errors.Is(err, errSameWallet))
+
+	_, err = man.TransferBatch(ownerCtx(), nil)
+	assert.True(t, errors.Is(err, errEmptyBatch))
+}
+
+func assertBalance(t *testing.T, repo models.WalletRepository, id string, expect float64) {
+	t.Helper()
+	wallet, err := repo.ByID(id)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, wallet.Balance())
+}
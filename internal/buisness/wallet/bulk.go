@@ -0,0 +1,93 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// maxBulkWallets ограничение числа кошельков в одном пакете.
+	maxBulkWallets = 10000
+	// maxMetadataKeys ограничение числа меток кошелька.
+	maxMetadataKeys = 16
+	// maxMetadataValue ограничение длины значения метки.
+	maxMetadataValue = 256
+)
+
+var (
+	errEmptyBulk       = fmt.Errorf("empty wallet list: %w", models.ErrInvalidArgument)
+	errBulkTooLarge    = fmt.Errorf("cannot create more than %d wallets at once: %w", maxBulkWallets, models.ErrInvalidArgument)
+	errBulkInvalid     = fmt.Errorf("some wallets are invalid, nothing created: %w", models.ErrInvalidArgument)
+	errInvalidMetadata = errors.New("invalid metadata")
+)
+
+// CreateBulk создаем пакет кошельков текущему клиенту.
+// Сначала проверяются все элементы: если хотя бы один некорректен, ничего не создается,
+// а ошибки по элементам возвращаются в результатах вместе с errBulkInvalid.
+// Корректный пакет создается в одной транзакции.
+func (man *manager) CreateBulk(ctx context.Context, items []models.NewWallet) (_ []models.BulkCreateResult, err error) {
+	ctx, span := startSpan(ctx, "wallet.CreateBulk", attribute.Int("wallet.count", len(items)))
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletCreate)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, errEmptyBulk
+	}
+	if len(items) > maxBulkWallets {
+		return nil, errBulkTooLarge
+	}
+
+	results := make([]models.BulkCreateResult, len(items))
+	invalid := false
+	for i, item := range items {
+		results[i].Err = validateNewWallet(item)
+		if results[i].Err != nil {
+			invalid = true
+		}
+	}
+	if invalid {
+		return results, errBulkInvalid
+	}
+
+	err = man.repo.Transaction(ctx, nil, func(repo models.WalletRepository) error {
+		for i, item := range items {
+			results[i].Wallet = repo.Create(item.Name, defaultBalance, defaultStatus, owner.ID, item.Metadata)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func validateNewWallet(item models.NewWallet) error {
+	if item.Name == "" {
+		return errEmptyName
+	}
+	if len(item.Metadata) > maxMetadataKeys {
+		return fmt.Errorf("more than %d keys: %w", maxMetadataKeys, errInvalidMetadata)
+	}
+	for k, v := range item.Metadata {
+		if k == "" {
+			return fmt.Errorf("empty key: %w", errInvalidMetadata)
+		}
+		if len(v) > maxMetadataValue {
+			return fmt.Errorf("value of %q longer than %d: %w", k, maxMetadataValue, errInvalidMetadata)
+		}
+	}
+	return nil
+}
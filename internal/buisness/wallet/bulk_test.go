@@ -0,0 +1,43 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBulk_created(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+
+	results, err := man.CreateBulk(ownerCtx(), []models.NewWallet{
+		{Name: "first"},
+		{Name: "second", Metadata: map[string]string{"team": "billing"}},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "first", results[0].Wallet.Name())
+	assert.Equal(t, testOwner, results[0].Wallet.Owner())
+	assert.Equal(t, map[string]string{"team": "billing"}, results[1].Wallet.Metadata())
+	assert.Len(t, repo.All(), 2)
+}
+
+func TestCreateBulk_invalidItem(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+
+	results, err := man.CreateBulk(ownerCtx(), []models.NewWallet{
+		{Name: "first"},
+		{Name: ""},
+		{Name: "third", Metadata: map[string]string{"": "empty key"}},
+	})
+	assert.True(t, errors.Is(err, errBulkInvalid))
+	assert.Len(t, results, 3)
+	assert.Nil(t, results[0].Err)
+	assert.True(t, errors.Is(results[1].Err, errEmptyName))
+	assert.True(t, errors.Is(results[2].Err, errInvalidMetadata))
+	assert.Empty(t, repo.All())
+}
@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	errNegativeCreditLimit = fmt.Errorf("credit limit cannot be negative: %w", models.ErrInvalidArgument)
+	errCreditLimitInUse    = errors.New("wallet uses more credit than the new limit")
+)
+
+// SetCreditLimit меняем овердрафт кошелька, операция только для администраторов.
+// Лимит не может быть меньше уже использованного кредита.
+// version - ожидаемая версия кошелька(nil - без проверки).
+func (man *manager) SetCreditLimit(ctx context.Context, id string, limit float64, version *uint64) (err error) {
+	ctx, span := startSpan(ctx, "wallet.SetCreditLimit",
+		attribute.String("wallet.id", id),
+		attribute.Float64("wallet.credit_limit", limit),
+	)
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorizeAdmin(ctx, models.PermWalletCredit)
+	if err != nil {
+		return err
+	}
+
+	if limit < 0 {
+		return errNegativeCreditLimit
+	}
+
+	errTx := man.repo.Transaction(ctx, []string{id}, func(repo models.WalletRepository) error {
+		wallet, err := repo.ByID(id)
+		if err != nil {
+			return fmt.Errorf("cannot get wallet by id %s: %w", id, err)
+		}
+		if wallet.Available()-wallet.CreditLimit()+limit < 0 {
+			return fmt.Errorf("wallet %s: %w", id, errCreditLimitInUse)
+		}
+
+		err = repo.UpdateByID(id, models.WalletUpdate{CreditLimit: utils.Ptr[float64](limit), ExpectedVersion: version})
+		if err != nil {
+			return fmt.Errorf("cannot update wallet: %w", err)
+		}
+		return nil
+	})
+
+	return errTx
+}
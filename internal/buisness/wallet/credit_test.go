@@ -0,0 +1,37 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func adminCtx() context.Context {
+	return models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "admin", Roles: []string{models.RoleAdmin}})
+}
+
+func TestSetCreditLimit_overdraft(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	business := repo.Create("business", 100, true, testOwner, nil)
+
+	err := man.SetCreditLimit(ownerCtx(), business.ID(), 500, nil)
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+
+	err = man.SetCreditLimit(adminCtx(), business.ID(), 500, nil)
+	assert.Nil(t, err)
+
+	_, err = man.DecreaseBalanceBy(ownerCtx(), business.ID(), 400)
+	assert.Nil(t, err)
+	assertBalance(t, repo, business.ID(), -300)
+
+	_, err = man.DecreaseBalanceBy(ownerCtx(), business.ID(), 300)
+	assert.True(t, errors.Is(err, errNotEnoughBalance))
+
+	err = man.SetCreditLimit(adminCtx(), business.ID(), 200, nil)
+	assert.True(t, errors.Is(err, errCreditLimitInUse))
+}
@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+)
+
+// feeCalculator расчет комиссий операций.
+type feeCalculator interface {
+	Fee(op models.OperationType, amount float64) float64
+	Collector() string
+}
+
+// WithFees списывать комиссии операций и зачислять их на кошелек сбора комиссий.
+func WithFees(fees feeCalculator) Option {
+	return func(man *manager) {
+		man.fees = fees
+	}
+}
+
+// fee комиссия операции op на сумму amount, которую платит payerID, и кошелек сбора комиссий.
+// Кошелек сбора комиссий комиссию не платит.
+func (man *manager) fee(op models.OperationType, payerID string, amount float64) (float64, string) {
+	collector := man.feeCollector(payerID)
+	if collector == "" {
+		return 0, ""
+	}
+	return man.roundAmount(man.fees.Fee(op, amount)), collector
+}
+
+// feeCollector кошелек сбора комиссий, пустой, если payerID комиссию не платит.
+func (man *manager) feeCollector(payerID string) string {
+	if man.fees == nil {
+		return ""
+	}
+	collector := man.fees.Collector()
+	if collector == payerID {
+		return ""
+	}
+	return collector
+}
+
+// chargeFee переводим комиссию с кошелька payerID на кошелек сбора комиссий в текущей транзакции.
+// Возвращает проводки комиссии, пустые при нулевой комиссии.
+// Максимальный баланс к кошельку сбора комиссий не применяется.
+func (man *manager) chargeFee(repo models.WalletRepository, payerID, collectorID string, fee float64) ([]models.LedgerEntry, error) {
+	if fee <= 0 {
+		return nil, nil
+	}
+
+	payer, err := repo.ByID(payerID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get wallet by id %s: %w", payerID, err)
+	}
+	err = repo.UpdateByID(payerID, models.WalletUpdate{Balance: utils.Ptr[float64](payer.Balance() - fee)})
+	if err != nil {
+		return nil, fmt.Errorf("cannot charge fee: %w", err)
+	}
+
+	collector, err := repo.ByID(collectorID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get fee wallet by id %s: %w", collectorID, err)
+	}
+	err = repo.UpdateByID(collectorID, models.WalletUpdate{Balance: utils.Ptr[float64](collector.Balance() + fee)})
+	if err != nil {
+		return nil, fmt.Errorf("cannot credit fee wallet: %w", err)
+	}
+
+	return []models.LedgerEntry{
+		{WalletID: payerID, Amount: -fee, Counterparty: collectorID, Fee: true},
+		{WalletID: collectorID, Amount: fee, Counterparty: payerID, Fee: true},
+	}, nil
+}
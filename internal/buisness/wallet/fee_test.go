@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/buisness/fee"
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFees_transfer(t *testing.T) {
+	repo := repository.NewRepo()
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	shop := repo.Create("shop", 0, true, "shop", nil)
+	fees := repo.Create("fees", 0, true, "system", nil)
+
+	engine, err := fee.NewEngine(models.FeeRules{
+		WalletID: fees.ID(),
+		Operations: map[models.OperationType]models.FeeRule{
+			models.OperationTransfer: {Flat: 1, Percent: 2},
+		},
+	})
+	assert.Nil(t, err)
+	man := NewManager(repo, WithFees(engine))
+
+	result, err := man.TransferBalance(ownerCtx(), wallet.ID(), shop.ID(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(3), result.Fee)
+	assert.NotEmpty(t, result.OperationID)
+
+	assertBalance(t, repo, wallet.ID(), 897)
+	assertBalance(t, repo, shop.ID(), 100)
+	assertBalance(t, repo, fees.ID(), 3)
+
+	entries, err := repo.Ledger(models.LedgerFilter{OperationID: result.OperationID})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 4)
+
+	// комиссии нет для операций без правила
+	result, err = man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 97)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), result.Fee)
+	assertBalance(t, repo, wallet.ID(), 800)
+}
+
+func TestFees_batchAndCapture(t *testing.T) {
+	repo := repository.NewRepo()
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	shop := repo.Create("shop", 0, true, "shop", nil)
+	fees := repo.Create("fees", 0, true, "system", nil)
+
+	engine, err := fee.NewEngine(models.FeeRules{
+		WalletID: fees.ID(),
+		Operations: map[models.OperationType]models.FeeRule{
+			models.OperationTransfer: {Flat: 2},
+			models.OperationWithdraw: {Flat: 1},
+		},
+	})
+	assert.Nil(t, err)
+	man := NewManager(repo, WithFees(engine))
+
+	batch, err := man.TransferBatch(ownerCtx(), []models.TransferLeg{
+		{FromID: wallet.ID(), ToID: shop.ID(), Amount: 100},
+		{FromID: wallet.ID(), ToID: shop.ID(), Amount: 50},
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, batch.OperationID)
+	assert.Equal(t, float64(150), batch.Amount)
+	assert.Equal(t, float64(4), batch.Fee)
+	assert.Equal(t, []float64{2, 2}, batch.Fees)
+	assertBalance(t, repo, wallet.ID(), 846)
+	assertBalance(t, repo, shop.ID(), 150)
+	assertBalance(t, repo, fees.ID(), 4)
+
+	hold, err := man.CreateHold(ownerCtx(), wallet.ID(), 100, 0)
+	assert.Nil(t, err)
+	captured, err := man.CaptureHold(ownerCtx(), hold.ID, 0, shop.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(2), captured.Fee)
+	entries, err := repo.Ledger(models.LedgerFilter{OperationID: captured.OperationID})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 4)
+	assert.Equal(t, models.OperationTransfer, entries[0].Type)
+	assertBalance(t, repo, wallet.ID(), 744)
+	assertBalance(t, repo, shop.ID(), 250)
+	assertBalance(t, repo, fees.ID(), 6)
+
+	// списание без перевода платит комиссию снятия
+	hold, err = man.CreateHold(ownerCtx(), wallet.ID(), 43, 0)
+	assert.Nil(t, err)
+	captured, err = man.CaptureHold(ownerCtx(), hold.ID, 0, "")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), captured.Fee)
+	entries, err = repo.Ledger(models.LedgerFilter{OperationID: captured.OperationID})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OperationWithdraw, entries[0].Type)
+	assertBalance(t, repo, wallet.ID(), 700)
+	assertBalance(t, repo, fees.ID(), 7)
+
+	// на комиссию не хватает доступного остатка
+	hold, err = man.CreateHold(ownerCtx(), wallet.ID(), 700, 0)
+	assert.Nil(t, err)
+	_, err = man.CaptureHold(ownerCtx(), hold.ID, 0, shop.ID())
+	assert.True(t, errors.Is(err, errNotEnoughBalance))
+	assertBalance(t, repo, fees.ID(), 7)
+}
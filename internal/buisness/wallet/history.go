@@ -0,0 +1,87 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// BalanceAt баланс кошелька на момент at, пересчитанный по журналу операций.
+// Учитываются проводки, записанные раньше at, поэтому баланс на начало месяца
+// равен балансу на конец предыдущего месяца.
+func (man *manager) BalanceAt(ctx context.Context, id string, at time.Time) (_ models.WalletBalance, err error) {
+	ctx, span := startSpan(ctx, "wallet.BalanceAt", attribute.String("wallet.id", id))
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletRead)
+	if err != nil {
+		return models.WalletBalance{}, err
+	}
+
+	wallet, err := man.repo.ByID(id)
+	if err != nil {
+		return models.WalletBalance{}, err
+	}
+	err = man.checkOwner(ctx, wallet)
+	if err != nil {
+		return models.WalletBalance{}, err
+	}
+	if wallet.CreatedAt().After(at) {
+		return models.WalletBalance{}, fmt.Errorf("wallet %s created after %s: %w", id, at.Format(time.RFC3339), models.ErrInvalidArgument)
+	}
+
+	entries, err := man.repo.Ledger(models.LedgerFilter{WalletID: id, To: at})
+	if err != nil {
+		return models.WalletBalance{}, err
+	}
+	var balance float64
+	for _, entry := range entries {
+		balance += entry.Amount
+	}
+
+	return models.WalletBalance{WalletID: id, Balance: man.roundAmount(balance), At: at}, nil
+}
+
+// BalancesAt балансы кошельков клиента на момент at для отчетов на конец периода.
+// Клиент с правом на чужие кошельки получает балансы кошельков всех владельцев.
+func (man *manager) BalancesAt(ctx context.Context, at time.Time) (_ []models.WalletBalance, err error) {
+	ctx, span := startSpan(ctx, "wallet.BalancesAt")
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletList)
+	if err != nil {
+		return nil, err
+	}
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := man.repo.Ledger(models.LedgerFilter{To: at})
+	if err != nil {
+		return nil, err
+	}
+	sums := make(map[string]float64)
+	for _, entry := range entries {
+		sums[entry.WalletID] += entry.Amount
+	}
+
+	out := make([]models.WalletBalance, 0)
+	for _, wallet := range man.repo.All() {
+		if wallet.CreatedAt().After(at) {
+			continue
+		}
+		if wallet.Owner() != p.ID && !man.anyOwner(p) {
+			continue
+		}
+		out = append(out, models.WalletBalance{
+			WalletID: wallet.ID(),
+			Balance:  man.roundAmount(sums[wallet.ID()]),
+			At:       at,
+		})
+	}
+	return out, nil
+}
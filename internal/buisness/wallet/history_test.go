@@ -0,0 +1,50 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceAt(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	beforeCreate := time.Now()
+	time.Sleep(time.Millisecond)
+	wallet := repo.Create("wallet", 100, true, testOwner, nil)
+	shop := repo.Create("shop", 0, true, "shop", nil)
+
+	_, err := man.TransferBalance(ownerCtx(), wallet.ID(), shop.ID(), 30)
+	assert.Nil(t, err)
+	time.Sleep(time.Millisecond)
+	monthEnd := time.Now()
+	time.Sleep(time.Millisecond)
+	_, err = man.IncreaseBalanceBy(ownerCtx(), wallet.ID(), 50)
+	assert.Nil(t, err)
+
+	got, err := man.BalanceAt(ownerCtx(), wallet.ID(), monthEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(70), got.Balance)
+
+	got, err = man.BalanceAt(ownerCtx(), wallet.ID(), time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(120), got.Balance)
+
+	_, err = man.BalanceAt(ownerCtx(), wallet.ID(), beforeCreate)
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+	_, err = man.BalanceAt(ownerCtx(), shop.ID(), monthEnd)
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+
+	balances, err := man.BalancesAt(ownerCtx(), monthEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, []models.WalletBalance{{WalletID: wallet.ID(), Balance: 70, At: monthEnd}}, balances)
+
+	balances, err = man.BalancesAt(adminCtx(), monthEnd)
+	assert.Nil(t, err)
+	assert.Len(t, balances, 2)
+	assert.Equal(t, float64(30), balances[1].Balance)
+}
@@ -0,0 +1,266 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// defaultHoldTTL срок блокировки, если он не задан.
+	defaultHoldTTL = 7 * 24 * time.Hour
+	// maxHoldTTL максимальный срок блокировки.
+	maxHoldTTL = 30 * 24 * time.Hour
+)
+
+var (
+	errHoldTTLTooLong     = fmt.Errorf("hold ttl cannot exceed %s", maxHoldTTL)
+	errHoldExpired        = errors.New("hold has expired")
+	errCaptureExceedsHold = errors.New("capture amount exceeds hold amount")
+)
+
+// CreateHold блокируем средства кошелька без списания.
+// Блокировка уменьшает доступный баланс и автоматически истекает через ttl(0 - срок по умолчанию).
+func (man *manager) CreateHold(ctx context.Context, walletID string, amount float64, ttl time.Duration) (_ models.Hold, err error) {
+	ctx, span := startSpan(ctx, "wallet.CreateHold",
+		attribute.String("wallet.id", walletID),
+		attribute.Float64("wallet.amount", amount),
+	)
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletHold)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	err = man.validateAmount(amount)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	if ttl <= 0 {
+		ttl = defaultHoldTTL
+	}
+	if ttl > maxHoldTTL {
+		return models.Hold{}, errHoldTTLTooLong
+	}
+	err = man.checkAmount(walletID, amount)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	var hold models.Hold
+	errTx := man.repo.Transaction(ctx, []string{walletID}, func(repo models.WalletRepository) error {
+		wallet, err := repo.ByID(walletID)
+		if err != nil {
+			return fmt.Errorf("wallet %s: %w", walletID, err)
+		}
+		err = man.checkOwner(ctx, wallet)
+		if err != nil {
+			return err
+		}
+		if wallet.Available() < amount {
+			return fmt.Errorf("wallet %s: %w", wallet.ID(), errNotEnoughBalance)
+		}
+		err = man.checkOutgoing(repo, walletID, amount, 1)
+		if err != nil {
+			return err
+		}
+
+		hold, err = repo.CreateHold(walletID, amount, man.now().Add(ttl))
+		return err
+	})
+	if errTx != nil {
+		return models.Hold{}, errTx
+	}
+
+	span.SetAttributes(attribute.String("hold.id", hold.ID))
+	return hold, nil
+}
+
+// CaptureHold списываем заблокированные средства.
+// amount - сумма списания, не больше суммы блокировки(0 - вся сумма), остаток освобождается.
+// toID - кошелек получателя, пустой при списании без перевода.
+// Захват записывается и тарифицируется как снятие(без toID) или перевод(с toID):
+// с кошелька блокировки дополнительно списывается комиссия этой операции.
+func (man *manager) CaptureHold(ctx context.Context, holdID string, amount float64, toID string) (_ models.Hold, err error) {
+	ctx, span := startSpan(ctx, "wallet.CaptureHold",
+		attribute.String("hold.id", holdID),
+		attribute.Float64("wallet.amount", amount),
+		attribute.String("wallet.to_id", toID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletHold)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	if amount != 0 {
+		err = man.validateAmount(amount)
+		if err != nil {
+			return models.Hold{}, err
+		}
+	}
+	walletID, err := man.holdWallet(holdID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	if walletID == toID {
+		return models.Hold{}, errSameWallet
+	}
+	op := models.OperationWithdraw
+	ids := []string{walletID}
+	if toID != "" {
+		op = models.OperationTransfer
+		ids = append(ids, toID)
+	}
+	if collector := man.feeCollector(walletID); collector != "" && collector != toID {
+		ids = append(ids, collector)
+	}
+
+	var hold models.Hold
+	errTx := man.repo.Transaction(ctx, ids, func(repo models.WalletRepository) error {
+		wallet, closed, err := man.closeHold(ctx, repo, holdID)
+		if err != nil {
+			return err
+		}
+
+		captured := amount
+		if captured == 0 {
+			captured = closed.Amount
+		}
+		if captured > closed.Amount {
+			return fmt.Errorf("hold %s: %w", holdID, errCaptureExceedsHold)
+		}
+		fee, feeWalletID := man.fee(op, wallet.ID(), captured)
+		// заблокированных средств хватает на сумму, комиссия списывается из доступного остатка
+		if wallet.Available() < captured+fee {
+			return fmt.Errorf("wallet %s: %w", wallet.ID(), errNotEnoughBalance)
+		}
+		// блокировка уже снята и не входит в объем, списание проверяется как новое
+		err = man.checkOutgoing(repo, wallet.ID(), captured+fee, 1)
+		if err != nil {
+			return err
+		}
+
+		err = repo.UpdateByID(wallet.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](wallet.Balance() - captured)})
+		if err != nil {
+			return fmt.Errorf("cannot update source wallet: %w", err)
+		}
+		entries := []models.LedgerEntry{{WalletID: wallet.ID(), Amount: -captured}}
+		if toID != "" {
+			entries = transferEntries(wallet.ID(), toID, captured)
+			toWallet, err := repo.ByID(toID)
+			if err != nil {
+				return fmt.Errorf("cannot get dest wallet by id %s: %w", toID, err)
+			}
+			err = man.checkBalanceCeiling(toID, toWallet.Balance()+captured)
+			if err != nil {
+				return err
+			}
+			err = repo.UpdateByID(toID, models.WalletUpdate{Balance: utils.Ptr[float64](toWallet.Balance() + captured)})
+			if err != nil {
+				return fmt.Errorf("cannot update dest wallet: %w", err)
+			}
+		}
+
+		feeEntries, err := man.chargeFee(repo, wallet.ID(), feeWalletID, fee)
+		if err != nil {
+			return err
+		}
+		opID, err := repo.RecordOperation(op, append(entries, feeEntries...))
+		if err != nil {
+			return err
+		}
+
+		closed.Status = models.HoldStatusCaptured
+		closed.Captured = captured
+		closed.CapturedTo = toID
+		closed.Fee = fee
+		closed.OperationID = opID
+		hold = closed
+		return nil
+	})
+	if errTx != nil {
+		return models.Hold{}, errTx
+	}
+
+	return hold, nil
+}
+
+// ReleaseHold снимаем блокировку без списания, средства снова доступны.
+func (man *manager) ReleaseHold(ctx context.Context, holdID string) (_ models.Hold, err error) {
+	ctx, span := startSpan(ctx, "wallet.ReleaseHold", attribute.String("hold.id", holdID))
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletHold)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	walletID, err := man.holdWallet(holdID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	var hold models.Hold
+	errTx := man.repo.Transaction(ctx, []string{walletID}, func(repo models.WalletRepository) error {
+		_, closed, err := man.closeHold(ctx, repo, holdID)
+		if err != nil {
+			return err
+		}
+
+		closed.Status = models.HoldStatusReleased
+		hold = closed
+		return nil
+	})
+	if errTx != nil {
+		return models.Hold{}, errTx
+	}
+
+	return hold, nil
+}
+
+// holdWallet идентификатор кошелька блокировки, нужен для объявления транзакции.
+func (man *manager) holdWallet(holdID string) (string, error) {
+	hold, err := man.repo.HoldByID(holdID)
+	if err != nil {
+		return "", fmt.Errorf("hold %s: %w", holdID, err)
+	}
+	return hold.WalletID, nil
+}
+
+// closeHold снимаем активную блокировку внутри транзакции.
+// Возвращает кошелек блокировки после снятия и саму блокировку.
+func (man *manager) closeHold(ctx context.Context, repo models.WalletRepository, holdID string) (models.Walleter, models.Hold, error) {
+	hold, err := repo.HoldByID(holdID)
+	if err != nil {
+		return nil, models.Hold{}, fmt.Errorf("hold %s: %w", holdID, err)
+	}
+	wallet, err := repo.ByID(hold.WalletID)
+	if err != nil {
+		return nil, models.Hold{}, fmt.Errorf("wallet %s: %w", hold.WalletID, err)
+	}
+	err = man.checkOwner(ctx, wallet)
+	if err != nil {
+		return nil, models.Hold{}, err
+	}
+	if hold.Status == models.HoldStatusExpired {
+		return nil, models.Hold{}, fmt.Errorf("hold %s: %w", holdID, errHoldExpired)
+	}
+
+	hold, err = repo.CloseHold(holdID)
+	if err != nil {
+		return nil, models.Hold{}, fmt.Errorf("hold %s: %w", holdID, err)
+	}
+	wallet, err = repo.ByID(hold.WalletID)
+	if err != nil {
+		return nil, models.Hold{}, fmt.Errorf("wallet %s: %w", hold.WalletID, err)
+	}
+	return wallet, hold, nil
+}
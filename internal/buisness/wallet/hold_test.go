@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateHold_reducesAvailable(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	card := repo.Create("card", 100, true, testOwner, nil)
+
+	hold, err := man.CreateHold(ownerCtx(), card.ID(), 80, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, models.HoldStatusActive, hold.Status)
+
+	_, err = man.DecreaseBalanceBy(ownerCtx(), card.ID(), 30)
+	assert.True(t, errors.Is(err, errNotEnoughBalance))
+	_, err = man.CreateHold(ownerCtx(), card.ID(), 30, 0)
+	assert.True(t, errors.Is(err, errNotEnoughBalance))
+
+	assertBalance(t, repo, card.ID(), 100)
+}
+
+func TestCreateHold_clock(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	man.now = func() time.Time { return clock }
+	card := repo.Create("card", 100, true, testOwner, nil)
+
+	hold, err := man.CreateHold(ownerCtx(), card.ID(), 10, time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, clock.Add(time.Hour), hold.ExpiresAt)
+}
+
+func TestCaptureHold_partialTransfer(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	card := repo.Create("card", 100, true, testOwner, nil)
+	shop := repo.Create("shop", 0, true, "shop", nil)
+
+	hold, err := man.CreateHold(ownerCtx(), card.ID(), 80, 0)
+	assert.Nil(t, err)
+
+	captured, err := man.CaptureHold(ownerCtx(), hold.ID, 50, shop.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, models.HoldStatusCaptured, captured.Status)
+	assert.Equal(t, float64(50), captured.Captured)
+
+	assertBalance(t, repo, card.ID(), 50)
+	assertBalance(t, repo, shop.ID(), 50)
+	wallet, err := repo.ByID(card.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(50), wallet.Available())
+
+	_, err = man.ReleaseHold(ownerCtx(), hold.ID)
+	assert.NotNil(t, err)
+}
+
+func TestCaptureHold_exceedsHold(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	card := repo.Create("card", 100, true, testOwner, nil)
+
+	hold, err := man.CreateHold(ownerCtx(), card.ID(), 40, 0)
+	assert.Nil(t, err)
+
+	_, err = man.CaptureHold(ownerCtx(), hold.ID, 50, "")
+	assert.True(t, errors.Is(err, errCaptureExceedsHold))
+
+	wallet, err := repo.ByID(card.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), wallet.Balance())
+	assert.Equal(t, float64(60), wallet.Available())
+}
+
+func TestReleaseHold(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	card := repo.Create("card", 100, true, testOwner, nil)
+
+	hold, err := man.CreateHold(ownerCtx(), card.ID(), 40, 0)
+	assert.Nil(t, err)
+
+	released, err := man.ReleaseHold(ownerCtx(), hold.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.HoldStatusReleased, released.Status)
+
+	wallet, err := repo.ByID(card.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), wallet.Available())
+}
@@ -0,0 +1,125 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+const (
+	limitScopeGlobal = "global"
+	limitScopeWallet = "wallet"
+)
+
+// WithLimits проверять суммы операций и списания за сутки и месяц по правилам лимитов.
+func WithLimits(rules models.LimitRules) Option {
+	return func(man *manager) {
+		man.limits = rules
+	}
+}
+
+// scopedLimits правила, действующие для кошелька.
+type scopedLimits struct {
+	scope  string
+	limits models.Limits
+}
+
+// walletLimits общие правила и правила кошелька.
+func (man *manager) walletLimits(walletID string) []scopedLimits {
+	out := []scopedLimits{{scope: limitScopeGlobal, limits: man.limits.Global}}
+	if limits, ok := man.limits.Wallets[walletID]; ok {
+		out = append(out, scopedLimits{scope: limitScopeWallet, limits: limits})
+	}
+	return out
+}
+
+// checkAmount лимит суммы одной операции по кошельку.
+func (man *manager) checkAmount(walletID string, amount float64) error {
+	for _, rule := range man.walletLimits(walletID) {
+		if rule.limits.MaxAmount > 0 && amount > rule.limits.MaxAmount {
+			return &models.LimitError{
+				Scope:    rule.scope,
+				WalletID: walletID,
+				Rule:     "max_amount",
+				Limit:    rule.limits.MaxAmount,
+				Value:    amount,
+			}
+		}
+	}
+	return nil
+}
+
+// checkOutgoing лимиты списаний с кошелька за сутки и месяц.
+// Учитываются списания из журнала операций, активные блокировки(они будут списаны при подтверждении)
+// и новые count списаний на сумму amount.
+func (man *manager) checkOutgoing(repo models.WalletRepository, walletID string, amount float64, count int) error {
+	rules := man.walletLimits(walletID)
+	periodic := false
+	for _, rule := range rules {
+		l := rule.limits
+		if l.DailyVolume > 0 || l.DailyCount > 0 || l.MonthlyVolume > 0 || l.MonthlyCount > 0 {
+			periodic = true
+		}
+	}
+	if !periodic {
+		return nil
+	}
+
+	now := man.now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	entries, err := repo.Ledger(models.LedgerFilter{WalletID: walletID, From: monthStart})
+	if err != nil {
+		return err
+	}
+	wallet, err := repo.ByID(walletID)
+	if err != nil {
+		return fmt.Errorf("wallet %s: %w", walletID, err)
+	}
+
+	dailyVolume, monthlyVolume := amount+wallet.Held(), amount+wallet.Held()
+	dailyCount, monthlyCount := count, count
+	for _, entry := range entries {
+		// сторнирование и исправления сверки правят прошлые операции и не считаются списанием
+		if entry.Amount >= 0 || entry.Reverses != "" || entry.Type == models.OperationCorrection {
+			continue
+		}
+		// комиссия входит в объем списаний, но не считается отдельным списанием
+		count := 1
+		if entry.Fee {
+			count = 0
+		}
+		monthlyVolume -= entry.Amount
+		monthlyCount += count
+		if !entry.Time.Before(dayStart) {
+			dailyVolume -= entry.Amount
+			dailyCount += count
+		}
+	}
+
+	for _, rule := range rules {
+		checks := []struct {
+			name  string
+			limit float64
+			value float64
+		}{
+			{"daily_volume", rule.limits.DailyVolume, dailyVolume},
+			{"daily_count", float64(rule.limits.DailyCount), float64(dailyCount)},
+			{"monthly_volume", rule.limits.MonthlyVolume, monthlyVolume},
+			{"monthly_count", float64(rule.limits.MonthlyCount), float64(monthlyCount)},
+		}
+		for _, check := range checks {
+			if check.limit > 0 && check.value > check.limit {
+				return &models.LimitError{
+					Scope:    rule.scope,
+					WalletID: walletID,
+					Rule:     check.name,
+					Limit:    check.limit,
+					Value:    check.value,
+				}
+			}
+		}
+	}
+	return nil
+}
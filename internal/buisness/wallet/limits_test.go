@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits_maxAmount(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo, WithLimits(models.LimitRules{Global: models.Limits{MaxAmount: 100}}))
+	wallet := repo.Create("wallet", 0, true, testOwner, nil)
+
+	_, err := man.IncreaseBalanceBy(ownerCtx(), wallet.ID(), 150)
+	var limitErr *models.LimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "global", limitErr.Scope)
+	assert.Equal(t, "max_amount", limitErr.Rule)
+	assert.True(t, errors.Is(err, models.ErrLimitExceeded))
+}
+
+func TestLimits_dailyFromLedger(t *testing.T) {
+	repo := repository.NewRepo()
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	shop := repo.Create("shop", 0, true, "shop", nil)
+	man := NewManager(repo, WithLimits(models.LimitRules{
+		Wallets: map[string]models.Limits{wallet.ID(): {DailyVolume: 250, DailyCount: 2}},
+	}))
+
+	_, err := man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 100)
+	assert.Nil(t, err)
+	_, err = man.TransferBalance(ownerCtx(), wallet.ID(), shop.ID(), 100)
+	assert.Nil(t, err)
+
+	_, err = man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 10)
+	var limitErr *models.LimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "wallet", limitErr.Scope)
+	assert.Equal(t, "daily_count", limitErr.Rule)
+
+	_, err = man.TransferBatch(ownerCtx(), []models.TransferLeg{{FromID: wallet.ID(), ToID: shop.ID(), Amount: 60}})
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "daily_volume", limitErr.Rule)
+	assertBalance(t, repo, wallet.ID(), 800)
+}
+
+func TestLimits_dailyWindowClock(t *testing.T) {
+	repo := repository.NewRepo()
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	man := NewManager(repo, WithLimits(models.LimitRules{
+		Wallets: map[string]models.Limits{wallet.ID(): {DailyCount: 1}},
+	}))
+
+	_, err := man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 100)
+	assert.Nil(t, err)
+	_, err = man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 100)
+	assert.True(t, errors.Is(err, models.ErrLimitExceeded))
+
+	// на следующий день окно лимита начинается заново
+	man.now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+	_, err = man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 100)
+	assert.Nil(t, err)
+}
+
+func TestLimits_holdsCounted(t *testing.T) {
+	repo := repository.NewRepo()
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	man := NewManager(repo, WithLimits(models.LimitRules{
+		Wallets: map[string]models.Limits{wallet.ID(): {DailyVolume: 250, DailyCount: 2}},
+	}))
+
+	first, err := man.CreateHold(ownerCtx(), wallet.ID(), 200, 0)
+	assert.Nil(t, err)
+	_, err = man.CreateHold(ownerCtx(), wallet.ID(), 100, 0)
+	var limitErr *models.LimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "daily_volume", limitErr.Rule)
+	assert.Equal(t, float64(300), limitErr.Value)
+
+	second, err := man.CreateHold(ownerCtx(), wallet.ID(), 50, 0)
+	assert.Nil(t, err)
+	_, err = man.CaptureHold(ownerCtx(), first.ID, 0, "")
+	assert.Nil(t, err)
+	_, err = man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 1)
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "daily_volume", limitErr.Rule)
+
+	_, err = man.CaptureHold(ownerCtx(), second.ID, 0, "")
+	assert.Nil(t, err)
+	assertBalance(t, repo, wallet.ID(), 750)
+}
+
+func TestLimits_correctionNotCounted(t *testing.T) {
+	repo := repository.NewRepo()
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	man := NewManager(repo, WithLimits(models.LimitRules{
+		Wallets: map[string]models.Limits{wallet.ID(): {DailyVolume: 250, DailyCount: 1}},
+	}))
+
+	_, err := repo.RecordOperation(models.OperationCorrection, []models.LedgerEntry{{WalletID: wallet.ID(), Amount: -500}})
+	assert.Nil(t, err)
+
+	_, err = man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 200)
+	assert.Nil(t, err)
+}
@@ -3,8 +3,10 @@ package wallet
 // Code generated by http://github.com/gojuno/minimock (dev). DO NOT EDIT.
 
 import (
+	"context"
 	"sync"
 	mm_atomic "sync/atomic"
+	"time"
 	mm_time "time"
 
 	mm_models "github.com/Nizom98/wallet/internal/models"
@@ -27,20 +29,56 @@ type RepositoryMock struct {
 	beforeByIDCounter uint64
 	ByIDMock          mRepositoryMockByID
 
-	funcCreate          func(name string, balance float64, status bool) (w1 mm_models.Walleter)
-	inspectFuncCreate   func(name string, balance float64, status bool)
+	funcCloseHold          func(id string) (h1 mm_models.Hold, err error)
+	inspectFuncCloseHold   func(id string)
+	afterCloseHoldCounter  uint64
+	beforeCloseHoldCounter uint64
+	CloseHoldMock          mRepositoryMockCloseHold
+
+	funcCreate          func(name string, balance float64, status bool, owner string, metadata map[string]string) (w1 mm_models.Walleter)
+	inspectFuncCreate   func(name string, balance float64, status bool, owner string, metadata map[string]string)
 	afterCreateCounter  uint64
 	beforeCreateCounter uint64
 	CreateMock          mRepositoryMockCreate
 
-	funcTransaction          func(fn func(repo mm_models.WalletRepository) error) (err error)
-	inspectFuncTransaction   func(fn func(repo mm_models.WalletRepository) error)
+	funcCreateHold          func(walletID string, amount float64, expiresAt time.Time) (h1 mm_models.Hold, err error)
+	inspectFuncCreateHold   func(walletID string, amount float64, expiresAt time.Time)
+	afterCreateHoldCounter  uint64
+	beforeCreateHoldCounter uint64
+	CreateHoldMock          mRepositoryMockCreateHold
+
+	funcHoldByID          func(id string) (h1 mm_models.Hold, err error)
+	inspectFuncHoldByID   func(id string)
+	afterHoldByIDCounter  uint64
+	beforeHoldByIDCounter uint64
+	HoldByIDMock          mRepositoryMockHoldByID
+
+	funcLedger          func(filter mm_models.LedgerFilter) (la1 []mm_models.LedgerEntry, err error)
+	inspectFuncLedger   func(filter mm_models.LedgerFilter)
+	afterLedgerCounter  uint64
+	beforeLedgerCounter uint64
+	LedgerMock          mRepositoryMockLedger
+
+	funcList          func(filter mm_models.WalletFilter) (wp1 *mm_models.WalletPage, err error)
+	inspectFuncList   func(filter mm_models.WalletFilter)
+	afterListCounter  uint64
+	beforeListCounter uint64
+	ListMock          mRepositoryMockList
+
+	funcRecordOperation          func(opType mm_models.OperationType, entries []mm_models.LedgerEntry) (s1 string, err error)
+	inspectFuncRecordOperation   func(opType mm_models.OperationType, entries []mm_models.LedgerEntry)
+	afterRecordOperationCounter  uint64
+	beforeRecordOperationCounter uint64
+	RecordOperationMock          mRepositoryMockRecordOperation
+
+	funcTransaction          func(ctx context.Context, ids []string, fn func(repo mm_models.WalletRepository) error) (err error)
+	inspectFuncTransaction   func(ctx context.Context, ids []string, fn func(repo mm_models.WalletRepository) error)
 	afterTransactionCounter  uint64
 	beforeTransactionCounter uint64
 	TransactionMock          mRepositoryMockTransaction
 
-	funcUpdateByID          func(id string, name *string, balance *float64, status *bool) (err error)
-	inspectFuncUpdateByID   func(id string, name *string, balance *float64, status *bool)
+	funcUpdateByID          func(id string, upd mm_models.WalletUpdate) (err error)
+	inspectFuncUpdateByID   func(id string, upd mm_models.WalletUpdate)
 	afterUpdateByIDCounter  uint64
 	beforeUpdateByIDCounter uint64
 	UpdateByIDMock          mRepositoryMockUpdateByID
@@ -58,9 +96,27 @@ func NewRepositoryMock(t minimock.Tester) *RepositoryMock {
 	m.ByIDMock = mRepositoryMockByID{mock: m}
 	m.ByIDMock.callArgs = []*RepositoryMockByIDParams{}
 
+	m.CloseHoldMock = mRepositoryMockCloseHold{mock: m}
+	m.CloseHoldMock.callArgs = []*RepositoryMockCloseHoldParams{}
+
 	m.CreateMock = mRepositoryMockCreate{mock: m}
 	m.CreateMock.callArgs = []*RepositoryMockCreateParams{}
 
+	m.CreateHoldMock = mRepositoryMockCreateHold{mock: m}
+	m.CreateHoldMock.callArgs = []*RepositoryMockCreateHoldParams{}
+
+	m.HoldByIDMock = mRepositoryMockHoldByID{mock: m}
+	m.HoldByIDMock.callArgs = []*RepositoryMockHoldByIDParams{}
+
+	m.LedgerMock = mRepositoryMockLedger{mock: m}
+	m.LedgerMock.callArgs = []*RepositoryMockLedgerParams{}
+
+	m.ListMock = mRepositoryMockList{mock: m}
+	m.ListMock.callArgs = []*RepositoryMockListParams{}
+
+	m.RecordOperationMock = mRepositoryMockRecordOperation{mock: m}
+	m.RecordOperationMock.callArgs = []*RepositoryMockRecordOperationParams{}
+
 	m.TransactionMock = mRepositoryMockTransaction{mock: m}
 	m.TransactionMock.callArgs = []*RepositoryMockTransactionParams{}
 
@@ -429,6 +485,222 @@ func (m *RepositoryMock) MinimockByIDInspect() {
 	}
 }
 
+type mRepositoryMockCloseHold struct {
+	mock               *RepositoryMock
+	defaultExpectation *RepositoryMockCloseHoldExpectation
+	expectations       []*RepositoryMockCloseHoldExpectation
+
+	callArgs []*RepositoryMockCloseHoldParams
+	mutex    sync.RWMutex
+}
+
+// RepositoryMockCloseHoldExpectation specifies expectation struct of the WalletRepository.CloseHold
+type RepositoryMockCloseHoldExpectation struct {
+	mock    *RepositoryMock
+	params  *RepositoryMockCloseHoldParams
+	results *RepositoryMockCloseHoldResults
+	Counter uint64
+}
+
+// RepositoryMockCloseHoldParams contains parameters of the WalletRepository.CloseHold
+type RepositoryMockCloseHoldParams struct {
+	id string
+}
+
+// RepositoryMockCloseHoldResults contains results of the WalletRepository.CloseHold
+type RepositoryMockCloseHoldResults struct {
+	h1  mm_models.Hold
+	err error
+}
+
+// Expect sets up expected params for WalletRepository.CloseHold
+func (mmCloseHold *mRepositoryMockCloseHold) Expect(id string) *mRepositoryMockCloseHold {
+	if mmCloseHold.mock.funcCloseHold != nil {
+		mmCloseHold.mock.t.Fatalf("RepositoryMock.CloseHold mock is already set by Set")
+	}
+
+	if mmCloseHold.defaultExpectation == nil {
+		mmCloseHold.defaultExpectation = &RepositoryMockCloseHoldExpectation{}
+	}
+
+	mmCloseHold.defaultExpectation.params = &RepositoryMockCloseHoldParams{id}
+	for _, e := range mmCloseHold.expectations {
+		if minimock.Equal(e.params, mmCloseHold.defaultExpectation.params) {
+			mmCloseHold.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmCloseHold.defaultExpectation.params)
+		}
+	}
+
+	return mmCloseHold
+}
+
+// Inspect accepts an inspector function that has same arguments as the WalletRepository.CloseHold
+func (mmCloseHold *mRepositoryMockCloseHold) Inspect(f func(id string)) *mRepositoryMockCloseHold {
+	if mmCloseHold.mock.inspectFuncCloseHold != nil {
+		mmCloseHold.mock.t.Fatalf("Inspect function is already set for RepositoryMock.CloseHold")
+	}
+
+	mmCloseHold.mock.inspectFuncCloseHold = f
+
+	return mmCloseHold
+}
+
+// Return sets up results that will be returned by WalletRepository.CloseHold
+func (mmCloseHold *mRepositoryMockCloseHold) Return(h1 mm_models.Hold, err error) *RepositoryMock {
+	if mmCloseHold.mock.funcCloseHold != nil {
+		mmCloseHold.mock.t.Fatalf("RepositoryMock.CloseHold mock is already set by Set")
+	}
+
+	if mmCloseHold.defaultExpectation == nil {
+		mmCloseHold.defaultExpectation = &RepositoryMockCloseHoldExpectation{mock: mmCloseHold.mock}
+	}
+	mmCloseHold.defaultExpectation.results = &RepositoryMockCloseHoldResults{h1, err}
+	return mmCloseHold.mock
+}
+
+// Set uses given function f to mock the WalletRepository.CloseHold method
+func (mmCloseHold *mRepositoryMockCloseHold) Set(f func(id string) (h1 mm_models.Hold, err error)) *RepositoryMock {
+	if mmCloseHold.defaultExpectation != nil {
+		mmCloseHold.mock.t.Fatalf("Default expectation is already set for the WalletRepository.CloseHold method")
+	}
+
+	if len(mmCloseHold.expectations) > 0 {
+		mmCloseHold.mock.t.Fatalf("Some expectations are already set for the WalletRepository.CloseHold method")
+	}
+
+	mmCloseHold.mock.funcCloseHold = f
+	return mmCloseHold.mock
+}
+
+// When sets expectation for the WalletRepository.CloseHold which will trigger the result defined by the following
+// Then helper
+func (mmCloseHold *mRepositoryMockCloseHold) When(id string) *RepositoryMockCloseHoldExpectation {
+	if mmCloseHold.mock.funcCloseHold != nil {
+		mmCloseHold.mock.t.Fatalf("RepositoryMock.CloseHold mock is already set by Set")
+	}
+
+	expectation := &RepositoryMockCloseHoldExpectation{
+		mock:   mmCloseHold.mock,
+		params: &RepositoryMockCloseHoldParams{id},
+	}
+	mmCloseHold.expectations = append(mmCloseHold.expectations, expectation)
+	return expectation
+}
+
+// Then sets up WalletRepository.CloseHold return parameters for the expectation previously defined by the When method
+func (e *RepositoryMockCloseHoldExpectation) Then(h1 mm_models.Hold, err error) *RepositoryMock {
+	e.results = &RepositoryMockCloseHoldResults{h1, err}
+	return e.mock
+}
+
+// CloseHold implements models.WalletRepository
+func (mmCloseHold *RepositoryMock) CloseHold(id string) (h1 mm_models.Hold, err error) {
+	mm_atomic.AddUint64(&mmCloseHold.beforeCloseHoldCounter, 1)
+	defer mm_atomic.AddUint64(&mmCloseHold.afterCloseHoldCounter, 1)
+
+	if mmCloseHold.inspectFuncCloseHold != nil {
+		mmCloseHold.inspectFuncCloseHold(id)
+	}
+
+	mm_params := &RepositoryMockCloseHoldParams{id}
+
+	// Record call args
+	mmCloseHold.CloseHoldMock.mutex.Lock()
+	mmCloseHold.CloseHoldMock.callArgs = append(mmCloseHold.CloseHoldMock.callArgs, mm_params)
+	mmCloseHold.CloseHoldMock.mutex.Unlock()
+
+	for _, e := range mmCloseHold.CloseHoldMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.h1, e.results.err
+		}
+	}
+
+	if mmCloseHold.CloseHoldMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmCloseHold.CloseHoldMock.defaultExpectation.Counter, 1)
+		mm_want := mmCloseHold.CloseHoldMock.defaultExpectation.params
+		mm_got := RepositoryMockCloseHoldParams{id}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmCloseHold.t.Errorf("RepositoryMock.CloseHold got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmCloseHold.CloseHoldMock.defaultExpectation.results
+		if mm_results == nil {
+			mmCloseHold.t.Fatal("No results are set for the RepositoryMock.CloseHold")
+		}
+		return (*mm_results).h1, (*mm_results).err
+	}
+	if mmCloseHold.funcCloseHold != nil {
+		return mmCloseHold.funcCloseHold(id)
+	}
+	mmCloseHold.t.Fatalf("Unexpected call to RepositoryMock.CloseHold. %v", id)
+	return
+}
+
+// CloseHoldAfterCounter returns a count of finished RepositoryMock.CloseHold invocations
+func (mmCloseHold *RepositoryMock) CloseHoldAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCloseHold.afterCloseHoldCounter)
+}
+
+// CloseHoldBeforeCounter returns a count of RepositoryMock.CloseHold invocations
+func (mmCloseHold *RepositoryMock) CloseHoldBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCloseHold.beforeCloseHoldCounter)
+}
+
+// Calls returns a list of arguments used in each call to RepositoryMock.CloseHold.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmCloseHold *mRepositoryMockCloseHold) Calls() []*RepositoryMockCloseHoldParams {
+	mmCloseHold.mutex.RLock()
+
+	argCopy := make([]*RepositoryMockCloseHoldParams, len(mmCloseHold.callArgs))
+	copy(argCopy, mmCloseHold.callArgs)
+
+	mmCloseHold.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockCloseHoldDone returns true if the count of the CloseHold invocations corresponds
+// the number of defined expectations
+func (m *RepositoryMock) MinimockCloseHoldDone() bool {
+	for _, e := range m.CloseHoldMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.CloseHoldMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCloseHoldCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcCloseHold != nil && mm_atomic.LoadUint64(&m.afterCloseHoldCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockCloseHoldInspect logs each unmet expectation
+func (m *RepositoryMock) MinimockCloseHoldInspect() {
+	for _, e := range m.CloseHoldMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to RepositoryMock.CloseHold with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.CloseHoldMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCloseHoldCounter) < 1 {
+		if m.CloseHoldMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to RepositoryMock.CloseHold")
+		} else {
+			m.t.Errorf("Expected call to RepositoryMock.CloseHold with params: %#v", *m.CloseHoldMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcCloseHold != nil && mm_atomic.LoadUint64(&m.afterCloseHoldCounter) < 1 {
+		m.t.Error("Expected call to RepositoryMock.CloseHold")
+	}
+}
+
 type mRepositoryMockCreate struct {
 	mock               *RepositoryMock
 	defaultExpectation *RepositoryMockCreateExpectation
@@ -448,9 +720,11 @@ type RepositoryMockCreateExpectation struct {
 
 // RepositoryMockCreateParams contains parameters of the WalletRepository.Create
 type RepositoryMockCreateParams struct {
-	name    string
-	balance float64
-	status  bool
+	name     string
+	balance  float64
+	status   bool
+	owner    string
+	metadata map[string]string
 }
 
 // RepositoryMockCreateResults contains results of the WalletRepository.Create
@@ -459,7 +733,7 @@ type RepositoryMockCreateResults struct {
 }
 
 // Expect sets up expected params for WalletRepository.Create
-func (mmCreate *mRepositoryMockCreate) Expect(name string, balance float64, status bool) *mRepositoryMockCreate {
+func (mmCreate *mRepositoryMockCreate) Expect(name string, balance float64, status bool, owner string, metadata map[string]string) *mRepositoryMockCreate {
 	if mmCreate.mock.funcCreate != nil {
 		mmCreate.mock.t.Fatalf("RepositoryMock.Create mock is already set by Set")
 	}
@@ -468,7 +742,7 @@ func (mmCreate *mRepositoryMockCreate) Expect(name string, balance float64, stat
 		mmCreate.defaultExpectation = &RepositoryMockCreateExpectation{}
 	}
 
-	mmCreate.defaultExpectation.params = &RepositoryMockCreateParams{name, balance, status}
+	mmCreate.defaultExpectation.params = &RepositoryMockCreateParams{name, balance, status, owner, metadata}
 	for _, e := range mmCreate.expectations {
 		if minimock.Equal(e.params, mmCreate.defaultExpectation.params) {
 			mmCreate.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmCreate.defaultExpectation.params)
@@ -479,7 +753,7 @@ func (mmCreate *mRepositoryMockCreate) Expect(name string, balance float64, stat
 }
 
 // Inspect accepts an inspector function that has same arguments as the WalletRepository.Create
-func (mmCreate *mRepositoryMockCreate) Inspect(f func(name string, balance float64, status bool)) *mRepositoryMockCreate {
+func (mmCreate *mRepositoryMockCreate) Inspect(f func(name string, balance float64, status bool, owner string, metadata map[string]string)) *mRepositoryMockCreate {
 	if mmCreate.mock.inspectFuncCreate != nil {
 		mmCreate.mock.t.Fatalf("Inspect function is already set for RepositoryMock.Create")
 	}
@@ -503,7 +777,7 @@ func (mmCreate *mRepositoryMockCreate) Return(w1 mm_models.Walleter) *Repository
 }
 
 // Set uses given function f to mock the WalletRepository.Create method
-func (mmCreate *mRepositoryMockCreate) Set(f func(name string, balance float64, status bool) (w1 mm_models.Walleter)) *RepositoryMock {
+func (mmCreate *mRepositoryMockCreate) Set(f func(name string, balance float64, status bool, owner string, metadata map[string]string) (w1 mm_models.Walleter)) *RepositoryMock {
 	if mmCreate.defaultExpectation != nil {
 		mmCreate.mock.t.Fatalf("Default expectation is already set for the WalletRepository.Create method")
 	}
@@ -518,14 +792,14 @@ func (mmCreate *mRepositoryMockCreate) Set(f func(name string, balance float64,
 
 // When sets expectation for the WalletRepository.Create which will trigger the result defined by the following
 // Then helper
-func (mmCreate *mRepositoryMockCreate) When(name string, balance float64, status bool) *RepositoryMockCreateExpectation {
+func (mmCreate *mRepositoryMockCreate) When(name string, balance float64, status bool, owner string, metadata map[string]string) *RepositoryMockCreateExpectation {
 	if mmCreate.mock.funcCreate != nil {
 		mmCreate.mock.t.Fatalf("RepositoryMock.Create mock is already set by Set")
 	}
 
 	expectation := &RepositoryMockCreateExpectation{
 		mock:   mmCreate.mock,
-		params: &RepositoryMockCreateParams{name, balance, status},
+		params: &RepositoryMockCreateParams{name, balance, status, owner, metadata},
 	}
 	mmCreate.expectations = append(mmCreate.expectations, expectation)
 	return expectation
@@ -538,15 +812,15 @@ func (e *RepositoryMockCreateExpectation) Then(w1 mm_models.Walleter) *Repositor
 }
 
 // Create implements models.WalletRepository
-func (mmCreate *RepositoryMock) Create(name string, balance float64, status bool) (w1 mm_models.Walleter) {
+func (mmCreate *RepositoryMock) Create(name string, balance float64, status bool, owner string, metadata map[string]string) (w1 mm_models.Walleter) {
 	mm_atomic.AddUint64(&mmCreate.beforeCreateCounter, 1)
 	defer mm_atomic.AddUint64(&mmCreate.afterCreateCounter, 1)
 
 	if mmCreate.inspectFuncCreate != nil {
-		mmCreate.inspectFuncCreate(name, balance, status)
+		mmCreate.inspectFuncCreate(name, balance, status, owner, metadata)
 	}
 
-	mm_params := &RepositoryMockCreateParams{name, balance, status}
+	mm_params := &RepositoryMockCreateParams{name, balance, status, owner, metadata}
 
 	// Record call args
 	mmCreate.CreateMock.mutex.Lock()
@@ -563,7 +837,7 @@ func (mmCreate *RepositoryMock) Create(name string, balance float64, status bool
 	if mmCreate.CreateMock.defaultExpectation != nil {
 		mm_atomic.AddUint64(&mmCreate.CreateMock.defaultExpectation.Counter, 1)
 		mm_want := mmCreate.CreateMock.defaultExpectation.params
-		mm_got := RepositoryMockCreateParams{name, balance, status}
+		mm_got := RepositoryMockCreateParams{name, balance, status, owner, metadata}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
 			mmCreate.t.Errorf("RepositoryMock.Create got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
@@ -575,9 +849,9 @@ func (mmCreate *RepositoryMock) Create(name string, balance float64, status bool
 		return (*mm_results).w1
 	}
 	if mmCreate.funcCreate != nil {
-		return mmCreate.funcCreate(name, balance, status)
+		return mmCreate.funcCreate(name, balance, status, owner, metadata)
 	}
-	mmCreate.t.Fatalf("Unexpected call to RepositoryMock.Create. %v %v %v", name, balance, status)
+	mmCreate.t.Fatalf("Unexpected call to RepositoryMock.Create. %v %v %v %v %v", name, balance, status, owner, metadata)
 	return
 }
 
@@ -646,319 +920,1402 @@ func (m *RepositoryMock) MinimockCreateInspect() {
 	}
 }
 
-type mRepositoryMockTransaction struct {
+type mRepositoryMockCreateHold struct {
 	mock               *RepositoryMock
-	defaultExpectation *RepositoryMockTransactionExpectation
-	expectations       []*RepositoryMockTransactionExpectation
+	defaultExpectation *RepositoryMockCreateHoldExpectation
+	expectations       []*RepositoryMockCreateHoldExpectation
 
-	callArgs []*RepositoryMockTransactionParams
+	callArgs []*RepositoryMockCreateHoldParams
 	mutex    sync.RWMutex
 }
 
-// RepositoryMockTransactionExpectation specifies expectation struct of the WalletRepository.Transaction
-type RepositoryMockTransactionExpectation struct {
+// RepositoryMockCreateHoldExpectation specifies expectation struct of the WalletRepository.CreateHold
+type RepositoryMockCreateHoldExpectation struct {
 	mock    *RepositoryMock
-	params  *RepositoryMockTransactionParams
-	results *RepositoryMockTransactionResults
+	params  *RepositoryMockCreateHoldParams
+	results *RepositoryMockCreateHoldResults
 	Counter uint64
 }
 
-// RepositoryMockTransactionParams contains parameters of the WalletRepository.Transaction
-type RepositoryMockTransactionParams struct {
-	fn func(repo mm_models.WalletRepository) error
+// RepositoryMockCreateHoldParams contains parameters of the WalletRepository.CreateHold
+type RepositoryMockCreateHoldParams struct {
+	walletID  string
+	amount    float64
+	expiresAt time.Time
 }
 
-// RepositoryMockTransactionResults contains results of the WalletRepository.Transaction
-type RepositoryMockTransactionResults struct {
+// RepositoryMockCreateHoldResults contains results of the WalletRepository.CreateHold
+type RepositoryMockCreateHoldResults struct {
+	h1  mm_models.Hold
 	err error
 }
 
-// Expect sets up expected params for WalletRepository.Transaction
-func (mmTransaction *mRepositoryMockTransaction) Expect(fn func(repo mm_models.WalletRepository) error) *mRepositoryMockTransaction {
-	if mmTransaction.mock.funcTransaction != nil {
-		mmTransaction.mock.t.Fatalf("RepositoryMock.Transaction mock is already set by Set")
+// Expect sets up expected params for WalletRepository.CreateHold
+func (mmCreateHold *mRepositoryMockCreateHold) Expect(walletID string, amount float64, expiresAt time.Time) *mRepositoryMockCreateHold {
+	if mmCreateHold.mock.funcCreateHold != nil {
+		mmCreateHold.mock.t.Fatalf("RepositoryMock.CreateHold mock is already set by Set")
 	}
 
-	if mmTransaction.defaultExpectation == nil {
-		mmTransaction.defaultExpectation = &RepositoryMockTransactionExpectation{}
+	if mmCreateHold.defaultExpectation == nil {
+		mmCreateHold.defaultExpectation = &RepositoryMockCreateHoldExpectation{}
 	}
 
-	mmTransaction.defaultExpectation.params = &RepositoryMockTransactionParams{fn}
-	for _, e := range mmTransaction.expectations {
-		if minimock.Equal(e.params, mmTransaction.defaultExpectation.params) {
-			mmTransaction.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmTransaction.defaultExpectation.params)
+	mmCreateHold.defaultExpectation.params = &RepositoryMockCreateHoldParams{walletID, amount, expiresAt}
+	for _, e := range mmCreateHold.expectations {
+		if minimock.Equal(e.params, mmCreateHold.defaultExpectation.params) {
+			mmCreateHold.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmCreateHold.defaultExpectation.params)
 		}
 	}
 
-	return mmTransaction
+	return mmCreateHold
 }
 
-// Inspect accepts an inspector function that has same arguments as the WalletRepository.Transaction
-func (mmTransaction *mRepositoryMockTransaction) Inspect(f func(fn func(repo mm_models.WalletRepository) error)) *mRepositoryMockTransaction {
-	if mmTransaction.mock.inspectFuncTransaction != nil {
-		mmTransaction.mock.t.Fatalf("Inspect function is already set for RepositoryMock.Transaction")
+// Inspect accepts an inspector function that has same arguments as the WalletRepository.CreateHold
+func (mmCreateHold *mRepositoryMockCreateHold) Inspect(f func(walletID string, amount float64, expiresAt time.Time)) *mRepositoryMockCreateHold {
+	if mmCreateHold.mock.inspectFuncCreateHold != nil {
+		mmCreateHold.mock.t.Fatalf("Inspect function is already set for RepositoryMock.CreateHold")
 	}
 
-	mmTransaction.mock.inspectFuncTransaction = f
+	mmCreateHold.mock.inspectFuncCreateHold = f
 
-	return mmTransaction
+	return mmCreateHold
 }
 
-// Return sets up results that will be returned by WalletRepository.Transaction
-func (mmTransaction *mRepositoryMockTransaction) Return(err error) *RepositoryMock {
-	if mmTransaction.mock.funcTransaction != nil {
-		mmTransaction.mock.t.Fatalf("RepositoryMock.Transaction mock is already set by Set")
+// Return sets up results that will be returned by WalletRepository.CreateHold
+func (mmCreateHold *mRepositoryMockCreateHold) Return(h1 mm_models.Hold, err error) *RepositoryMock {
+	if mmCreateHold.mock.funcCreateHold != nil {
+		mmCreateHold.mock.t.Fatalf("RepositoryMock.CreateHold mock is already set by Set")
 	}
 
-	if mmTransaction.defaultExpectation == nil {
-		mmTransaction.defaultExpectation = &RepositoryMockTransactionExpectation{mock: mmTransaction.mock}
+	if mmCreateHold.defaultExpectation == nil {
+		mmCreateHold.defaultExpectation = &RepositoryMockCreateHoldExpectation{mock: mmCreateHold.mock}
 	}
-	mmTransaction.defaultExpectation.results = &RepositoryMockTransactionResults{err}
-	return mmTransaction.mock
+	mmCreateHold.defaultExpectation.results = &RepositoryMockCreateHoldResults{h1, err}
+	return mmCreateHold.mock
 }
 
-// Set uses given function f to mock the WalletRepository.Transaction method
-func (mmTransaction *mRepositoryMockTransaction) Set(f func(fn func(repo mm_models.WalletRepository) error) (err error)) *RepositoryMock {
-	if mmTransaction.defaultExpectation != nil {
-		mmTransaction.mock.t.Fatalf("Default expectation is already set for the WalletRepository.Transaction method")
+// Set uses given function f to mock the WalletRepository.CreateHold method
+func (mmCreateHold *mRepositoryMockCreateHold) Set(f func(walletID string, amount float64, expiresAt time.Time) (h1 mm_models.Hold, err error)) *RepositoryMock {
+	if mmCreateHold.defaultExpectation != nil {
+		mmCreateHold.mock.t.Fatalf("Default expectation is already set for the WalletRepository.CreateHold method")
 	}
 
-	if len(mmTransaction.expectations) > 0 {
-		mmTransaction.mock.t.Fatalf("Some expectations are already set for the WalletRepository.Transaction method")
+	if len(mmCreateHold.expectations) > 0 {
+		mmCreateHold.mock.t.Fatalf("Some expectations are already set for the WalletRepository.CreateHold method")
 	}
 
-	mmTransaction.mock.funcTransaction = f
-	return mmTransaction.mock
+	mmCreateHold.mock.funcCreateHold = f
+	return mmCreateHold.mock
 }
 
-// When sets expectation for the WalletRepository.Transaction which will trigger the result defined by the following
+// When sets expectation for the WalletRepository.CreateHold which will trigger the result defined by the following
 // Then helper
-func (mmTransaction *mRepositoryMockTransaction) When(fn func(repo mm_models.WalletRepository) error) *RepositoryMockTransactionExpectation {
-	if mmTransaction.mock.funcTransaction != nil {
-		mmTransaction.mock.t.Fatalf("RepositoryMock.Transaction mock is already set by Set")
+func (mmCreateHold *mRepositoryMockCreateHold) When(walletID string, amount float64, expiresAt time.Time) *RepositoryMockCreateHoldExpectation {
+	if mmCreateHold.mock.funcCreateHold != nil {
+		mmCreateHold.mock.t.Fatalf("RepositoryMock.CreateHold mock is already set by Set")
 	}
 
-	expectation := &RepositoryMockTransactionExpectation{
-		mock:   mmTransaction.mock,
-		params: &RepositoryMockTransactionParams{fn},
+	expectation := &RepositoryMockCreateHoldExpectation{
+		mock:   mmCreateHold.mock,
+		params: &RepositoryMockCreateHoldParams{walletID, amount, expiresAt},
 	}
-	mmTransaction.expectations = append(mmTransaction.expectations, expectation)
+	mmCreateHold.expectations = append(mmCreateHold.expectations, expectation)
 	return expectation
 }
 
-// Then sets up WalletRepository.Transaction return parameters for the expectation previously defined by the When method
-func (e *RepositoryMockTransactionExpectation) Then(err error) *RepositoryMock {
-	e.results = &RepositoryMockTransactionResults{err}
+// Then sets up WalletRepository.CreateHold return parameters for the expectation previously defined by the When method
+func (e *RepositoryMockCreateHoldExpectation) Then(h1 mm_models.Hold, err error) *RepositoryMock {
+	e.results = &RepositoryMockCreateHoldResults{h1, err}
 	return e.mock
 }
 
-// Transaction implements models.WalletRepository
-func (mmTransaction *RepositoryMock) Transaction(fn func(repo mm_models.WalletRepository) error) (err error) {
-	mm_atomic.AddUint64(&mmTransaction.beforeTransactionCounter, 1)
-	defer mm_atomic.AddUint64(&mmTransaction.afterTransactionCounter, 1)
+// CreateHold implements models.WalletRepository
+func (mmCreateHold *RepositoryMock) CreateHold(walletID string, amount float64, expiresAt time.Time) (h1 mm_models.Hold, err error) {
+	mm_atomic.AddUint64(&mmCreateHold.beforeCreateHoldCounter, 1)
+	defer mm_atomic.AddUint64(&mmCreateHold.afterCreateHoldCounter, 1)
 
-	if mmTransaction.inspectFuncTransaction != nil {
-		mmTransaction.inspectFuncTransaction(fn)
+	if mmCreateHold.inspectFuncCreateHold != nil {
+		mmCreateHold.inspectFuncCreateHold(walletID, amount, expiresAt)
 	}
 
-	mm_params := &RepositoryMockTransactionParams{fn}
+	mm_params := &RepositoryMockCreateHoldParams{walletID, amount, expiresAt}
 
 	// Record call args
-	mmTransaction.TransactionMock.mutex.Lock()
-	mmTransaction.TransactionMock.callArgs = append(mmTransaction.TransactionMock.callArgs, mm_params)
-	mmTransaction.TransactionMock.mutex.Unlock()
+	mmCreateHold.CreateHoldMock.mutex.Lock()
+	mmCreateHold.CreateHoldMock.callArgs = append(mmCreateHold.CreateHoldMock.callArgs, mm_params)
+	mmCreateHold.CreateHoldMock.mutex.Unlock()
 
-	for _, e := range mmTransaction.TransactionMock.expectations {
+	for _, e := range mmCreateHold.CreateHoldMock.expectations {
 		if minimock.Equal(e.params, mm_params) {
 			mm_atomic.AddUint64(&e.Counter, 1)
-			return e.results.err
+			return e.results.h1, e.results.err
 		}
 	}
 
-	if mmTransaction.TransactionMock.defaultExpectation != nil {
-		mm_atomic.AddUint64(&mmTransaction.TransactionMock.defaultExpectation.Counter, 1)
-		mm_want := mmTransaction.TransactionMock.defaultExpectation.params
-		mm_got := RepositoryMockTransactionParams{fn}
+	if mmCreateHold.CreateHoldMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmCreateHold.CreateHoldMock.defaultExpectation.Counter, 1)
+		mm_want := mmCreateHold.CreateHoldMock.defaultExpectation.params
+		mm_got := RepositoryMockCreateHoldParams{walletID, amount, expiresAt}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
-			mmTransaction.t.Errorf("RepositoryMock.Transaction got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+			mmCreateHold.t.Errorf("RepositoryMock.CreateHold got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
 
-		mm_results := mmTransaction.TransactionMock.defaultExpectation.results
+		mm_results := mmCreateHold.CreateHoldMock.defaultExpectation.results
 		if mm_results == nil {
-			mmTransaction.t.Fatal("No results are set for the RepositoryMock.Transaction")
+			mmCreateHold.t.Fatal("No results are set for the RepositoryMock.CreateHold")
 		}
-		return (*mm_results).err
+		return (*mm_results).h1, (*mm_results).err
 	}
-	if mmTransaction.funcTransaction != nil {
-		return mmTransaction.funcTransaction(fn)
+	if mmCreateHold.funcCreateHold != nil {
+		return mmCreateHold.funcCreateHold(walletID, amount, expiresAt)
 	}
-	mmTransaction.t.Fatalf("Unexpected call to RepositoryMock.Transaction. %v", fn)
+	mmCreateHold.t.Fatalf("Unexpected call to RepositoryMock.CreateHold. %v %v %v", walletID, amount, expiresAt)
 	return
 }
 
-// TransactionAfterCounter returns a count of finished RepositoryMock.Transaction invocations
-func (mmTransaction *RepositoryMock) TransactionAfterCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmTransaction.afterTransactionCounter)
+// CreateHoldAfterCounter returns a count of finished RepositoryMock.CreateHold invocations
+func (mmCreateHold *RepositoryMock) CreateHoldAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCreateHold.afterCreateHoldCounter)
 }
 
-// TransactionBeforeCounter returns a count of RepositoryMock.Transaction invocations
-func (mmTransaction *RepositoryMock) TransactionBeforeCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmTransaction.beforeTransactionCounter)
+// CreateHoldBeforeCounter returns a count of RepositoryMock.CreateHold invocations
+func (mmCreateHold *RepositoryMock) CreateHoldBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCreateHold.beforeCreateHoldCounter)
 }
 
-// Calls returns a list of arguments used in each call to RepositoryMock.Transaction.
+// Calls returns a list of arguments used in each call to RepositoryMock.CreateHold.
 // The list is in the same order as the calls were made (i.e. recent calls have a higher index)
-func (mmTransaction *mRepositoryMockTransaction) Calls() []*RepositoryMockTransactionParams {
-	mmTransaction.mutex.RLock()
+func (mmCreateHold *mRepositoryMockCreateHold) Calls() []*RepositoryMockCreateHoldParams {
+	mmCreateHold.mutex.RLock()
 
-	argCopy := make([]*RepositoryMockTransactionParams, len(mmTransaction.callArgs))
-	copy(argCopy, mmTransaction.callArgs)
+	argCopy := make([]*RepositoryMockCreateHoldParams, len(mmCreateHold.callArgs))
+	copy(argCopy, mmCreateHold.callArgs)
 
-	mmTransaction.mutex.RUnlock()
+	mmCreateHold.mutex.RUnlock()
 
 	return argCopy
 }
 
-// MinimockTransactionDone returns true if the count of the Transaction invocations corresponds
+// MinimockCreateHoldDone returns true if the count of the CreateHold invocations corresponds
 // the number of defined expectations
-func (m *RepositoryMock) MinimockTransactionDone() bool {
-	for _, e := range m.TransactionMock.expectations {
+func (m *RepositoryMock) MinimockCreateHoldDone() bool {
+	for _, e := range m.CreateHoldMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
 			return false
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.TransactionMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterTransactionCounter) < 1 {
+	if m.CreateHoldMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCreateHoldCounter) < 1 {
 		return false
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcTransaction != nil && mm_atomic.LoadUint64(&m.afterTransactionCounter) < 1 {
+	if m.funcCreateHold != nil && mm_atomic.LoadUint64(&m.afterCreateHoldCounter) < 1 {
 		return false
 	}
 	return true
 }
 
-// MinimockTransactionInspect logs each unmet expectation
-func (m *RepositoryMock) MinimockTransactionInspect() {
-	for _, e := range m.TransactionMock.expectations {
+// MinimockCreateHoldInspect logs each unmet expectation
+func (m *RepositoryMock) MinimockCreateHoldInspect() {
+	for _, e := range m.CreateHoldMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
-			m.t.Errorf("Expected call to RepositoryMock.Transaction with params: %#v", *e.params)
+			m.t.Errorf("Expected call to RepositoryMock.CreateHold with params: %#v", *e.params)
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.TransactionMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterTransactionCounter) < 1 {
-		if m.TransactionMock.defaultExpectation.params == nil {
-			m.t.Error("Expected call to RepositoryMock.Transaction")
+	if m.CreateHoldMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCreateHoldCounter) < 1 {
+		if m.CreateHoldMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to RepositoryMock.CreateHold")
 		} else {
-			m.t.Errorf("Expected call to RepositoryMock.Transaction with params: %#v", *m.TransactionMock.defaultExpectation.params)
+			m.t.Errorf("Expected call to RepositoryMock.CreateHold with params: %#v", *m.CreateHoldMock.defaultExpectation.params)
 		}
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcTransaction != nil && mm_atomic.LoadUint64(&m.afterTransactionCounter) < 1 {
-		m.t.Error("Expected call to RepositoryMock.Transaction")
+	if m.funcCreateHold != nil && mm_atomic.LoadUint64(&m.afterCreateHoldCounter) < 1 {
+		m.t.Error("Expected call to RepositoryMock.CreateHold")
 	}
 }
 
-type mRepositoryMockUpdateByID struct {
+type mRepositoryMockHoldByID struct {
 	mock               *RepositoryMock
-	defaultExpectation *RepositoryMockUpdateByIDExpectation
-	expectations       []*RepositoryMockUpdateByIDExpectation
+	defaultExpectation *RepositoryMockHoldByIDExpectation
+	expectations       []*RepositoryMockHoldByIDExpectation
 
-	callArgs []*RepositoryMockUpdateByIDParams
+	callArgs []*RepositoryMockHoldByIDParams
 	mutex    sync.RWMutex
 }
 
-// RepositoryMockUpdateByIDExpectation specifies expectation struct of the WalletRepository.UpdateByID
-type RepositoryMockUpdateByIDExpectation struct {
+// RepositoryMockHoldByIDExpectation specifies expectation struct of the WalletRepository.HoldByID
+type RepositoryMockHoldByIDExpectation struct {
 	mock    *RepositoryMock
-	params  *RepositoryMockUpdateByIDParams
-	results *RepositoryMockUpdateByIDResults
+	params  *RepositoryMockHoldByIDParams
+	results *RepositoryMockHoldByIDResults
 	Counter uint64
 }
 
-// RepositoryMockUpdateByIDParams contains parameters of the WalletRepository.UpdateByID
-type RepositoryMockUpdateByIDParams struct {
-	id      string
-	name    *string
-	balance *float64
-	status  *bool
+// RepositoryMockHoldByIDParams contains parameters of the WalletRepository.HoldByID
+type RepositoryMockHoldByIDParams struct {
+	id string
 }
 
-// RepositoryMockUpdateByIDResults contains results of the WalletRepository.UpdateByID
-type RepositoryMockUpdateByIDResults struct {
+// RepositoryMockHoldByIDResults contains results of the WalletRepository.HoldByID
+type RepositoryMockHoldByIDResults struct {
+	h1  mm_models.Hold
 	err error
 }
 
-// Expect sets up expected params for WalletRepository.UpdateByID
-func (mmUpdateByID *mRepositoryMockUpdateByID) Expect(id string, name *string, balance *float64, status *bool) *mRepositoryMockUpdateByID {
-	if mmUpdateByID.mock.funcUpdateByID != nil {
-		mmUpdateByID.mock.t.Fatalf("RepositoryMock.UpdateByID mock is already set by Set")
+// Expect sets up expected params for WalletRepository.HoldByID
+func (mmHoldByID *mRepositoryMockHoldByID) Expect(id string) *mRepositoryMockHoldByID {
+	if mmHoldByID.mock.funcHoldByID != nil {
+		mmHoldByID.mock.t.Fatalf("RepositoryMock.HoldByID mock is already set by Set")
 	}
 
-	if mmUpdateByID.defaultExpectation == nil {
-		mmUpdateByID.defaultExpectation = &RepositoryMockUpdateByIDExpectation{}
+	if mmHoldByID.defaultExpectation == nil {
+		mmHoldByID.defaultExpectation = &RepositoryMockHoldByIDExpectation{}
 	}
 
-	mmUpdateByID.defaultExpectation.params = &RepositoryMockUpdateByIDParams{id, name, balance, status}
-	for _, e := range mmUpdateByID.expectations {
-		if minimock.Equal(e.params, mmUpdateByID.defaultExpectation.params) {
-			mmUpdateByID.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUpdateByID.defaultExpectation.params)
+	mmHoldByID.defaultExpectation.params = &RepositoryMockHoldByIDParams{id}
+	for _, e := range mmHoldByID.expectations {
+		if minimock.Equal(e.params, mmHoldByID.defaultExpectation.params) {
+			mmHoldByID.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmHoldByID.defaultExpectation.params)
 		}
 	}
 
-	return mmUpdateByID
+	return mmHoldByID
 }
 
-// Inspect accepts an inspector function that has same arguments as the WalletRepository.UpdateByID
-func (mmUpdateByID *mRepositoryMockUpdateByID) Inspect(f func(id string, name *string, balance *float64, status *bool)) *mRepositoryMockUpdateByID {
-	if mmUpdateByID.mock.inspectFuncUpdateByID != nil {
-		mmUpdateByID.mock.t.Fatalf("Inspect function is already set for RepositoryMock.UpdateByID")
+// Inspect accepts an inspector function that has same arguments as the WalletRepository.HoldByID
+func (mmHoldByID *mRepositoryMockHoldByID) Inspect(f func(id string)) *mRepositoryMockHoldByID {
+	if mmHoldByID.mock.inspectFuncHoldByID != nil {
+		mmHoldByID.mock.t.Fatalf("Inspect function is already set for RepositoryMock.HoldByID")
 	}
 
-	mmUpdateByID.mock.inspectFuncUpdateByID = f
+	mmHoldByID.mock.inspectFuncHoldByID = f
 
-	return mmUpdateByID
+	return mmHoldByID
 }
 
-// Return sets up results that will be returned by WalletRepository.UpdateByID
-func (mmUpdateByID *mRepositoryMockUpdateByID) Return(err error) *RepositoryMock {
-	if mmUpdateByID.mock.funcUpdateByID != nil {
-		mmUpdateByID.mock.t.Fatalf("RepositoryMock.UpdateByID mock is already set by Set")
+// Return sets up results that will be returned by WalletRepository.HoldByID
+func (mmHoldByID *mRepositoryMockHoldByID) Return(h1 mm_models.Hold, err error) *RepositoryMock {
+	if mmHoldByID.mock.funcHoldByID != nil {
+		mmHoldByID.mock.t.Fatalf("RepositoryMock.HoldByID mock is already set by Set")
 	}
 
-	if mmUpdateByID.defaultExpectation == nil {
-		mmUpdateByID.defaultExpectation = &RepositoryMockUpdateByIDExpectation{mock: mmUpdateByID.mock}
+	if mmHoldByID.defaultExpectation == nil {
+		mmHoldByID.defaultExpectation = &RepositoryMockHoldByIDExpectation{mock: mmHoldByID.mock}
 	}
-	mmUpdateByID.defaultExpectation.results = &RepositoryMockUpdateByIDResults{err}
-	return mmUpdateByID.mock
+	mmHoldByID.defaultExpectation.results = &RepositoryMockHoldByIDResults{h1, err}
+	return mmHoldByID.mock
 }
 
-// Set uses given function f to mock the WalletRepository.UpdateByID method
-func (mmUpdateByID *mRepositoryMockUpdateByID) Set(f func(id string, name *string, balance *float64, status *bool) (err error)) *RepositoryMock {
-	if mmUpdateByID.defaultExpectation != nil {
-		mmUpdateByID.mock.t.Fatalf("Default expectation is already set for the WalletRepository.UpdateByID method")
+// Set uses given function f to mock the WalletRepository.HoldByID method
+func (mmHoldByID *mRepositoryMockHoldByID) Set(f func(id string) (h1 mm_models.Hold, err error)) *RepositoryMock {
+	if mmHoldByID.defaultExpectation != nil {
+		mmHoldByID.mock.t.Fatalf("Default expectation is already set for the WalletRepository.HoldByID method")
 	}
 
-	if len(mmUpdateByID.expectations) > 0 {
-		mmUpdateByID.mock.t.Fatalf("Some expectations are already set for the WalletRepository.UpdateByID method")
+	if len(mmHoldByID.expectations) > 0 {
+		mmHoldByID.mock.t.Fatalf("Some expectations are already set for the WalletRepository.HoldByID method")
 	}
 
-	mmUpdateByID.mock.funcUpdateByID = f
-	return mmUpdateByID.mock
+	mmHoldByID.mock.funcHoldByID = f
+	return mmHoldByID.mock
 }
 
-// When sets expectation for the WalletRepository.UpdateByID which will trigger the result defined by the following
+// When sets expectation for the WalletRepository.HoldByID which will trigger the result defined by the following
 // Then helper
-func (mmUpdateByID *mRepositoryMockUpdateByID) When(id string, name *string, balance *float64, status *bool) *RepositoryMockUpdateByIDExpectation {
-	if mmUpdateByID.mock.funcUpdateByID != nil {
-		mmUpdateByID.mock.t.Fatalf("RepositoryMock.UpdateByID mock is already set by Set")
+func (mmHoldByID *mRepositoryMockHoldByID) When(id string) *RepositoryMockHoldByIDExpectation {
+	if mmHoldByID.mock.funcHoldByID != nil {
+		mmHoldByID.mock.t.Fatalf("RepositoryMock.HoldByID mock is already set by Set")
 	}
 
-	expectation := &RepositoryMockUpdateByIDExpectation{
+	expectation := &RepositoryMockHoldByIDExpectation{
+		mock:   mmHoldByID.mock,
+		params: &RepositoryMockHoldByIDParams{id},
+	}
+	mmHoldByID.expectations = append(mmHoldByID.expectations, expectation)
+	return expectation
+}
+
+// Then sets up WalletRepository.HoldByID return parameters for the expectation previously defined by the When method
+func (e *RepositoryMockHoldByIDExpectation) Then(h1 mm_models.Hold, err error) *RepositoryMock {
+	e.results = &RepositoryMockHoldByIDResults{h1, err}
+	return e.mock
+}
+
+// HoldByID implements models.WalletRepository
+func (mmHoldByID *RepositoryMock) HoldByID(id string) (h1 mm_models.Hold, err error) {
+	mm_atomic.AddUint64(&mmHoldByID.beforeHoldByIDCounter, 1)
+	defer mm_atomic.AddUint64(&mmHoldByID.afterHoldByIDCounter, 1)
+
+	if mmHoldByID.inspectFuncHoldByID != nil {
+		mmHoldByID.inspectFuncHoldByID(id)
+	}
+
+	mm_params := &RepositoryMockHoldByIDParams{id}
+
+	// Record call args
+	mmHoldByID.HoldByIDMock.mutex.Lock()
+	mmHoldByID.HoldByIDMock.callArgs = append(mmHoldByID.HoldByIDMock.callArgs, mm_params)
+	mmHoldByID.HoldByIDMock.mutex.Unlock()
+
+	for _, e := range mmHoldByID.HoldByIDMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.h1, e.results.err
+		}
+	}
+
+	if mmHoldByID.HoldByIDMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmHoldByID.HoldByIDMock.defaultExpectation.Counter, 1)
+		mm_want := mmHoldByID.HoldByIDMock.defaultExpectation.params
+		mm_got := RepositoryMockHoldByIDParams{id}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmHoldByID.t.Errorf("RepositoryMock.HoldByID got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmHoldByID.HoldByIDMock.defaultExpectation.results
+		if mm_results == nil {
+			mmHoldByID.t.Fatal("No results are set for the RepositoryMock.HoldByID")
+		}
+		return (*mm_results).h1, (*mm_results).err
+	}
+	if mmHoldByID.funcHoldByID != nil {
+		return mmHoldByID.funcHoldByID(id)
+	}
+	mmHoldByID.t.Fatalf("Unexpected call to RepositoryMock.HoldByID. %v", id)
+	return
+}
+
+// HoldByIDAfterCounter returns a count of finished RepositoryMock.HoldByID invocations
+func (mmHoldByID *RepositoryMock) HoldByIDAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmHoldByID.afterHoldByIDCounter)
+}
+
+// HoldByIDBeforeCounter returns a count of RepositoryMock.HoldByID invocations
+func (mmHoldByID *RepositoryMock) HoldByIDBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmHoldByID.beforeHoldByIDCounter)
+}
+
+// Calls returns a list of arguments used in each call to RepositoryMock.HoldByID.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmHoldByID *mRepositoryMockHoldByID) Calls() []*RepositoryMockHoldByIDParams {
+	mmHoldByID.mutex.RLock()
+
+	argCopy := make([]*RepositoryMockHoldByIDParams, len(mmHoldByID.callArgs))
+	copy(argCopy, mmHoldByID.callArgs)
+
+	mmHoldByID.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockHoldByIDDone returns true if the count of the HoldByID invocations corresponds
+// the number of defined expectations
+func (m *RepositoryMock) MinimockHoldByIDDone() bool {
+	for _, e := range m.HoldByIDMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.HoldByIDMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterHoldByIDCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcHoldByID != nil && mm_atomic.LoadUint64(&m.afterHoldByIDCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockHoldByIDInspect logs each unmet expectation
+func (m *RepositoryMock) MinimockHoldByIDInspect() {
+	for _, e := range m.HoldByIDMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to RepositoryMock.HoldByID with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.HoldByIDMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterHoldByIDCounter) < 1 {
+		if m.HoldByIDMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to RepositoryMock.HoldByID")
+		} else {
+			m.t.Errorf("Expected call to RepositoryMock.HoldByID with params: %#v", *m.HoldByIDMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcHoldByID != nil && mm_atomic.LoadUint64(&m.afterHoldByIDCounter) < 1 {
+		m.t.Error("Expected call to RepositoryMock.HoldByID")
+	}
+}
+
+type mRepositoryMockLedger struct {
+	mock               *RepositoryMock
+	defaultExpectation *RepositoryMockLedgerExpectation
+	expectations       []*RepositoryMockLedgerExpectation
+
+	callArgs []*RepositoryMockLedgerParams
+	mutex    sync.RWMutex
+}
+
+// RepositoryMockLedgerExpectation specifies expectation struct of the WalletRepository.Ledger
+type RepositoryMockLedgerExpectation struct {
+	mock    *RepositoryMock
+	params  *RepositoryMockLedgerParams
+	results *RepositoryMockLedgerResults
+	Counter uint64
+}
+
+// RepositoryMockLedgerParams contains parameters of the WalletRepository.Ledger
+type RepositoryMockLedgerParams struct {
+	filter mm_models.LedgerFilter
+}
+
+// RepositoryMockLedgerResults contains results of the WalletRepository.Ledger
+type RepositoryMockLedgerResults struct {
+	la1 []mm_models.LedgerEntry
+	err error
+}
+
+// Expect sets up expected params for WalletRepository.Ledger
+func (mmLedger *mRepositoryMockLedger) Expect(filter mm_models.LedgerFilter) *mRepositoryMockLedger {
+	if mmLedger.mock.funcLedger != nil {
+		mmLedger.mock.t.Fatalf("RepositoryMock.Ledger mock is already set by Set")
+	}
+
+	if mmLedger.defaultExpectation == nil {
+		mmLedger.defaultExpectation = &RepositoryMockLedgerExpectation{}
+	}
+
+	mmLedger.defaultExpectation.params = &RepositoryMockLedgerParams{filter}
+	for _, e := range mmLedger.expectations {
+		if minimock.Equal(e.params, mmLedger.defaultExpectation.params) {
+			mmLedger.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmLedger.defaultExpectation.params)
+		}
+	}
+
+	return mmLedger
+}
+
+// Inspect accepts an inspector function that has same arguments as the WalletRepository.Ledger
+func (mmLedger *mRepositoryMockLedger) Inspect(f func(filter mm_models.LedgerFilter)) *mRepositoryMockLedger {
+	if mmLedger.mock.inspectFuncLedger != nil {
+		mmLedger.mock.t.Fatalf("Inspect function is already set for RepositoryMock.Ledger")
+	}
+
+	mmLedger.mock.inspectFuncLedger = f
+
+	return mmLedger
+}
+
+// Return sets up results that will be returned by WalletRepository.Ledger
+func (mmLedger *mRepositoryMockLedger) Return(la1 []mm_models.LedgerEntry, err error) *RepositoryMock {
+	if mmLedger.mock.funcLedger != nil {
+		mmLedger.mock.t.Fatalf("RepositoryMock.Ledger mock is already set by Set")
+	}
+
+	if mmLedger.defaultExpectation == nil {
+		mmLedger.defaultExpectation = &RepositoryMockLedgerExpectation{mock: mmLedger.mock}
+	}
+	mmLedger.defaultExpectation.results = &RepositoryMockLedgerResults{la1, err}
+	return mmLedger.mock
+}
+
+// Set uses given function f to mock the WalletRepository.Ledger method
+func (mmLedger *mRepositoryMockLedger) Set(f func(filter mm_models.LedgerFilter) (la1 []mm_models.LedgerEntry, err error)) *RepositoryMock {
+	if mmLedger.defaultExpectation != nil {
+		mmLedger.mock.t.Fatalf("Default expectation is already set for the WalletRepository.Ledger method")
+	}
+
+	if len(mmLedger.expectations) > 0 {
+		mmLedger.mock.t.Fatalf("Some expectations are already set for the WalletRepository.Ledger method")
+	}
+
+	mmLedger.mock.funcLedger = f
+	return mmLedger.mock
+}
+
+// When sets expectation for the WalletRepository.Ledger which will trigger the result defined by the following
+// Then helper
+func (mmLedger *mRepositoryMockLedger) When(filter mm_models.LedgerFilter) *RepositoryMockLedgerExpectation {
+	if mmLedger.mock.funcLedger != nil {
+		mmLedger.mock.t.Fatalf("RepositoryMock.Ledger mock is already set by Set")
+	}
+
+	expectation := &RepositoryMockLedgerExpectation{
+		mock:   mmLedger.mock,
+		params: &RepositoryMockLedgerParams{filter},
+	}
+	mmLedger.expectations = append(mmLedger.expectations, expectation)
+	return expectation
+}
+
+// Then sets up WalletRepository.Ledger return parameters for the expectation previously defined by the When method
+func (e *RepositoryMockLedgerExpectation) Then(la1 []mm_models.LedgerEntry, err error) *RepositoryMock {
+	e.results = &RepositoryMockLedgerResults{la1, err}
+	return e.mock
+}
+
+// Ledger implements models.WalletRepository
+func (mmLedger *RepositoryMock) Ledger(filter mm_models.LedgerFilter) (la1 []mm_models.LedgerEntry, err error) {
+	mm_atomic.AddUint64(&mmLedger.beforeLedgerCounter, 1)
+	defer mm_atomic.AddUint64(&mmLedger.afterLedgerCounter, 1)
+
+	if mmLedger.inspectFuncLedger != nil {
+		mmLedger.inspectFuncLedger(filter)
+	}
+
+	mm_params := &RepositoryMockLedgerParams{filter}
+
+	// Record call args
+	mmLedger.LedgerMock.mutex.Lock()
+	mmLedger.LedgerMock.callArgs = append(mmLedger.LedgerMock.callArgs, mm_params)
+	mmLedger.LedgerMock.mutex.Unlock()
+
+	for _, e := range mmLedger.LedgerMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.la1, e.results.err
+		}
+	}
+
+	if mmLedger.LedgerMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmLedger.LedgerMock.defaultExpectation.Counter, 1)
+		mm_want := mmLedger.LedgerMock.defaultExpectation.params
+		mm_got := RepositoryMockLedgerParams{filter}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmLedger.t.Errorf("RepositoryMock.Ledger got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmLedger.LedgerMock.defaultExpectation.results
+		if mm_results == nil {
+			mmLedger.t.Fatal("No results are set for the RepositoryMock.Ledger")
+		}
+		return (*mm_results).la1, (*mm_results).err
+	}
+	if mmLedger.funcLedger != nil {
+		return mmLedger.funcLedger(filter)
+	}
+	mmLedger.t.Fatalf("Unexpected call to RepositoryMock.Ledger. %v", filter)
+	return
+}
+
+// LedgerAfterCounter returns a count of finished RepositoryMock.Ledger invocations
+func (mmLedger *RepositoryMock) LedgerAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmLedger.afterLedgerCounter)
+}
+
+// LedgerBeforeCounter returns a count of RepositoryMock.Ledger invocations
+func (mmLedger *RepositoryMock) LedgerBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmLedger.beforeLedgerCounter)
+}
+
+// Calls returns a list of arguments used in each call to RepositoryMock.Ledger.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmLedger *mRepositoryMockLedger) Calls() []*RepositoryMockLedgerParams {
+	mmLedger.mutex.RLock()
+
+	argCopy := make([]*RepositoryMockLedgerParams, len(mmLedger.callArgs))
+	copy(argCopy, mmLedger.callArgs)
+
+	mmLedger.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockLedgerDone returns true if the count of the Ledger invocations corresponds
+// the number of defined expectations
+func (m *RepositoryMock) MinimockLedgerDone() bool {
+	for _, e := range m.LedgerMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.LedgerMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterLedgerCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcLedger != nil && mm_atomic.LoadUint64(&m.afterLedgerCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockLedgerInspect logs each unmet expectation
+func (m *RepositoryMock) MinimockLedgerInspect() {
+	for _, e := range m.LedgerMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to RepositoryMock.Ledger with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.LedgerMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterLedgerCounter) < 1 {
+		if m.LedgerMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to RepositoryMock.Ledger")
+		} else {
+			m.t.Errorf("Expected call to RepositoryMock.Ledger with params: %#v", *m.LedgerMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcLedger != nil && mm_atomic.LoadUint64(&m.afterLedgerCounter) < 1 {
+		m.t.Error("Expected call to RepositoryMock.Ledger")
+	}
+}
+
+type mRepositoryMockList struct {
+	mock               *RepositoryMock
+	defaultExpectation *RepositoryMockListExpectation
+	expectations       []*RepositoryMockListExpectation
+
+	callArgs []*RepositoryMockListParams
+	mutex    sync.RWMutex
+}
+
+// RepositoryMockListExpectation specifies expectation struct of the WalletRepository.List
+type RepositoryMockListExpectation struct {
+	mock    *RepositoryMock
+	params  *RepositoryMockListParams
+	results *RepositoryMockListResults
+	Counter uint64
+}
+
+// RepositoryMockListParams contains parameters of the WalletRepository.List
+type RepositoryMockListParams struct {
+	filter mm_models.WalletFilter
+}
+
+// RepositoryMockListResults contains results of the WalletRepository.List
+type RepositoryMockListResults struct {
+	wp1 *mm_models.WalletPage
+	err error
+}
+
+// Expect sets up expected params for WalletRepository.List
+func (mmList *mRepositoryMockList) Expect(filter mm_models.WalletFilter) *mRepositoryMockList {
+	if mmList.mock.funcList != nil {
+		mmList.mock.t.Fatalf("RepositoryMock.List mock is already set by Set")
+	}
+
+	if mmList.defaultExpectation == nil {
+		mmList.defaultExpectation = &RepositoryMockListExpectation{}
+	}
+
+	mmList.defaultExpectation.params = &RepositoryMockListParams{filter}
+	for _, e := range mmList.expectations {
+		if minimock.Equal(e.params, mmList.defaultExpectation.params) {
+			mmList.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmList.defaultExpectation.params)
+		}
+	}
+
+	return mmList
+}
+
+// Inspect accepts an inspector function that has same arguments as the WalletRepository.List
+func (mmList *mRepositoryMockList) Inspect(f func(filter mm_models.WalletFilter)) *mRepositoryMockList {
+	if mmList.mock.inspectFuncList != nil {
+		mmList.mock.t.Fatalf("Inspect function is already set for RepositoryMock.List")
+	}
+
+	mmList.mock.inspectFuncList = f
+
+	return mmList
+}
+
+// Return sets up results that will be returned by WalletRepository.List
+func (mmList *mRepositoryMockList) Return(wp1 *mm_models.WalletPage, err error) *RepositoryMock {
+	if mmList.mock.funcList != nil {
+		mmList.mock.t.Fatalf("RepositoryMock.List mock is already set by Set")
+	}
+
+	if mmList.defaultExpectation == nil {
+		mmList.defaultExpectation = &RepositoryMockListExpectation{mock: mmList.mock}
+	}
+	mmList.defaultExpectation.results = &RepositoryMockListResults{wp1, err}
+	return mmList.mock
+}
+
+// Set uses given function f to mock the WalletRepository.List method
+func (mmList *mRepositoryMockList) Set(f func(filter mm_models.WalletFilter) (wp1 *mm_models.WalletPage, err error)) *RepositoryMock {
+	if mmList.defaultExpectation != nil {
+		mmList.mock.t.Fatalf("Default expectation is already set for the WalletRepository.List method")
+	}
+
+	if len(mmList.expectations) > 0 {
+		mmList.mock.t.Fatalf("Some expectations are already set for the WalletRepository.List method")
+	}
+
+	mmList.mock.funcList = f
+	return mmList.mock
+}
+
+// When sets expectation for the WalletRepository.List which will trigger the result defined by the following
+// Then helper
+func (mmList *mRepositoryMockList) When(filter mm_models.WalletFilter) *RepositoryMockListExpectation {
+	if mmList.mock.funcList != nil {
+		mmList.mock.t.Fatalf("RepositoryMock.List mock is already set by Set")
+	}
+
+	expectation := &RepositoryMockListExpectation{
+		mock:   mmList.mock,
+		params: &RepositoryMockListParams{filter},
+	}
+	mmList.expectations = append(mmList.expectations, expectation)
+	return expectation
+}
+
+// Then sets up WalletRepository.List return parameters for the expectation previously defined by the When method
+func (e *RepositoryMockListExpectation) Then(wp1 *mm_models.WalletPage, err error) *RepositoryMock {
+	e.results = &RepositoryMockListResults{wp1, err}
+	return e.mock
+}
+
+// List implements models.WalletRepository
+func (mmList *RepositoryMock) List(filter mm_models.WalletFilter) (wp1 *mm_models.WalletPage, err error) {
+	mm_atomic.AddUint64(&mmList.beforeListCounter, 1)
+	defer mm_atomic.AddUint64(&mmList.afterListCounter, 1)
+
+	if mmList.inspectFuncList != nil {
+		mmList.inspectFuncList(filter)
+	}
+
+	mm_params := &RepositoryMockListParams{filter}
+
+	// Record call args
+	mmList.ListMock.mutex.Lock()
+	mmList.ListMock.callArgs = append(mmList.ListMock.callArgs, mm_params)
+	mmList.ListMock.mutex.Unlock()
+
+	for _, e := range mmList.ListMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.wp1, e.results.err
+		}
+	}
+
+	if mmList.ListMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmList.ListMock.defaultExpectation.Counter, 1)
+		mm_want := mmList.ListMock.defaultExpectation.params
+		mm_got := RepositoryMockListParams{filter}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmList.t.Errorf("RepositoryMock.List got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmList.ListMock.defaultExpectation.results
+		if mm_results == nil {
+			mmList.t.Fatal("No results are set for the RepositoryMock.List")
+		}
+		return (*mm_results).wp1, (*mm_results).err
+	}
+	if mmList.funcList != nil {
+		return mmList.funcList(filter)
+	}
+	mmList.t.Fatalf("Unexpected call to RepositoryMock.List. %v", filter)
+	return
+}
+
+// ListAfterCounter returns a count of finished RepositoryMock.List invocations
+func (mmList *RepositoryMock) ListAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmList.afterListCounter)
+}
+
+// ListBeforeCounter returns a count of RepositoryMock.List invocations
+func (mmList *RepositoryMock) ListBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmList.beforeListCounter)
+}
+
+// Calls returns a list of arguments used in each call to RepositoryMock.List.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmList *mRepositoryMockList) Calls() []*RepositoryMockListParams {
+	mmList.mutex.RLock()
+
+	argCopy := make([]*RepositoryMockListParams, len(mmList.callArgs))
+	copy(argCopy, mmList.callArgs)
+
+	mmList.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockListDone returns true if the count of the List invocations corresponds
+// the number of defined expectations
+func (m *RepositoryMock) MinimockListDone() bool {
+	for _, e := range m.ListMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ListMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterListCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcList != nil && mm_atomic.LoadUint64(&m.afterListCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockListInspect logs each unmet expectation
+func (m *RepositoryMock) MinimockListInspect() {
+	for _, e := range m.ListMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to RepositoryMock.List with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ListMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterListCounter) < 1 {
+		if m.ListMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to RepositoryMock.List")
+		} else {
+			m.t.Errorf("Expected call to RepositoryMock.List with params: %#v", *m.ListMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcList != nil && mm_atomic.LoadUint64(&m.afterListCounter) < 1 {
+		m.t.Error("Expected call to RepositoryMock.List")
+	}
+}
+
+type mRepositoryMockRecordOperation struct {
+	mock               *RepositoryMock
+	defaultExpectation *RepositoryMockRecordOperationExpectation
+	expectations       []*RepositoryMockRecordOperationExpectation
+
+	callArgs []*RepositoryMockRecordOperationParams
+	mutex    sync.RWMutex
+}
+
+// RepositoryMockRecordOperationExpectation specifies expectation struct of the WalletRepository.RecordOperation
+type RepositoryMockRecordOperationExpectation struct {
+	mock    *RepositoryMock
+	params  *RepositoryMockRecordOperationParams
+	results *RepositoryMockRecordOperationResults
+	Counter uint64
+}
+
+// RepositoryMockRecordOperationParams contains parameters of the WalletRepository.RecordOperation
+type RepositoryMockRecordOperationParams struct {
+	opType  mm_models.OperationType
+	entries []mm_models.LedgerEntry
+}
+
+// RepositoryMockRecordOperationResults contains results of the WalletRepository.RecordOperation
+type RepositoryMockRecordOperationResults struct {
+	s1  string
+	err error
+}
+
+// Expect sets up expected params for WalletRepository.RecordOperation
+func (mmRecordOperation *mRepositoryMockRecordOperation) Expect(opType mm_models.OperationType, entries []mm_models.LedgerEntry) *mRepositoryMockRecordOperation {
+	if mmRecordOperation.mock.funcRecordOperation != nil {
+		mmRecordOperation.mock.t.Fatalf("RepositoryMock.RecordOperation mock is already set by Set")
+	}
+
+	if mmRecordOperation.defaultExpectation == nil {
+		mmRecordOperation.defaultExpectation = &RepositoryMockRecordOperationExpectation{}
+	}
+
+	mmRecordOperation.defaultExpectation.params = &RepositoryMockRecordOperationParams{opType, entries}
+	for _, e := range mmRecordOperation.expectations {
+		if minimock.Equal(e.params, mmRecordOperation.defaultExpectation.params) {
+			mmRecordOperation.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmRecordOperation.defaultExpectation.params)
+		}
+	}
+
+	return mmRecordOperation
+}
+
+// Inspect accepts an inspector function that has same arguments as the WalletRepository.RecordOperation
+func (mmRecordOperation *mRepositoryMockRecordOperation) Inspect(f func(opType mm_models.OperationType, entries []mm_models.LedgerEntry)) *mRepositoryMockRecordOperation {
+	if mmRecordOperation.mock.inspectFuncRecordOperation != nil {
+		mmRecordOperation.mock.t.Fatalf("Inspect function is already set for RepositoryMock.RecordOperation")
+	}
+
+	mmRecordOperation.mock.inspectFuncRecordOperation = f
+
+	return mmRecordOperation
+}
+
+// Return sets up results that will be returned by WalletRepository.RecordOperation
+func (mmRecordOperation *mRepositoryMockRecordOperation) Return(s1 string, err error) *RepositoryMock {
+	if mmRecordOperation.mock.funcRecordOperation != nil {
+		mmRecordOperation.mock.t.Fatalf("RepositoryMock.RecordOperation mock is already set by Set")
+	}
+
+	if mmRecordOperation.defaultExpectation == nil {
+		mmRecordOperation.defaultExpectation = &RepositoryMockRecordOperationExpectation{mock: mmRecordOperation.mock}
+	}
+	mmRecordOperation.defaultExpectation.results = &RepositoryMockRecordOperationResults{s1, err}
+	return mmRecordOperation.mock
+}
+
+// Set uses given function f to mock the WalletRepository.RecordOperation method
+func (mmRecordOperation *mRepositoryMockRecordOperation) Set(f func(opType mm_models.OperationType, entries []mm_models.LedgerEntry) (s1 string, err error)) *RepositoryMock {
+	if mmRecordOperation.defaultExpectation != nil {
+		mmRecordOperation.mock.t.Fatalf("Default expectation is already set for the WalletRepository.RecordOperation method")
+	}
+
+	if len(mmRecordOperation.expectations) > 0 {
+		mmRecordOperation.mock.t.Fatalf("Some expectations are already set for the WalletRepository.RecordOperation method")
+	}
+
+	mmRecordOperation.mock.funcRecordOperation = f
+	return mmRecordOperation.mock
+}
+
+// When sets expectation for the WalletRepository.RecordOperation which will trigger the result defined by the following
+// Then helper
+func (mmRecordOperation *mRepositoryMockRecordOperation) When(opType mm_models.OperationType, entries []mm_models.LedgerEntry) *RepositoryMockRecordOperationExpectation {
+	if mmRecordOperation.mock.funcRecordOperation != nil {
+		mmRecordOperation.mock.t.Fatalf("RepositoryMock.RecordOperation mock is already set by Set")
+	}
+
+	expectation := &RepositoryMockRecordOperationExpectation{
+		mock:   mmRecordOperation.mock,
+		params: &RepositoryMockRecordOperationParams{opType, entries},
+	}
+	mmRecordOperation.expectations = append(mmRecordOperation.expectations, expectation)
+	return expectation
+}
+
+// Then sets up WalletRepository.RecordOperation return parameters for the expectation previously defined by the When method
+func (e *RepositoryMockRecordOperationExpectation) Then(s1 string, err error) *RepositoryMock {
+	e.results = &RepositoryMockRecordOperationResults{s1, err}
+	return e.mock
+}
+
+// RecordOperation implements models.WalletRepository
+func (mmRecordOperation *RepositoryMock) RecordOperation(opType mm_models.OperationType, entries []mm_models.LedgerEntry) (s1 string, err error) {
+	mm_atomic.AddUint64(&mmRecordOperation.beforeRecordOperationCounter, 1)
+	defer mm_atomic.AddUint64(&mmRecordOperation.afterRecordOperationCounter, 1)
+
+	if mmRecordOperation.inspectFuncRecordOperation != nil {
+		mmRecordOperation.inspectFuncRecordOperation(opType, entries)
+	}
+
+	mm_params := &RepositoryMockRecordOperationParams{opType, entries}
+
+	// Record call args
+	mmRecordOperation.RecordOperationMock.mutex.Lock()
+	mmRecordOperation.RecordOperationMock.callArgs = append(mmRecordOperation.RecordOperationMock.callArgs, mm_params)
+	mmRecordOperation.RecordOperationMock.mutex.Unlock()
+
+	for _, e := range mmRecordOperation.RecordOperationMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.s1, e.results.err
+		}
+	}
+
+	if mmRecordOperation.RecordOperationMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmRecordOperation.RecordOperationMock.defaultExpectation.Counter, 1)
+		mm_want := mmRecordOperation.RecordOperationMock.defaultExpectation.params
+		mm_got := RepositoryMockRecordOperationParams{opType, entries}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmRecordOperation.t.Errorf("RepositoryMock.RecordOperation got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmRecordOperation.RecordOperationMock.defaultExpectation.results
+		if mm_results == nil {
+			mmRecordOperation.t.Fatal("No results are set for the RepositoryMock.RecordOperation")
+		}
+		return (*mm_results).s1, (*mm_results).err
+	}
+	if mmRecordOperation.funcRecordOperation != nil {
+		return mmRecordOperation.funcRecordOperation(opType, entries)
+	}
+	mmRecordOperation.t.Fatalf("Unexpected call to RepositoryMock.RecordOperation. %v %v", opType, entries)
+	return
+}
+
+// RecordOperationAfterCounter returns a count of finished RepositoryMock.RecordOperation invocations
+func (mmRecordOperation *RepositoryMock) RecordOperationAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmRecordOperation.afterRecordOperationCounter)
+}
+
+// RecordOperationBeforeCounter returns a count of RepositoryMock.RecordOperation invocations
+func (mmRecordOperation *RepositoryMock) RecordOperationBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmRecordOperation.beforeRecordOperationCounter)
+}
+
+// Calls returns a list of arguments used in each call to RepositoryMock.RecordOperation.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmRecordOperation *mRepositoryMockRecordOperation) Calls() []*RepositoryMockRecordOperationParams {
+	mmRecordOperation.mutex.RLock()
+
+	argCopy := make([]*RepositoryMockRecordOperationParams, len(mmRecordOperation.callArgs))
+	copy(argCopy, mmRecordOperation.callArgs)
+
+	mmRecordOperation.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockRecordOperationDone returns true if the count of the RecordOperation invocations corresponds
+// the number of defined expectations
+func (m *RepositoryMock) MinimockRecordOperationDone() bool {
+	for _, e := range m.RecordOperationMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.RecordOperationMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterRecordOperationCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcRecordOperation != nil && mm_atomic.LoadUint64(&m.afterRecordOperationCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockRecordOperationInspect logs each unmet expectation
+func (m *RepositoryMock) MinimockRecordOperationInspect() {
+	for _, e := range m.RecordOperationMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to RepositoryMock.RecordOperation with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.RecordOperationMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterRecordOperationCounter) < 1 {
+		if m.RecordOperationMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to RepositoryMock.RecordOperation")
+		} else {
+			m.t.Errorf("Expected call to RepositoryMock.RecordOperation with params: %#v", *m.RecordOperationMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcRecordOperation != nil && mm_atomic.LoadUint64(&m.afterRecordOperationCounter) < 1 {
+		m.t.Error("Expected call to RepositoryMock.RecordOperation")
+	}
+}
+
+type mRepositoryMockTransaction struct {
+	mock               *RepositoryMock
+	defaultExpectation *RepositoryMockTransactionExpectation
+	expectations       []*RepositoryMockTransactionExpectation
+
+	callArgs []*RepositoryMockTransactionParams
+	mutex    sync.RWMutex
+}
+
+// RepositoryMockTransactionExpectation specifies expectation struct of the WalletRepository.Transaction
+type RepositoryMockTransactionExpectation struct {
+	mock    *RepositoryMock
+	params  *RepositoryMockTransactionParams
+	results *RepositoryMockTransactionResults
+	Counter uint64
+}
+
+// RepositoryMockTransactionParams contains parameters of the WalletRepository.Transaction
+type RepositoryMockTransactionParams struct {
+	ctx context.Context
+	ids []string
+	fn  func(repo mm_models.WalletRepository) error
+}
+
+// RepositoryMockTransactionResults contains results of the WalletRepository.Transaction
+type RepositoryMockTransactionResults struct {
+	err error
+}
+
+// Expect sets up expected params for WalletRepository.Transaction
+func (mmTransaction *mRepositoryMockTransaction) Expect(ctx context.Context, ids []string, fn func(repo mm_models.WalletRepository) error) *mRepositoryMockTransaction {
+	if mmTransaction.mock.funcTransaction != nil {
+		mmTransaction.mock.t.Fatalf("RepositoryMock.Transaction mock is already set by Set")
+	}
+
+	if mmTransaction.defaultExpectation == nil {
+		mmTransaction.defaultExpectation = &RepositoryMockTransactionExpectation{}
+	}
+
+	mmTransaction.defaultExpectation.params = &RepositoryMockTransactionParams{ctx, ids, fn}
+	for _, e := range mmTransaction.expectations {
+		if minimock.Equal(e.params, mmTransaction.defaultExpectation.params) {
+			mmTransaction.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmTransaction.defaultExpectation.params)
+		}
+	}
+
+	return mmTransaction
+}
+
+// Inspect accepts an inspector function that has same arguments as the WalletRepository.Transaction
+func (mmTransaction *mRepositoryMockTransaction) Inspect(f func(ctx context.Context, ids []string, fn func(repo mm_models.WalletRepository) error)) *mRepositoryMockTransaction {
+	if mmTransaction.mock.inspectFuncTransaction != nil {
+		mmTransaction.mock.t.Fatalf("Inspect function is already set for RepositoryMock.Transaction")
+	}
+
+	mmTransaction.mock.inspectFuncTransaction = f
+
+	return mmTransaction
+}
+
+// Return sets up results that will be returned by WalletRepository.Transaction
+func (mmTransaction *mRepositoryMockTransaction) Return(err error) *RepositoryMock {
+	if mmTransaction.mock.funcTransaction != nil {
+		mmTransaction.mock.t.Fatalf("RepositoryMock.Transaction mock is already set by Set")
+	}
+
+	if mmTransaction.defaultExpectation == nil {
+		mmTransaction.defaultExpectation = &RepositoryMockTransactionExpectation{mock: mmTransaction.mock}
+	}
+	mmTransaction.defaultExpectation.results = &RepositoryMockTransactionResults{err}
+	return mmTransaction.mock
+}
+
+// Set uses given function f to mock the WalletRepository.Transaction method
+func (mmTransaction *mRepositoryMockTransaction) Set(f func(ctx context.Context, ids []string, fn func(repo mm_models.WalletRepository) error) (err error)) *RepositoryMock {
+	if mmTransaction.defaultExpectation != nil {
+		mmTransaction.mock.t.Fatalf("Default expectation is already set for the WalletRepository.Transaction method")
+	}
+
+	if len(mmTransaction.expectations) > 0 {
+		mmTransaction.mock.t.Fatalf("Some expectations are already set for the WalletRepository.Transaction method")
+	}
+
+	mmTransaction.mock.funcTransaction = f
+	return mmTransaction.mock
+}
+
+// When sets expectation for the WalletRepository.Transaction which will trigger the result defined by the following
+// Then helper
+func (mmTransaction *mRepositoryMockTransaction) When(ctx context.Context, ids []string, fn func(repo mm_models.WalletRepository) error) *RepositoryMockTransactionExpectation {
+	if mmTransaction.mock.funcTransaction != nil {
+		mmTransaction.mock.t.Fatalf("RepositoryMock.Transaction mock is already set by Set")
+	}
+
+	expectation := &RepositoryMockTransactionExpectation{
+		mock:   mmTransaction.mock,
+		params: &RepositoryMockTransactionParams{ctx, ids, fn},
+	}
+	mmTransaction.expectations = append(mmTransaction.expectations, expectation)
+	return expectation
+}
+
+// Then sets up WalletRepository.Transaction return parameters for the expectation previously defined by the When method
+func (e *RepositoryMockTransactionExpectation) Then(err error) *RepositoryMock {
+	e.results = &RepositoryMockTransactionResults{err}
+	return e.mock
+}
+
+// Transaction implements models.WalletRepository
+func (mmTransaction *RepositoryMock) Transaction(ctx context.Context, ids []string, fn func(repo mm_models.WalletRepository) error) (err error) {
+	mm_atomic.AddUint64(&mmTransaction.beforeTransactionCounter, 1)
+	defer mm_atomic.AddUint64(&mmTransaction.afterTransactionCounter, 1)
+
+	if mmTransaction.inspectFuncTransaction != nil {
+		mmTransaction.inspectFuncTransaction(ctx, ids, fn)
+	}
+
+	mm_params := &RepositoryMockTransactionParams{ctx, ids, fn}
+
+	// Record call args
+	mmTransaction.TransactionMock.mutex.Lock()
+	mmTransaction.TransactionMock.callArgs = append(mmTransaction.TransactionMock.callArgs, mm_params)
+	mmTransaction.TransactionMock.mutex.Unlock()
+
+	for _, e := range mmTransaction.TransactionMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.err
+		}
+	}
+
+	if mmTransaction.TransactionMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmTransaction.TransactionMock.defaultExpectation.Counter, 1)
+		mm_want := mmTransaction.TransactionMock.defaultExpectation.params
+		mm_got := RepositoryMockTransactionParams{ctx, ids, fn}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmTransaction.t.Errorf("RepositoryMock.Transaction got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmTransaction.TransactionMock.defaultExpectation.results
+		if mm_results == nil {
+			mmTransaction.t.Fatal("No results are set for the RepositoryMock.Transaction")
+		}
+		return (*mm_results).err
+	}
+	if mmTransaction.funcTransaction != nil {
+		return mmTransaction.funcTransaction(ctx, ids, fn)
+	}
+	mmTransaction.t.Fatalf("Unexpected call to RepositoryMock.Transaction. %v %v %v", ctx, ids, fn)
+	return
+}
+
+// TransactionAfterCounter returns a count of finished RepositoryMock.Transaction invocations
+func (mmTransaction *RepositoryMock) TransactionAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmTransaction.afterTransactionCounter)
+}
+
+// TransactionBeforeCounter returns a count of RepositoryMock.Transaction invocations
+func (mmTransaction *RepositoryMock) TransactionBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmTransaction.beforeTransactionCounter)
+}
+
+// Calls returns a list of arguments used in each call to RepositoryMock.Transaction.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmTransaction *mRepositoryMockTransaction) Calls() []*RepositoryMockTransactionParams {
+	mmTransaction.mutex.RLock()
+
+	argCopy := make([]*RepositoryMockTransactionParams, len(mmTransaction.callArgs))
+	copy(argCopy, mmTransaction.callArgs)
+
+	mmTransaction.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockTransactionDone returns true if the count of the Transaction invocations corresponds
+// the number of defined expectations
+func (m *RepositoryMock) MinimockTransactionDone() bool {
+	for _, e := range m.TransactionMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.TransactionMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterTransactionCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcTransaction != nil && mm_atomic.LoadUint64(&m.afterTransactionCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockTransactionInspect logs each unmet expectation
+func (m *RepositoryMock) MinimockTransactionInspect() {
+	for _, e := range m.TransactionMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to RepositoryMock.Transaction with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.TransactionMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterTransactionCounter) < 1 {
+		if m.TransactionMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to RepositoryMock.Transaction")
+		} else {
+			m.t.Errorf("Expected call to RepositoryMock.Transaction with params: %#v", *m.TransactionMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcTransaction != nil && mm_atomic.LoadUint64(&m.afterTransactionCounter) < 1 {
+		m.t.Error("Expected call to RepositoryMock.Transaction")
+	}
+}
+
+type mRepositoryMockUpdateByID struct {
+	mock               *RepositoryMock
+	defaultExpectation *RepositoryMockUpdateByIDExpectation
+	expectations       []*RepositoryMockUpdateByIDExpectation
+
+	callArgs []*RepositoryMockUpdateByIDParams
+	mutex    sync.RWMutex
+}
+
+// RepositoryMockUpdateByIDExpectation specifies expectation struct of the WalletRepository.UpdateByID
+type RepositoryMockUpdateByIDExpectation struct {
+	mock    *RepositoryMock
+	params  *RepositoryMockUpdateByIDParams
+	results *RepositoryMockUpdateByIDResults
+	Counter uint64
+}
+
+// RepositoryMockUpdateByIDParams contains parameters of the WalletRepository.UpdateByID
+type RepositoryMockUpdateByIDParams struct {
+	id  string
+	upd mm_models.WalletUpdate
+}
+
+// RepositoryMockUpdateByIDResults contains results of the WalletRepository.UpdateByID
+type RepositoryMockUpdateByIDResults struct {
+	err error
+}
+
+// Expect sets up expected params for WalletRepository.UpdateByID
+func (mmUpdateByID *mRepositoryMockUpdateByID) Expect(id string, upd mm_models.WalletUpdate) *mRepositoryMockUpdateByID {
+	if mmUpdateByID.mock.funcUpdateByID != nil {
+		mmUpdateByID.mock.t.Fatalf("RepositoryMock.UpdateByID mock is already set by Set")
+	}
+
+	if mmUpdateByID.defaultExpectation == nil {
+		mmUpdateByID.defaultExpectation = &RepositoryMockUpdateByIDExpectation{}
+	}
+
+	mmUpdateByID.defaultExpectation.params = &RepositoryMockUpdateByIDParams{id, upd}
+	for _, e := range mmUpdateByID.expectations {
+		if minimock.Equal(e.params, mmUpdateByID.defaultExpectation.params) {
+			mmUpdateByID.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUpdateByID.defaultExpectation.params)
+		}
+	}
+
+	return mmUpdateByID
+}
+
+// Inspect accepts an inspector function that has same arguments as the WalletRepository.UpdateByID
+func (mmUpdateByID *mRepositoryMockUpdateByID) Inspect(f func(id string, upd mm_models.WalletUpdate)) *mRepositoryMockUpdateByID {
+	if mmUpdateByID.mock.inspectFuncUpdateByID != nil {
+		mmUpdateByID.mock.t.Fatalf("Inspect function is already set for RepositoryMock.UpdateByID")
+	}
+
+	mmUpdateByID.mock.inspectFuncUpdateByID = f
+
+	return mmUpdateByID
+}
+
+// Return sets up results that will be returned by WalletRepository.UpdateByID
+func (mmUpdateByID *mRepositoryMockUpdateByID) Return(err error) *RepositoryMock {
+	if mmUpdateByID.mock.funcUpdateByID != nil {
+		mmUpdateByID.mock.t.Fatalf("RepositoryMock.UpdateByID mock is already set by Set")
+	}
+
+	if mmUpdateByID.defaultExpectation == nil {
+		mmUpdateByID.defaultExpectation = &RepositoryMockUpdateByIDExpectation{mock: mmUpdateByID.mock}
+	}
+	mmUpdateByID.defaultExpectation.results = &RepositoryMockUpdateByIDResults{err}
+	return mmUpdateByID.mock
+}
+
+// Set uses given function f to mock the WalletRepository.UpdateByID method
+func (mmUpdateByID *mRepositoryMockUpdateByID) Set(f func(id string, upd mm_models.WalletUpdate) (err error)) *RepositoryMock {
+	if mmUpdateByID.defaultExpectation != nil {
+		mmUpdateByID.mock.t.Fatalf("Default expectation is already set for the WalletRepository.UpdateByID method")
+	}
+
+	if len(mmUpdateByID.expectations) > 0 {
+		mmUpdateByID.mock.t.Fatalf("Some expectations are already set for the WalletRepository.UpdateByID method")
+	}
+
+	mmUpdateByID.mock.funcUpdateByID = f
+	return mmUpdateByID.mock
+}
+
+// When sets expectation for the WalletRepository.UpdateByID which will trigger the result defined by the following
+// Then helper
+func (mmUpdateByID *mRepositoryMockUpdateByID) When(id string, upd mm_models.WalletUpdate) *RepositoryMockUpdateByIDExpectation {
+	if mmUpdateByID.mock.funcUpdateByID != nil {
+		mmUpdateByID.mock.t.Fatalf("RepositoryMock.UpdateByID mock is already set by Set")
+	}
+
+	expectation := &RepositoryMockUpdateByIDExpectation{
 		mock:   mmUpdateByID.mock,
-		params: &RepositoryMockUpdateByIDParams{id, name, balance, status},
+		params: &RepositoryMockUpdateByIDParams{id, upd},
 	}
 	mmUpdateByID.expectations = append(mmUpdateByID.expectations, expectation)
 	return expectation
@@ -971,15 +2328,15 @@ func (e *RepositoryMockUpdateByIDExpectation) Then(err error) *RepositoryMock {
 }
 
 // UpdateByID implements models.WalletRepository
-func (mmUpdateByID *RepositoryMock) UpdateByID(id string, name *string, balance *float64, status *bool) (err error) {
+func (mmUpdateByID *RepositoryMock) UpdateByID(id string, upd mm_models.WalletUpdate) (err error) {
 	mm_atomic.AddUint64(&mmUpdateByID.beforeUpdateByIDCounter, 1)
 	defer mm_atomic.AddUint64(&mmUpdateByID.afterUpdateByIDCounter, 1)
 
 	if mmUpdateByID.inspectFuncUpdateByID != nil {
-		mmUpdateByID.inspectFuncUpdateByID(id, name, balance, status)
+		mmUpdateByID.inspectFuncUpdateByID(id, upd)
 	}
 
-	mm_params := &RepositoryMockUpdateByIDParams{id, name, balance, status}
+	mm_params := &RepositoryMockUpdateByIDParams{id, upd}
 
 	// Record call args
 	mmUpdateByID.UpdateByIDMock.mutex.Lock()
@@ -996,7 +2353,7 @@ func (mmUpdateByID *RepositoryMock) UpdateByID(id string, name *string, balance
 	if mmUpdateByID.UpdateByIDMock.defaultExpectation != nil {
 		mm_atomic.AddUint64(&mmUpdateByID.UpdateByIDMock.defaultExpectation.Counter, 1)
 		mm_want := mmUpdateByID.UpdateByIDMock.defaultExpectation.params
-		mm_got := RepositoryMockUpdateByIDParams{id, name, balance, status}
+		mm_got := RepositoryMockUpdateByIDParams{id, upd}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
 			mmUpdateByID.t.Errorf("RepositoryMock.UpdateByID got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
@@ -1008,9 +2365,9 @@ func (mmUpdateByID *RepositoryMock) UpdateByID(id string, name *string, balance
 		return (*mm_results).err
 	}
 	if mmUpdateByID.funcUpdateByID != nil {
-		return mmUpdateByID.funcUpdateByID(id, name, balance, status)
+		return mmUpdateByID.funcUpdateByID(id, upd)
 	}
-	mmUpdateByID.t.Fatalf("Unexpected call to RepositoryMock.UpdateByID. %v %v %v %v", id, name, balance, status)
+	mmUpdateByID.t.Fatalf("Unexpected call to RepositoryMock.UpdateByID. %v %v", id, upd)
 	return
 }
 
@@ -1086,8 +2443,20 @@ func (m *RepositoryMock) MinimockFinish() {
 
 		m.MinimockByIDInspect()
 
+		m.MinimockCloseHoldInspect()
+
 		m.MinimockCreateInspect()
 
+		m.MinimockCreateHoldInspect()
+
+		m.MinimockHoldByIDInspect()
+
+		m.MinimockLedgerInspect()
+
+		m.MinimockListInspect()
+
+		m.MinimockRecordOperationInspect()
+
 		m.MinimockTransactionInspect()
 
 		m.MinimockUpdateByIDInspect()
@@ -1116,7 +2485,13 @@ func (m *RepositoryMock) minimockDone() bool {
 	return done &&
 		m.MinimockAllDone() &&
 		m.MinimockByIDDone() &&
+		m.MinimockCloseHoldDone() &&
 		m.MinimockCreateDone() &&
+		m.MinimockCreateHoldDone() &&
+		m.MinimockHoldByIDDone() &&
+		m.MinimockLedgerDone() &&
+		m.MinimockListDone() &&
+		m.MinimockRecordOperationDone() &&
 		m.MinimockTransactionDone() &&
 		m.MinimockUpdateByIDDone()
 }
@@ -0,0 +1,196 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	errOperationNotFound = fmt.Errorf("operation %w", models.ErrNotFound)
+	errNotReversible     = fmt.Errorf("only deposits, withdrawals, transfers and batch transfers can be reversed: %w", models.ErrInvalidArgument)
+	errAlreadyReversed   = fmt.Errorf("operation is already fully reversed: %w", models.ErrInvalidArgument)
+	errPartialBatch      = fmt.Errorf("batch transfer can only be reversed in full: %w", models.ErrInvalidArgument)
+)
+
+// ReverseOperation сторнируем операцию operationID компенсирующими проводками.
+// amount - сумма возврата, 0 - весь несторнированный остаток операции.
+// Сумма всех возвратов не может превысить сумму операции, пакетный перевод сторнируется только целиком.
+// Комиссия операции возвращается только при сторнировании всей операции одним возвратом.
+// Кошелек, с которого списывается возврат, должен иметь достаточно доступных средств.
+func (man *manager) ReverseOperation(ctx context.Context, operationID string, amount float64) (_ models.OperationResult, err error) {
+	ctx, span := startSpan(ctx, "wallet.ReverseOperation",
+		attribute.String("operation.id", operationID),
+		attribute.Float64("wallet.amount", amount),
+	)
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorizeAdmin(ctx, models.PermWalletReverse)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	if amount != 0 {
+		err = man.validateAmount(amount)
+		if err != nil {
+			return models.OperationResult{}, err
+		}
+	}
+
+	original, err := man.repo.Ledger(models.LedgerFilter{OperationID: operationID})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+	if len(original) == 0 {
+		return models.OperationResult{}, fmt.Errorf("operation %s: %w", operationID, errOperationNotFound)
+	}
+	if !reversible(original[0].Type) {
+		return models.OperationResult{}, fmt.Errorf("operation %s of type %s: %w", operationID, original[0].Type, errNotReversible)
+	}
+
+	// result заполняется заново при каждом выполнении fn: транзакция может повторяться
+	var result models.OperationResult
+	errTx := man.repo.Transaction(ctx, entryWallets(original), func(repo models.WalletRepository) error {
+		result = models.OperationResult{}
+		reversals, err := repo.Ledger(models.LedgerFilter{Reverses: operationID})
+		if err != nil {
+			return err
+		}
+
+		total := operationAmount(original)
+		remaining := man.roundAmount(total - operationAmount(reversals))
+		if remaining <= 0 {
+			return fmt.Errorf("operation %s: %w", operationID, errAlreadyReversed)
+		}
+		reversed := amount
+		if reversed == 0 {
+			reversed = remaining
+		}
+		if reversed > remaining {
+			return fmt.Errorf("amount %g exceeds not reversed %g of operation %s: %w", reversed, remaining, operationID, models.ErrInvalidArgument)
+		}
+		full := len(reversals) == 0 && reversed == total
+		if original[0].Type == models.OperationBatch && !full {
+			return fmt.Errorf("operation %s: %w", operationID, errPartialBatch)
+		}
+
+		entries := reversalEntries(original, operationID, reversed, full)
+		var fee float64
+		for _, entry := range entries {
+			if entry.Fee && entry.Amount > 0 {
+				fee += entry.Amount
+			}
+		}
+
+		err = applyEntries(repo, entries)
+		if err != nil {
+			return err
+		}
+		opID, err := repo.RecordOperation(models.OperationReversal, entries)
+		if err != nil {
+			return err
+		}
+		result = models.OperationResult{OperationID: opID, Amount: reversed, Fee: fee, Reverses: operationID}
+		return nil
+	})
+	if errTx != nil {
+		return models.OperationResult{}, errTx
+	}
+
+	return result, nil
+}
+
+// reversible можно ли сторнировать операцию типа opType.
+// Начальный баланс, исправления сверки и сами сторнирования не сторнируются.
+func reversible(opType models.OperationType) bool {
+	switch opType {
+	case models.OperationDeposit, models.OperationWithdraw, models.OperationTransfer, models.OperationBatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// reversalEntries компенсирующие проводки возврата amount по операции original.
+// При полном сторнировании компенсируются все проводки, включая комиссию.
+func reversalEntries(original []models.LedgerEntry, operationID string, amount float64, full bool) []models.LedgerEntry {
+	entries := make([]models.LedgerEntry, 0, len(original))
+	for _, entry := range original {
+		if entry.Fee && !full {
+			continue
+		}
+		reversed := -entry.Amount
+		if !full {
+			reversed = math.Copysign(amount, -entry.Amount)
+		}
+		entries = append(entries, models.LedgerEntry{
+			WalletID:     entry.WalletID,
+			Amount:       reversed,
+			Counterparty: entry.Counterparty,
+			Fee:          entry.Fee,
+			Reverses:     operationID,
+		})
+	}
+	return entries
+}
+
+// applyEntries меняем балансы кошельков на сумму проводок.
+// Списание не может превысить доступные средства кошелька.
+func applyEntries(repo models.WalletRepository, entries []models.LedgerEntry) error {
+	deltas := make(map[string]float64, len(entries))
+	for _, entry := range entries {
+		deltas[entry.WalletID] += entry.Amount
+	}
+
+	for _, id := range entryWallets(entries) {
+		wallet, err := repo.ByID(id)
+		if err != nil {
+			return fmt.Errorf("cannot get wallet by id %s: %w", id, err)
+		}
+		delta := deltas[id]
+		if delta < 0 && wallet.Available() < -delta {
+			return fmt.Errorf("wallet %s: %w", id, errNotEnoughBalance)
+		}
+		err = repo.UpdateByID(id, models.WalletUpdate{Balance: utils.Ptr[float64](wallet.Balance() + delta)})
+		if err != nil {
+			return fmt.Errorf("cannot update wallet %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// operationAmount сумма операции без комиссии: сумма зачислений, а для снятия - сумма списаний.
+func operationAmount(entries []models.LedgerEntry) float64 {
+	var credit, debit float64
+	for _, entry := range entries {
+		if entry.Fee {
+			continue
+		}
+		if entry.Amount > 0 {
+			credit += entry.Amount
+		} else {
+			debit -= entry.Amount
+		}
+	}
+	return math.Max(credit, debit)
+}
+
+// entryWallets кошельки проводок по возрастанию id.
+func entryWallets(entries []models.LedgerEntry) []string {
+	seen := make(map[string]struct{}, len(entries))
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if _, ok := seen[entry.WalletID]; ok {
+			continue
+		}
+		seen[entry.WalletID] = struct{}{}
+		ids = append(ids, entry.WalletID)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,170 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/buisness/fee"
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/repository"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseOperation_partialRefunds(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	shop := repo.Create("shop", 0, true, "shop", nil)
+
+	transfer, err := man.TransferBalance(ownerCtx(), wallet.ID(), shop.ID(), 300)
+	assert.Nil(t, err)
+
+	_, err = man.ReverseOperation(ownerCtx(), transfer.OperationID, 100)
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+
+	refund, err := man.ReverseOperation(adminCtx(), transfer.OperationID, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), refund.Amount)
+	assert.Equal(t, transfer.OperationID, refund.Reverses)
+	assertBalance(t, repo, wallet.ID(), 800)
+	assertBalance(t, repo, shop.ID(), 200)
+
+	_, err = man.ReverseOperation(adminCtx(), transfer.OperationID, 250)
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+
+	// остаток операции
+	rest, err := man.ReverseOperation(adminCtx(), transfer.OperationID, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(200), rest.Amount)
+	assertBalance(t, repo, wallet.ID(), 1000)
+	assertBalance(t, repo, shop.ID(), 0)
+
+	_, err = man.ReverseOperation(adminCtx(), transfer.OperationID, 0)
+	assert.True(t, errors.Is(err, errAlreadyReversed))
+	_, err = man.ReverseOperation(adminCtx(), rest.OperationID, 0)
+	assert.True(t, errors.Is(err, errNotReversible))
+
+	entries, err := repo.Ledger(models.LedgerFilter{Reverses: transfer.OperationID})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 4)
+	for _, entry := range entries {
+		assert.Equal(t, models.OperationReversal, entry.Type)
+	}
+}
+
+func TestReverseOperation_notReversible(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+
+	opening, err := repo.Ledger(models.LedgerFilter{WalletID: wallet.ID()})
+	assert.Nil(t, err)
+	assert.Len(t, opening, 1)
+	assert.Equal(t, models.OperationOpening, opening[0].Type)
+	_, err = man.ReverseOperation(adminCtx(), opening[0].OperationID, 0)
+	assert.True(t, errors.Is(err, errNotReversible))
+
+	correctionID, err := repo.RecordOperation(models.OperationCorrection, []models.LedgerEntry{{WalletID: wallet.ID(), Amount: 5}})
+	assert.Nil(t, err)
+	_, err = man.ReverseOperation(adminCtx(), correctionID, 0)
+	assert.True(t, errors.Is(err, errNotReversible))
+	assertBalance(t, repo, wallet.ID(), 1000)
+
+	_, err = man.ReverseOperation(adminCtx(), "unknown", 0)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+}
+
+func TestReverseOperation_fullWithFee(t *testing.T) {
+	repo := repository.NewRepo()
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	fees := repo.Create("fees", 0, true, "system", nil)
+	engine, err := fee.NewEngine(models.FeeRules{
+		WalletID:   fees.ID(),
+		Operations: map[models.OperationType]models.FeeRule{models.OperationWithdraw: {Flat: 5}},
+	})
+	assert.Nil(t, err)
+	man := NewManager(repo, WithFees(engine))
+
+	withdraw, err := man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 100)
+	assert.Nil(t, err)
+	assertBalance(t, repo, wallet.ID(), 895)
+
+	reversal, err := man.ReverseOperation(adminCtx(), withdraw.OperationID, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), reversal.Amount)
+	assert.Equal(t, float64(5), reversal.Fee)
+	assertBalance(t, repo, wallet.ID(), 1000)
+	assertBalance(t, repo, fees.ID(), 0)
+}
+
+// conflictRepo хранилище, в котором первые conflicts транзакций завершаются конфликтом:
+// после выполнения fn другой экземпляр меняет первый кошелек транзакции.
+type conflictRepo struct {
+	models.WalletRepository
+	other     models.WalletRepository
+	conflicts int
+}
+
+func (repo *conflictRepo) Transaction(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) error {
+	return repo.WalletRepository.Transaction(ctx, ids, func(tx models.WalletRepository) error {
+		err := fn(tx)
+		if err != nil || repo.conflicts == 0 {
+			return err
+		}
+		repo.conflicts--
+		return repo.other.UpdateByID(ids[0], models.WalletUpdate{})
+	})
+}
+
+func TestReverseOperation_retriedTransaction(t *testing.T) {
+	server := miniredis.RunT(t)
+	repos := make([]models.WalletRepository, 2)
+	for i := range repos {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		repos[i] = repository.NewRedisRepo(client, repository.RedisOptions{})
+	}
+	repo := &conflictRepo{WalletRepository: repos[0], other: repos[1]}
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	fees := repo.Create("fees", 0, true, "system", nil)
+	engine, err := fee.NewEngine(models.FeeRules{
+		WalletID:   fees.ID(),
+		Operations: map[models.OperationType]models.FeeRule{models.OperationWithdraw: {Flat: 5}},
+	})
+	assert.Nil(t, err)
+	man := NewManager(repo, WithFees(engine))
+
+	withdraw, err := man.DecreaseBalanceBy(ownerCtx(), wallet.ID(), 100)
+	assert.Nil(t, err)
+
+	repo.conflicts = 1
+	reversal, err := man.ReverseOperation(adminCtx(), withdraw.OperationID, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, repo.conflicts)
+	assert.Equal(t, float64(100), reversal.Amount)
+	assert.Equal(t, float64(5), reversal.Fee)
+	assertBalance(t, repo, wallet.ID(), 1000)
+	assertBalance(t, repo, fees.ID(), 0)
+}
+
+func TestReverseOperation_notEnoughBalance(t *testing.T) {
+	repo := repository.NewRepo()
+	man := NewManager(repo)
+	wallet := repo.Create("wallet", 1000, true, testOwner, nil)
+	shop := repo.Create("shop", 0, true, "shop", nil)
+
+	transfer, err := man.TransferBalance(ownerCtx(), wallet.ID(), shop.ID(), 300)
+	assert.Nil(t, err)
+	_, err = man.DecreaseBalanceBy(adminCtx(), shop.ID(), 250)
+	assert.Nil(t, err)
+
+	_, err = man.ReverseOperation(adminCtx(), transfer.OperationID, 0)
+	assert.True(t, errors.Is(err, errNotEnoughBalance))
+	assertBalance(t, repo, wallet.ID(), 700)
+
+	_, err = man.ReverseOperation(adminCtx(), "unknown", 0)
+	assert.True(t, errors.Is(err, errOperationNotFound))
+}
@@ -1,11 +1,17 @@
 package wallet
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Nizom98/wallet/internal/models"
 	"github.com/Nizom98/wallet/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -14,145 +20,356 @@ const (
 )
 
 var (
-	errAmountLessThanOne = errors.New("amount cannot be less than 1")
-	errEmptyName         = errors.New("empty wallet name")
-	errNotEnoughBalance  = errors.New("wallet has not enough balance")
-	errSameWallet        = errors.New("same wallet")
+	errEmptyName        = errors.New("empty wallet name")
+	errNotEnoughBalance = errors.New("wallet has not enough balance")
+	errSameWallet       = errors.New("same wallet")
 )
 
+var tracer = otel.Tracer("github.com/Nizom98/wallet/internal/buisness/wallet")
+
 type manager struct {
-	repo models.WalletRepository
+	repo   models.WalletRepository
+	policy authorizer
+	// limits правила лимитов операций
+	limits models.LimitRules
+	// amounts правила сумм операций и балансов
+	amounts models.AmountPolicy
+	// fees расчет комиссий, nil - без комиссий
+	fees feeCalculator
+	// now текущее время для сроков блокировок и окон лимитов
+	now func() time.Time
+}
+
+// Option дополнительная настройка менеджера кошельков.
+type Option func(man *manager)
+
+// WithPolicy проверять права клиента на каждую операцию по политике доступа.
+func WithPolicy(policy authorizer) Option {
+	return func(man *manager) {
+		man.policy = policy
+	}
 }
 
 // NewManager конструктор менеджера кошельков
-func NewManager(repo models.WalletRepository) *manager {
-	return &manager{
+func NewManager(repo models.WalletRepository, opts ...Option) *manager {
+	man := &manager{
 		repo: repo,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(man)
 	}
+	return man
 }
 
 // Create создаем новый кошелек.
 // name - наименование кошелька(не пустое).
-func (man *manager) Create(name string) (models.Walleter, error) {
+func (man *manager) Create(ctx context.Context, name string) (_ models.Walleter, err error) {
+	ctx, span := startSpan(ctx, "wallet.Create")
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletCreate)
+	if err != nil {
+		return nil, err
+	}
+
 	if name == "" {
 		return nil, errEmptyName
 	}
+	owner, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var newWallet models.Walleter
-	err := man.repo.Transaction(func(repo models.WalletRepository) error {
-		newWallet = repo.Create(name, defaultBalance, defaultStatus)
+	err = man.repo.Transaction(ctx, nil, func(repo models.WalletRepository) error {
+		newWallet = repo.Create(name, defaultBalance, defaultStatus, owner.ID, nil)
 		return nil
 	})
+	if newWallet != nil {
+		span.SetAttributes(attribute.String("wallet.id", newWallet.ID()))
+	}
 
 	return newWallet, err
 }
 
 // ByID получаем кошелек по идентификатору(даже если деактивирован).
-func (man *manager) ByID(id string) (models.Walleter, error) {
-	return man.repo.ByID(id)
+func (man *manager) ByID(ctx context.Context, id string) (_ models.Walleter, err error) {
+	ctx, span := startSpan(ctx, "wallet.ByID", attribute.String("wallet.id", id))
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletRead)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := man.repo.ByID(id)
+	if err != nil {
+		return nil, err
+	}
+	err = man.checkOwner(ctx, wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
 }
 
-// List получаем весь список кошельков(включая деактивированные)
-func (man *manager) List() []models.Walleter {
-	return man.repo.All()
+// List получаем страницу списка кошельков клиента(включая деактивированные).
+// Клиент с правом на чужие кошельки может получать кошельки всех владельцев.
+func (man *manager) List(ctx context.Context, filter models.WalletFilter) (_ *models.WalletPage, err error) {
+	ctx, span := startSpan(ctx, "wallet.List")
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletList)
+	if err != nil {
+		return nil, err
+	}
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !man.anyOwner(p) {
+		filter.Owner = p.ID
+	}
+
+	return man.repo.List(filter)
 }
 
 // IncreaseBalanceBy пополнение кошелька.
 // id - какой кошелек пополняем.
-// amount - сумма пополнения(больше 0).
-func (man *manager) IncreaseBalanceBy(id string, amount float64) error {
-	if amount <= 0 {
-		return errAmountLessThanOne
+// amount - сумма пополнения, проверяется по правилам сумм валюты.
+func (man *manager) IncreaseBalanceBy(ctx context.Context, id string, amount float64) (_ models.OperationResult, err error) {
+	ctx, span := startSpan(ctx, "wallet.IncreaseBalanceBy",
+		attribute.String("wallet.id", id),
+		attribute.Float64("wallet.amount", amount),
+	)
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletDeposit)
+	if err != nil {
+		return models.OperationResult{}, err
 	}
 
-	errTx := man.repo.Transaction(func(repo models.WalletRepository) error {
+	err = man.validateAmount(amount)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+	err = man.checkAmount(id, amount)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	result := models.OperationResult{Amount: amount}
+	errTx := man.repo.Transaction(ctx, []string{id}, func(repo models.WalletRepository) error {
 		wallet, err := repo.ByID(id)
 		if err != nil {
 			return fmt.Errorf("wallet %s: %w", id, err)
 		}
+		err = man.checkOwner(ctx, wallet)
+		if err != nil {
+			return err
+		}
 
 		newBalance := wallet.Balance() + amount
-		return repo.UpdateByID(id, nil, utils.Ptr[float64](newBalance), nil)
+		err = man.checkBalanceCeiling(id, newBalance)
+		if err != nil {
+			return err
+		}
+		err = repo.UpdateByID(id, models.WalletUpdate{Balance: utils.Ptr[float64](newBalance)})
+		if err != nil {
+			return err
+		}
+		result.OperationID, err = repo.RecordOperation(models.OperationDeposit, []models.LedgerEntry{{WalletID: id, Amount: amount}})
+		return err
 	})
+	if errTx != nil {
+		return models.OperationResult{}, errTx
+	}
 
-	return errTx
+	return result, nil
 }
 
 // DecreaseBalanceBy снятие средств из кошелька.
+// Вместе с суммой списывается комиссия, баланс может уйти в минус в пределах овердрафта кошелька.
 // id - из какого кошелька снимаем.
-// amount - сумма снятия(больше 0).
-func (man *manager) DecreaseBalanceBy(id string, amount float64) error {
-	if amount <= 0 {
-		return errAmountLessThanOne
+// amount - сумма снятия, проверяется по правилам сумм валюты.
+func (man *manager) DecreaseBalanceBy(ctx context.Context, id string, amount float64) (_ models.OperationResult, err error) {
+	ctx, span := startSpan(ctx, "wallet.DecreaseBalanceBy",
+		attribute.String("wallet.id", id),
+		attribute.Float64("wallet.amount", amount),
+	)
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletWithdraw)
+	if err != nil {
+		return models.OperationResult{}, err
 	}
 
-	errTx := man.repo.Transaction(func(repo models.WalletRepository) error {
+	err = man.validateAmount(amount)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+	err = man.checkAmount(id, amount)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	fee, feeWalletID := man.fee(models.OperationWithdraw, id, amount)
+	ids := []string{id}
+	if fee > 0 {
+		ids = append(ids, feeWalletID)
+	}
+
+	result := models.OperationResult{Amount: amount, Fee: fee}
+	errTx := man.repo.Transaction(ctx, ids, func(repo models.WalletRepository) error {
 		wallet, err := repo.ByID(id)
 		if err != nil {
 			return fmt.Errorf("wallet %s: %w", id, err)
 		}
+		err = man.checkOwner(ctx, wallet)
+		if err != nil {
+			return err
+		}
 
-		newBalance := wallet.Balance() - amount
-		if newBalance < 0 {
+		if wallet.Available() < amount+fee {
 			return fmt.Errorf("wallet %s: %w", wallet.ID(), errNotEnoughBalance)
 		}
+		err = man.checkOutgoing(repo, id, amount+fee, 1)
+		if err != nil {
+			return err
+		}
+
+		newBalance := wallet.Balance() - amount
+		err = repo.UpdateByID(id, models.WalletUpdate{Balance: utils.Ptr[float64](newBalance)})
+		if err != nil {
+			return err
+		}
+		entries := []models.LedgerEntry{{WalletID: id, Amount: -amount}}
 
-		return repo.UpdateByID(id, nil, utils.Ptr[float64](newBalance), nil)
+		feeEntries, err := man.chargeFee(repo, id, feeWalletID, fee)
+		if err != nil {
+			return err
+		}
+		result.OperationID, err = repo.RecordOperation(models.OperationWithdraw, append(entries, feeEntries...))
+		return err
 	})
+	if errTx != nil {
+		return models.OperationResult{}, errTx
+	}
 
-	return errTx
+	return result, nil
 }
 
 // TransferBalance перевод средств из одного кошелька в другой.
 // Перевод в рамках одного кошелька запрещена.
+// С источника вместе с суммой списывается комиссия, овердрафт источника учитывается.
 // fromID - из какого кошелька переводи.
 // toID - в какой кошелек переводим.
-// amount - сумма перевода(больше 0).
-func (man *manager) TransferBalance(fromID, toID string, amount float64) error {
+// amount - сумма перевода, проверяется по правилам сумм валюты.
+func (man *manager) TransferBalance(ctx context.Context, fromID, toID string, amount float64) (_ models.OperationResult, err error) {
+	ctx, span := startSpan(ctx, "wallet.TransferBalance",
+		attribute.String("wallet.from_id", fromID),
+		attribute.String("wallet.to_id", toID),
+		attribute.Float64("wallet.amount", amount),
+	)
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletTransfer)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
 	if fromID == toID {
-		return errSameWallet
+		return models.OperationResult{}, errSameWallet
 	}
-	if amount <= 0 {
-		return errAmountLessThanOne
+	err = man.validateAmount(amount)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+	err = man.checkAmount(fromID, amount)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	fee, feeWalletID := man.fee(models.OperationTransfer, fromID, amount)
+	ids := []string{fromID, toID}
+	if fee > 0 && feeWalletID != toID {
+		ids = append(ids, feeWalletID)
 	}
 
-	errTx := man.repo.Transaction(func(repo models.WalletRepository) error {
+	result := models.OperationResult{Amount: amount, Fee: fee}
+	errTx := man.repo.Transaction(ctx, ids, func(repo models.WalletRepository) error {
 		fromWallet, err := repo.ByID(fromID)
 		if err != nil {
 			return fmt.Errorf("cannot get source wallet by id %s: %w", fromID, err)
 		}
+		err = man.checkOwner(ctx, fromWallet)
+		if err != nil {
+			return err
+		}
 		toWallet, err := repo.ByID(toID)
 		if err != nil {
 			return fmt.Errorf("cannot get dest wallet by id %s: %w", toID, err)
 		}
 
-		if fromWallet.Balance() < amount {
+		if fromWallet.Available() < amount+fee {
 			return fmt.Errorf("wallet %s: %w", fromWallet.ID(), errNotEnoughBalance)
 		}
+		err = man.checkOutgoing(repo, fromID, amount+fee, 1)
+		if err != nil {
+			return err
+		}
 
-		err = repo.UpdateByID(fromID, nil, utils.Ptr[float64](fromWallet.Balance()-amount), nil)
+		err = repo.UpdateByID(fromID, models.WalletUpdate{Balance: utils.Ptr[float64](fromWallet.Balance() - amount)})
 		if err != nil {
 			return fmt.Errorf("cannot update source wallet: %w", err)
 		}
 
-		err = repo.UpdateByID(toID, nil, utils.Ptr[float64](toWallet.Balance()+amount), nil)
+		err = man.checkBalanceCeiling(toID, toWallet.Balance()+amount)
+		if err != nil {
+			return err
+		}
+		err = repo.UpdateByID(toID, models.WalletUpdate{Balance: utils.Ptr[float64](toWallet.Balance() + amount)})
 		if err != nil {
 			return fmt.Errorf("cannot update dest wallet: %w", err)
 		}
-		return nil
+
+		feeEntries, err := man.chargeFee(repo, fromID, feeWalletID, fee)
+		if err != nil {
+			return err
+		}
+		result.OperationID, err = repo.RecordOperation(models.OperationTransfer, append(transferEntries(fromID, toID, amount), feeEntries...))
+		return err
 	})
+	if errTx != nil {
+		return models.OperationResult{}, errTx
+	}
 
-	return errTx
+	return result, nil
 }
 
 // DeactivateByID деактивируем кошелек по идентификатору.
-func (man *manager) DeactivateByID(id string) error {
-	errTx := man.repo.Transaction(func(repo models.WalletRepository) error {
+// version - ожидаемая версия кошелька(nil - без проверки).
+func (man *manager) DeactivateByID(ctx context.Context, id string, version *uint64) (err error) {
+	ctx, span := startSpan(ctx, "wallet.DeactivateByID", attribute.String("wallet.id", id))
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletDeactivate)
+	if err != nil {
+		return err
+	}
+
+	errTx := man.repo.Transaction(ctx, []string{id}, func(repo models.WalletRepository) error {
 		wallet, err := repo.ByID(id)
 		if err != nil {
 			return fmt.Errorf("cannot get wallet by id %s: %w", id, err)
 		}
+		err = man.checkOwner(ctx, wallet)
+		if err != nil {
+			return err
+		}
 
-		err = repo.UpdateByID(wallet.ID(), nil, nil, utils.Ptr[bool](false))
+		err = repo.UpdateByID(wallet.ID(), models.WalletUpdate{Status: utils.Ptr[bool](false), ExpectedVersion: version})
 		if err != nil {
 			return fmt.Errorf("cannot update dest wallet: %w", err)
 		}
@@ -164,12 +381,30 @@ func (man *manager) DeactivateByID(id string) error {
 
 // UpdateName обновляем наименование кошелька.
 // Пустое наименование не допускается.
-func (man *manager) UpdateName(id, name string) error {
+// version - ожидаемая версия кошелька(nil - без проверки).
+func (man *manager) UpdateName(ctx context.Context, id, name string, version *uint64) (err error) {
+	ctx, span := startSpan(ctx, "wallet.UpdateName", attribute.String("wallet.id", id))
+	defer func() { endSpan(span, err) }()
+
+	err = man.authorize(ctx, models.PermWalletRename)
+	if err != nil {
+		return err
+	}
+
 	if name == "" {
 		return errEmptyName
 	}
-	errTx := man.repo.Transaction(func(repo models.WalletRepository) error {
-		err := repo.UpdateByID(id, utils.Ptr[string](name), nil, nil)
+	errTx := man.repo.Transaction(ctx, []string{id}, func(repo models.WalletRepository) error {
+		wallet, err := repo.ByID(id)
+		if err != nil {
+			return fmt.Errorf("cannot get wallet by id %s: %w", id, err)
+		}
+		err = man.checkOwner(ctx, wallet)
+		if err != nil {
+			return err
+		}
+
+		err = repo.UpdateByID(id, models.WalletUpdate{Name: utils.Ptr[string](name), ExpectedVersion: version})
 		if err != nil {
 			return fmt.Errorf("cannot update dest wallet: %w", err)
 		}
@@ -178,3 +413,25 @@ func (man *manager) UpdateName(id, name string) error {
 
 	return errTx
 }
+
+// transferEntries проводки перевода: списание с fromID и зачисление на toID.
+func transferEntries(fromID, toID string, amount float64) []models.LedgerEntry {
+	return []models.LedgerEntry{
+		{WalletID: fromID, Amount: -amount, Counterparty: toID},
+		{WalletID: toID, Amount: amount, Counterparty: fromID},
+	}
+}
+
+// startSpan открываем спан операции менеджера.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan закрываем спан, при ошибке помечаем его как неуспешный.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
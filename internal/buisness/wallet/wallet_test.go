@@ -1,14 +1,21 @@
 package wallet
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/Nizom98/wallet/internal/access"
+	"github.com/Nizom98/wallet/internal/clients/tracing/tracingtest"
 	"github.com/Nizom98/wallet/internal/models"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
 )
 
+const testOwner = "test_owner"
+
 //go:generate minimock -g -i github.com/Nizom98/wallet/internal/models.WalletRepository -o ./repository_mock_test.go -n RepositoryMock
 
 func TestCreate(t *testing.T) {
@@ -16,24 +23,26 @@ func TestCreate(t *testing.T) {
 	man := NewManager(repo)
 	expectName, expectID := "test_name", "test_id"
 
-	repo.CreateMock.Set(func(name string, balance float64, status bool) (w1 models.Walleter) {
+	repo.CreateMock.Set(func(name string, balance float64, status bool, owner string, metadata map[string]string) (w1 models.Walleter) {
 		return &fakeWallet{
 			id:      expectID,
 			name:    name,
 			balance: balance,
 			status:  status,
+			owner:   owner,
 		}
 	})
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
-	wallet, err := man.Create(expectName)
+	wallet, err := man.Create(ownerCtx(), expectName)
 
 	assert.Nil(t, err)
 	assert.True(t, expectID == wallet.ID())
 	assert.True(t, expectName == wallet.Name())
 	assert.True(t, defaultBalance == wallet.Balance())
 	assert.True(t, defaultStatus == wallet.Status())
+	assert.True(t, testOwner == wallet.Owner())
 }
 
 func TestIncreaseBalanceBy_found(t *testing.T) {
@@ -43,19 +52,20 @@ func TestIncreaseBalanceBy_found(t *testing.T) {
 	wallet := newFakeWallet("test_id", "test_name", defaultBalance)
 
 	repo.ByIDMock.Return(wallet, nil)
-	repo.UpdateByIDMock.Set(func(id string, name *string, balance *float64, status *bool) (err error) {
+	repo.UpdateByIDMock.Set(func(id string, upd models.WalletUpdate) (err error) {
 		assert.True(t, id == wallet.id)
-		assert.Nil(t, name)
-		assert.NotNil(t, balance)
-		assert.True(t, *balance == defaultBalance+amount)
-		assert.Nil(t, status)
+		assert.Nil(t, upd.Name)
+		assert.NotNil(t, upd.Balance)
+		assert.True(t, *upd.Balance == defaultBalance+amount)
+		assert.Nil(t, upd.Status)
 		return nil
 	})
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.RecordOperationMock.Expect(models.OperationDeposit, []models.LedgerEntry{{WalletID: wallet.id, Amount: amount}}).Return("op_id", nil)
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
 
-	err := man.IncreaseBalanceBy(wallet.id, amount)
+	_, err := man.IncreaseBalanceBy(ownerCtx(), wallet.id, amount)
 	assert.Nil(t, err)
 }
 
@@ -67,11 +77,11 @@ func TestIncreaseBalanceBy_notFound(t *testing.T) {
 	unknownID := "test_id"
 
 	repo.ByIDMock.Return(nil, expectErr)
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
 
-	err := man.IncreaseBalanceBy(unknownID, amount)
+	_, err := man.IncreaseBalanceBy(ownerCtx(), unknownID, amount)
 	assert.True(t, errors.Is(err, expectErr))
 }
 
@@ -80,9 +90,9 @@ func TestIncreaseBalanceBy_incorrectAmount(t *testing.T) {
 	incorrectAmount := float64(0)
 	walletID := "test_id1"
 
-	err := man.IncreaseBalanceBy(walletID, incorrectAmount)
+	_, err := man.IncreaseBalanceBy(ownerCtx(), walletID, incorrectAmount)
 	assert.NotNil(t, err)
-	assert.True(t, errors.Is(err, errAmountLessThanOne))
+	assert.True(t, errors.Is(err, errNonPositiveAmount))
 }
 
 func TestDecreaseBalanceBy_found(t *testing.T) {
@@ -93,19 +103,20 @@ func TestDecreaseBalanceBy_found(t *testing.T) {
 	wallet := newFakeWallet("test_id", "test_name", oldBalance)
 
 	repo.ByIDMock.Return(wallet, nil)
-	repo.UpdateByIDMock.Set(func(id string, name *string, balance *float64, status *bool) (err error) {
+	repo.UpdateByIDMock.Set(func(id string, upd models.WalletUpdate) (err error) {
 		assert.True(t, id == wallet.id)
-		assert.Nil(t, name)
-		assert.NotNil(t, balance)
-		assert.True(t, *balance == oldBalance-amount)
-		assert.Nil(t, status)
+		assert.Nil(t, upd.Name)
+		assert.NotNil(t, upd.Balance)
+		assert.True(t, *upd.Balance == oldBalance-amount)
+		assert.Nil(t, upd.Status)
 		return nil
 	})
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.RecordOperationMock.Expect(models.OperationWithdraw, []models.LedgerEntry{{WalletID: wallet.id, Amount: -amount}}).Return("op_id", nil)
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
 
-	err := man.DecreaseBalanceBy(wallet.id, amount)
+	_, err := man.DecreaseBalanceBy(ownerCtx(), wallet.id, amount)
 	assert.Nil(t, err)
 }
 
@@ -116,11 +127,11 @@ func TestDecreaseBalanceBy_notEnoughBalance(t *testing.T) {
 	wallet := newFakeWallet("test_id", "test_name", 10)
 
 	repo.ByIDMock.Return(wallet, nil)
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
 
-	err := man.DecreaseBalanceBy(wallet.id, amount)
+	_, err := man.DecreaseBalanceBy(ownerCtx(), wallet.id, amount)
 	assert.True(t, errors.Is(err, errNotEnoughBalance))
 }
 
@@ -132,11 +143,11 @@ func TestDecreaseBalanceBy_notFound(t *testing.T) {
 	unknownID := "test_id"
 
 	repo.ByIDMock.Return(nil, expectErr)
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
 
-	err := man.DecreaseBalanceBy(unknownID, amount)
+	_, err := man.DecreaseBalanceBy(ownerCtx(), unknownID, amount)
 	assert.True(t, errors.Is(err, expectErr))
 }
 
@@ -145,9 +156,9 @@ func TestDecreaseBalanceBy_incorrectAmount(t *testing.T) {
 	incorrectAmount := float64(0)
 	walletID := "test_id1"
 
-	err := man.DecreaseBalanceBy(walletID, incorrectAmount)
+	_, err := man.DecreaseBalanceBy(ownerCtx(), walletID, incorrectAmount)
 	assert.NotNil(t, err)
-	assert.True(t, errors.Is(err, errAmountLessThanOne))
+	assert.True(t, errors.Is(err, errNonPositiveAmount))
 }
 
 func TestTransferBalance_found(t *testing.T) {
@@ -166,26 +177,31 @@ func TestTransferBalance_found(t *testing.T) {
 		}
 		return nil, fmt.Errorf("unexpected id")
 	})
-	repo.UpdateByIDMock.Set(func(id string, name *string, balance *float64, status *bool) (err error) {
-		assert.Nil(t, name)
-		assert.Nil(t, status)
-		assert.NotNil(t, balance)
+	repo.UpdateByIDMock.Set(func(id string, upd models.WalletUpdate) (err error) {
+		assert.Nil(t, upd.Name)
+		assert.Nil(t, upd.Status)
+		assert.NotNil(t, upd.Balance)
 
 		if id == fromWallet.id {
-			assert.True(t, *balance == fromWallet.balance-amount)
+			assert.True(t, *upd.Balance == fromWallet.balance-amount)
 		} else if id == toWallet.id {
-			assert.True(t, *balance == toWallet.balance+amount)
+			assert.True(t, *upd.Balance == toWallet.balance+amount)
 		} else {
 			return fmt.Errorf("unexpected id")
 		}
 
 		return nil
 	})
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.RecordOperationMock.Expect(models.OperationTransfer, []models.LedgerEntry{
+		{WalletID: fromWallet.id, Amount: -amount, Counterparty: toWallet.id},
+		{WalletID: toWallet.id, Amount: amount, Counterparty: fromWallet.id},
+	}).Return("op_id", nil)
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
+		assert.ElementsMatch(t, []string{fromWallet.id, toWallet.id}, ids)
 		return fn(repo)
 	})
 
-	err := man.TransferBalance(fromWallet.id, toWallet.id, amount)
+	_, err := man.TransferBalance(ownerCtx(), fromWallet.id, toWallet.id, amount)
 	assert.Nil(t, err)
 }
 
@@ -198,11 +214,11 @@ func TestTransferBalance_notFound(t *testing.T) {
 	unknownID2 := "test_id_2"
 
 	repo.ByIDMock.Return(nil, expectErr)
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
 
-	err := man.TransferBalance(unknownID1, unknownID2, amount)
+	_, err := man.TransferBalance(ownerCtx(), unknownID1, unknownID2, amount)
 	assert.NotNil(t, err)
 	assert.True(t, errors.Is(err, expectErr))
 }
@@ -212,7 +228,7 @@ func TestTransferBalance_sameWallet(t *testing.T) {
 	amount := float64(100)
 	walletID := "test_id"
 
-	err := man.TransferBalance(walletID, walletID, amount)
+	_, err := man.TransferBalance(ownerCtx(), walletID, walletID, amount)
 	assert.NotNil(t, err)
 	assert.True(t, errors.Is(err, errSameWallet))
 }
@@ -223,9 +239,9 @@ func TestTransferBalance_incorrectAmount(t *testing.T) {
 	walletID1 := "test_id1"
 	walletID2 := "test_id2"
 
-	err := man.TransferBalance(walletID1, walletID2, incorrectAmount)
+	_, err := man.TransferBalance(ownerCtx(), walletID1, walletID2, incorrectAmount)
 	assert.NotNil(t, err)
-	assert.True(t, errors.Is(err, errAmountLessThanOne))
+	assert.True(t, errors.Is(err, errNonPositiveAmount))
 }
 
 func TestDeactivateByID_found(t *testing.T) {
@@ -235,18 +251,18 @@ func TestDeactivateByID_found(t *testing.T) {
 	wallet := newFakeWallet("from_id", "test_name_from", 999999)
 
 	repo.ByIDMock.Return(wallet, nil)
-	repo.UpdateByIDMock.Set(func(id string, name *string, balance *float64, status *bool) (err error) {
-		assert.Nil(t, name)
-		assert.Nil(t, balance)
-		assert.NotNil(t, status)
-		assert.True(t, *status == false)
+	repo.UpdateByIDMock.Set(func(id string, upd models.WalletUpdate) (err error) {
+		assert.Nil(t, upd.Name)
+		assert.Nil(t, upd.Balance)
+		assert.NotNil(t, upd.Status)
+		assert.True(t, *upd.Status == false)
 		return nil
 	})
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
 
-	err := man.DeactivateByID(wallet.id)
+	err := man.DeactivateByID(ownerCtx(), wallet.id, nil)
 	assert.Nil(t, err)
 }
 
@@ -257,29 +273,111 @@ func TestDeactivateByID_notFound(t *testing.T) {
 	unknownID := "test_id_1"
 
 	repo.ByIDMock.Return(nil, expectErr)
-	repo.TransactionMock.Set(func(fn func(repo models.WalletRepository) error) (err error) {
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
 		return fn(repo)
 	})
 
-	err := man.DeactivateByID(unknownID)
+	err := man.DeactivateByID(ownerCtx(), unknownID, nil)
 	assert.NotNil(t, err)
 	assert.True(t, errors.Is(err, expectErr))
 }
 
+func TestDecreaseBalanceBy_forbidden(t *testing.T) {
+	repo := NewRepositoryMock(t)
+	man := NewManager(repo)
+	wallet := newFakeWallet("test_id", "test_name", 100)
+	ctx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "stranger"})
+
+	repo.ByIDMock.Return(wallet, nil)
+	repo.TransactionMock.Set(func(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) (err error) {
+		return fn(repo)
+	})
+
+	_, err := man.DecreaseBalanceBy(ctx, wallet.id, 10)
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+}
+
+func TestByID_adminBypass(t *testing.T) {
+	repo := NewRepositoryMock(t)
+	man := NewManager(repo)
+	wallet := newFakeWallet("test_id", "test_name", 100)
+	admin := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "admin", Roles: []string{models.RoleAdmin}})
+	stranger := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "stranger"})
+
+	repo.ByIDMock.Return(wallet, nil)
+
+	got, err := man.ByID(admin, wallet.id)
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.id, got.ID())
+
+	_, err = man.ByID(stranger, wallet.id)
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+}
+
+func TestList_ownedOnly(t *testing.T) {
+	repo := NewRepositoryMock(t)
+	man := NewManager(repo)
+	own := newFakeWallet("own_id", "own", 0)
+
+	repo.ListMock.Set(func(filter models.WalletFilter) (*models.WalletPage, error) {
+		assert.Equal(t, testOwner, filter.Owner)
+		assert.Equal(t, 10, filter.Limit)
+		return &models.WalletPage{Wallets: []models.Walleter{own}}, nil
+	})
+
+	got, err := man.List(ownerCtx(), models.WalletFilter{Owner: "stranger", Limit: 10})
+	assert.Nil(t, err)
+	assert.Len(t, got.Wallets, 1)
+	assert.Equal(t, own.id, got.Wallets[0].ID())
+}
+
+func TestDeactivateByID_policyDenied(t *testing.T) {
+	policy := access.NewPolicy(map[string][]models.Permission{
+		"customer": {models.PermWalletRead},
+	})
+	man := NewManager(nil, WithPolicy(policy))
+	ctx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: testOwner, Roles: []string{"customer"}})
+
+	err := man.DeactivateByID(ctx, "test_id", nil)
+	assert.True(t, errors.Is(err, models.ErrForbidden))
+}
+
+func TestByID_policyAnyOwner(t *testing.T) {
+	repo := NewRepositoryMock(t)
+	policy := access.NewPolicy(map[string][]models.Permission{
+		"auditor": {models.PermWalletRead, models.PermWalletAnyOwner},
+	})
+	man := NewManager(repo, WithPolicy(policy))
+	wallet := newFakeWallet("test_id", "test_name", 100)
+	ctx := models.ContextWithPrincipal(context.Background(), &models.Principal{ID: "auditor_1", Roles: []string{"auditor"}})
+
+	repo.ByIDMock.Return(wallet, nil)
+
+	got, err := man.ByID(ctx, wallet.id)
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.id, got.ID())
+}
+
+func ownerCtx() context.Context {
+	return models.ContextWithPrincipal(context.Background(), &models.Principal{ID: testOwner})
+}
+
 func newFakeWallet(id, name string, balance float64) *fakeWallet {
 	return &fakeWallet{
 		id:      id,
 		name:    name,
 		balance: balance,
 		status:  defaultStatus,
+		owner:   testOwner,
 	}
 }
 
 type fakeWallet struct {
-	id      string  `json:"id"`
-	name    string  `json:"name"`
-	balance float64 `json:"balance"`
-	status  bool    `json:"status"`
+	id      string
+	name    string
+	balance float64
+	status  bool
+	owner   string
 }
 
 func (wal *fakeWallet) ID() string {
@@ -294,6 +392,51 @@ func (wal *fakeWallet) Balance() float64 {
 	return wal.balance
 }
 
+func (wal *fakeWallet) Held() float64 {
+	return 0
+}
+
+func (wal *fakeWallet) CreditLimit() float64 {
+	return 0
+}
+
+func (wal *fakeWallet) Available() float64 {
+	return wal.balance
+}
+
 func (wal *fakeWallet) Status() bool {
 	return wal.status
 }
+
+func (wal *fakeWallet) Owner() string {
+	return wal.owner
+}
+
+func (wal *fakeWallet) CreatedAt() time.Time {
+	return time.Time{}
+}
+
+func (wal *fakeWallet) UpdatedAt() time.Time {
+	return time.Time{}
+}
+
+func (wal *fakeWallet) Version() uint64 {
+	return 1
+}
+
+func (wal *fakeWallet) Metadata() map[string]string {
+	return nil
+}
+
+func TestIncreaseBalanceBy_span(t *testing.T) {
+	_, exporter := tracingtest.NewInMemoryProvider()
+	man := NewManager(nil)
+
+	_, err := man.IncreaseBalanceBy(ownerCtx(), "test_id", 0)
+	assert.True(t, errors.Is(err, errNonPositiveAmount))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "wallet.IncreaseBalanceBy", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
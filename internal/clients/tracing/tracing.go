@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const (
+	// ExporterNone спаны не экспортируются.
+	ExporterNone = "none"
+	// ExporterStdout спаны пишутся в stdout в формате json.
+	ExporterStdout = "stdout"
+)
+
+// Provider провайдер трассировки приложения.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// NewProvider конструктор провайдера трассировки.
+// Провайдер и пропагатор контекста регистрируются глобально(otel.SetTracerProvider).
+// service - имя сервиса в ресурсах спанов.
+// exporter - куда отправлять спаны(ExporterNone, ExporterStdout).
+// out - куда писать спаны для ExporterStdout.
+func NewProvider(service, exporter string, out io.Writer) (*Provider, error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(service))),
+	}
+
+	switch exporter {
+	case ExporterNone, "":
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(out))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create stdout exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	default:
+		return nil, fmt.Errorf("unknown exporter %q", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	register(provider)
+
+	return &Provider{provider: provider}, nil
+}
+
+// NewSyncProvider провайдер, синхронно отправляющий спаны в exp, регистрируется глобально как NewProvider.
+func NewSyncProvider(exp sdktrace.SpanExporter) *Provider {
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	register(provider)
+
+	return &Provider{provider: provider}
+}
+
+// Stop отправляем оставшиеся спаны и останавливаем провайдер.
+func (p *Provider) Stop(ctx context.Context) error {
+	return p.provider.Shutdown(ctx)
+}
+
+func register(provider *sdktrace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
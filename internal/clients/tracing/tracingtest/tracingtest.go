@@ -0,0 +1,12 @@
+package tracingtest
+
+import (
+	"github.com/Nizom98/wallet/internal/clients/tracing"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewInMemoryProvider провайдер для тестов, спаны синхронно складываются в память.
+func NewInMemoryProvider() (*tracing.Provider, *tracetest.InMemoryExporter) {
+	exp := tracetest.NewInMemoryExporter()
+	return tracing.NewSyncProvider(exp), exp
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// Config настройки приложения, читаются из json файла.
+type Config struct {
+	Auth   Auth   `json:"auth"`
+	Access Access `json:"access"`
+	Audit  Audit  `json:"audit"`
+	// Limits лимиты операций, без правил операции не ограничиваются.
+	Limits models.LimitRules `json:"limits"`
+	// Amounts правила сумм операций и балансов кошельков.
+	Amounts models.AmountPolicy `json:"amounts"`
+	// Fees правила комиссий, если кошелек сбора комиссий не задан, он создается при запуске.
+	Fees models.FeeRules `json:"fees"`
+	// Scheduler настройки регулярных переводов.
+	Scheduler Scheduler `json:"scheduler"`
+	// Reconcile настройки периодической сверки балансов.
+	Reconcile Reconcile `json:"reconcile"`
+	// Persistence настройки хранения кошельков на диске.
+	Persistence Persistence `json:"persistence"`
+	// Tracing настройки трассировки.
+	Tracing Tracing `json:"tracing"`
+}
+
+// Auth настройки аутентификации.
+type Auth struct {
+	// APIKeys статические ключи доступа.
+	APIKeys []APIKey `json:"api_keys"`
+	// JWKSFile путь к локальному JWKS файлу для проверки подписи JWT.
+	// Если пуст, JWT не принимаются.
+	JWKSFile string `json:"jwks_file"`
+	// Issuer ожидаемый iss в JWT(не проверяется, если пуст).
+	Issuer string `json:"issuer"`
+	// Audience ожидаемый aud в JWT(не проверяется, если пуст).
+	Audience string `json:"audience"`
+}
+
+// APIKey статический ключ доступа.
+type APIKey struct {
+	// ID идентификатор клиента, которому выдан ключ.
+	ID string `json:"id"`
+	// Hash sha256 от ключа в hex, например: printf %s "$API_KEY" | sha256sum.
+	// Сам ключ в настройках не хранится, нулевой хеш примера не совпадает ни с одним ключом.
+	Hash string `json:"hash"`
+	// Roles роли клиента.
+	Roles []string `json:"roles"`
+}
+
+// Access настройки ролевой модели доступа.
+type Access struct {
+	// PolicyFile путь к json файлу политики(роль -> список прав).
+	PolicyFile string `json:"policy_file"`
+}
+
+// Audit настройки журнала аудита.
+type Audit struct {
+	// LogFile файл журнала(json lines), если пуст, журнал ведется только в памяти.
+	LogFile string `json:"log_file"`
+}
+
+// Scheduler настройки регулярных переводов.
+type Scheduler struct {
+	// PollSeconds как часто проверять наступившие переводы, 0 - раз в минуту.
+	PollSeconds int `json:"poll_seconds"`
+	// MaxRetries повторов перевода после ошибки, 0 - по умолчанию.
+	MaxRetries int `json:"max_retries"`
+	// RetryBackoffSeconds пауза перед первым повтором, удваивается с каждым повтором.
+	RetryBackoffSeconds int `json:"retry_backoff_seconds"`
+}
+
+// Reconcile настройки периодической сверки балансов с журналом операций.
+type Reconcile struct {
+	// IntervalSeconds период сверки, 0 - раз в час, отрицательный - сверка только по запросу.
+	IntervalSeconds int `json:"interval_seconds"`
+	// Correct записывать исправления журнала по найденным расхождениям.
+	Correct bool `json:"correct"`
+}
+
+// Persistence настройки хранения кошельков: на диске(снимки и журнал изменений) или в Redis.
+type Persistence struct {
+	// Dir каталог снимков и журнала, если пуст(в режиме wal), кошельки хранятся только в памяти.
+	// В режиме events обязателен.
+	Dir string `json:"dir"`
+	// Mode способ хранения: wal(по умолчанию) - снимки и журнал изменений,
+	// events - поток событий кошельков, interval для fsync в этом режиме не поддерживается,
+	// redis - кошельки в Redis, общие для нескольких экземпляров сервиса: нужен fees.wallet_id,
+	// периодическая сверка в этом режиме не запускается.
+	// Регулярные переводы хранятся там же, где кошельки: в Redis или в каталоге Dir.
+	Mode string `json:"mode"`
+	// Fsync сброс журнала на диск: always(по умолчанию), interval или never.
+	Fsync string `json:"fsync"`
+	// FsyncIntervalMs период сброса журнала для interval, 0 - раз в секунду.
+	FsyncIntervalMs int `json:"fsync_interval_ms"`
+	// SnapshotIntervalSeconds период снимков, 0 - раз в час, отрицательный - без снимков.
+	SnapshotIntervalSeconds int `json:"snapshot_interval_seconds"`
+	// Redis подключение для режима redis.
+	Redis Redis `json:"redis"`
+}
+
+// Tracing настройки трассировки.
+type Tracing struct {
+	// Exporter куда отправлять спаны: none(по умолчанию) или stdout.
+	Exporter string `json:"exporter"`
+}
+
+// Redis настройки подключения к Redis.
+type Redis struct {
+	Addr     string `json:"addr"`
+	Password string `json:"password"`
+	DB       int    `json:"db"`
+	// Prefix префикс ключей хранилища, по умолчанию "wallet:".
+	Prefix string `json:"prefix"`
+	// MaxRetries повторы транзакции при конкурентном изменении ее кошельков, 0 - 16 повторов.
+	MaxRetries int `json:"max_retries"`
+}
+
+// Load читаем настройки из файла path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config %s: %w", path, err)
+	}
+
+	cfg := &Config{}
+	err = json.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,21 @@
+package models
+
+// AmountRules правила сумм и балансов, нулевое значение правила - без ограничения.
+type AmountRules struct {
+	// MinAmount минимальная сумма операции.
+	MinAmount float64 `json:"min_amount"`
+	// MaxAmount максимальная сумма операции.
+	MaxAmount float64 `json:"max_amount"`
+	// Decimals допустимое число знаков после запятой(nil - без ограничения).
+	Decimals *int `json:"decimals,omitempty"`
+	// MaxBalance максимальный баланс кошелька.
+	MaxBalance float64 `json:"max_balance"`
+}
+
+// AmountPolicy политика проверки сумм операций.
+// Все кошельки сервиса в одной валюте, правила задаются для нее.
+type AmountPolicy struct {
+	// Currency валюта кошельков сервиса, указывается в ошибках и выписках.
+	Currency string `json:"currency"`
+	AmountRules
+}
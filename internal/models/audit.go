@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+const (
+	AuditActionCreate       = "create"
+	AuditActionRename       = "rename"
+	AuditActionDeactivate   = "deactivate"
+	AuditActionDeposit      = "deposit"
+	AuditActionWithdraw     = "withdraw"
+	AuditActionTransfer     = "transfer"
+	AuditActionBatch        = "batch_transfer"
+	AuditActionHold         = "hold"
+	AuditActionCapture      = "capture"
+	AuditActionRelease      = "release"
+	AuditActionCreditLimit  = "credit_limit"
+	AuditActionReverse      = "reverse"
+	AuditActionAccessDenied = "access_denied"
+
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeDenied  = "denied"
+)
+
+// AuditRecord запись журнала аудита.
+// Записи связаны в цепочку: Hash считается от PrevHash и содержимого записи,
+// поэтому изменение или удаление любой записи обнаруживается при проверке цепочки.
+type AuditRecord struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	WalletIDs []string  `json:"wallet_ids,omitempty"`
+	Amount    float64   `json:"amount,omitempty"`
+	Fee       float64   `json:"fee,omitempty"`
+	HoldID    string    `json:"hold_id,omitempty"`
+	// OperationID операция журнала операций.
+	OperationID string `json:"operation_id,omitempty"`
+	// Reverses операция, сторнированная операцией OperationID.
+	Reverses  string                       `json:"reverses,omitempty"`
+	Before    map[string]*AuditWalletState `json:"before,omitempty"`
+	After     map[string]*AuditWalletState `json:"after,omitempty"`
+	RequestID string                       `json:"request_id,omitempty"`
+	ClientIP  string                       `json:"client_ip,omitempty"`
+	Outcome   string                       `json:"outcome"`
+	Error     string                       `json:"error,omitempty"`
+	PrevHash  string                       `json:"prev_hash"`
+	Hash      string                       `json:"hash"`
+}
+
+// AuditWalletState состояние кошелька до или после операции.
+type AuditWalletState struct {
+	Name    string  `json:"name"`
+	Balance float64 `json:"balance"`
+	Held    float64 `json:"held,omitempty"`
+	// CreditLimit разрешенный овердрафт, пустой для кошельков без овердрафта.
+	CreditLimit float64 `json:"credit_limit,omitempty"`
+	Status      bool    `json:"status"`
+	Owner       string  `json:"owner"`
+}
+
+// AuditFilter параметры выборки журнала аудита, пустые поля не фильтруют.
+type AuditFilter struct {
+	Actor    string
+	Action   string
+	WalletID string
+	From     time.Time
+	To       time.Time
+	// AfterSeq записи с порядковым номером больше AfterSeq(курсор).
+	AfterSeq uint64
+	Limit    int
+}
+
+// AuditStore хранилище журнала аудита, только добавление записей.
+type AuditStore interface {
+	// Append дописываем запись в конец цепочки, Seq, PrevHash и Hash заполняет хранилище.
+	Append(rec *AuditRecord) error
+	Query(filter AuditFilter) []AuditRecord
+	// Verify проверяем целостность цепочки.
+	Verify() error
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// WalletDump полное состояние кошелька для выгрузки и загрузки хранилища.
+type WalletDump struct {
+	ID          string
+	Name        string
+	Balance     float64
+	CreditLimit float64
+	Status      bool
+	Owner       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     uint64
+	Metadata    map[string]string
+	// Holds действующие блокировки средств кошелька.
+	Holds []Hold
+}
+
+// Dataset все кошельки хранилища вместе с журналом операций.
+type Dataset struct {
+	Wallets []WalletDump
+	// Ledger проводки всех кошельков в порядке записи.
+	Ledger []LedgerEntry
+}
+
+// DatasetStore хранилище, состояние которого можно выгрузить целиком и загрузить обратно.
+type DatasetStore interface {
+	// Dump согласованная копия всего хранилища.
+	Dump() (*Dataset, error)
+	// Restore загружаем кошельки с их идентификаторами и журналом операций: все или ничего.
+	// Кошельки и блокировки с уже существующими идентификаторами не загружаются.
+	Restore(ds *Dataset) error
+}
+
+// DatasetSummary сведения о файле выгрузки.
+type DatasetSummary struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Wallets   int       `json:"wallets"`
+	Entries   int       `json:"entries"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// EventType тип события кошелька.
+type EventType string
+
+const (
+	EventCreated     EventType = "created"
+	EventDeposited   EventType = "deposited"
+	EventWithdrawn   EventType = "withdrawn"
+	EventTransferred EventType = "transferred"
+	EventRenamed     EventType = "renamed"
+	EventDeactivated EventType = "deactivated"
+	// EventActivated деактивированный кошелек снова активен.
+	EventActivated EventType = "activated"
+	// EventOperationRecorded операция журнала остальных типов: начальный баланс, захват блокировки,
+	// сторнирование, исправление.
+	EventOperationRecorded EventType = "operation_recorded"
+	// EventBalanceAdjusted изменение баланса без проводок журнала операций.
+	EventBalanceAdjusted    EventType = "balance_adjusted"
+	EventCreditLimitChanged EventType = "credit_limit_changed"
+	EventHoldPlaced         EventType = "hold_placed"
+	EventHoldClosed         EventType = "hold_closed"
+)
+
+// WalletEvent событие одного кошелька, состояние кошелька - результат применения его событий по порядку.
+type WalletEvent struct {
+	// Seq номер события в потоке, присваивает хранилище событий.
+	Seq      uint64    `json:"seq"`
+	Type     EventType `json:"type"`
+	WalletID string    `json:"wallet_id"`
+	Time     time.Time `json:"time"`
+	// Version версия кошелька после события.
+	Version uint64 `json:"version"`
+
+	// Name название кошелька для EventCreated и EventRenamed.
+	Name     string            `json:"name,omitempty"`
+	Owner    string            `json:"owner,omitempty"`
+	Status   bool              `json:"status,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Operation и Entries операция журнала и ее проводки по кошельку для событий операций.
+	Operation OperationType `json:"operation,omitempty"`
+	Entries   []LedgerEntry `json:"entries,omitempty"`
+	// Amount изменение баланса для EventBalanceAdjusted, новый овердрафт для EventCreditLimitChanged.
+	Amount float64 `json:"amount,omitempty"`
+	// Balance баланс кошелька после события операции или изменения баланса.
+	Balance float64 `json:"balance,omitempty"`
+	// Hold блокировка для EventHoldPlaced, для EventHoldClosed заполнен только ID.
+	Hold *Hold `json:"hold,omitempty"`
+}
+
+// DatasetSnapshot снимок хранилища после события или записи журнала с номером Seq.
+type DatasetSnapshot struct {
+	Seq       uint64    `json:"seq"`
+	CreatedAt time.Time `json:"created_at"`
+	// Offset позиция в хранилище потока после события Seq, 0 - неизвестна(поток читается с начала).
+	Offset  int64   `json:"offset,omitempty"`
+	Dataset Dataset `json:"dataset"`
+}
+
+// EventStore поток событий кошельков и снимки построенных по нему проекций.
+type EventStore interface {
+	// Append дописываем события одной транзакции: все или ничего, номера присваиваются по порядку.
+	Append(events []WalletEvent) error
+	// Events события с номером больше after в порядке записи.
+	Events(after uint64, fn func(ev WalletEvent) error) error
+	// LastSeq номер последнего события.
+	LastSeq() uint64
+	SaveSnapshot(snap *DatasetSnapshot) error
+	// LatestSnapshot последний снимок, nil если снимков нет.
+	LatestSnapshot() (*DatasetSnapshot, error)
+}
@@ -0,0 +1,28 @@
+package models
+
+// FeeTier ступень тарифа: применяется к суммам не больше UpTo.
+type FeeTier struct {
+	// UpTo верхняя граница суммы, 0 - без границы(последняя ступень).
+	UpTo    float64 `json:"up_to"`
+	Flat    float64 `json:"flat"`
+	Percent float64 `json:"percent"`
+}
+
+// FeeRule правило комиссии операции: Flat + Percent от суммы
+// либо, если заданы ступени, Flat + Percent подходящей ступени.
+// Результат ограничивается снизу Min и сверху Max(0 - без ограничения).
+type FeeRule struct {
+	Flat    float64   `json:"flat"`
+	Percent float64   `json:"percent"`
+	Tiers   []FeeTier `json:"tiers"`
+	Min     float64   `json:"min"`
+	Max     float64   `json:"max"`
+}
+
+// FeeRules настройки комиссий.
+type FeeRules struct {
+	// WalletID кошелек, на который зачисляются комиссии.
+	WalletID string `json:"wallet_id"`
+	// Operations правила по типу операции, поддерживаются withdraw и transfer.
+	Operations map[OperationType]FeeRule `json:"operations"`
+}
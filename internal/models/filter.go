@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// SortByCreated порядок создания кошельков(по умолчанию).
+	SortByCreated = "created"
+	SortByUpdated = "updated"
+	SortByName    = "name"
+	SortByBalance = "balance"
+)
+
+// ErrInvalidArgument некорректные параметры запроса.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// WalletFilter параметры выборки списка кошельков, пустые поля не фильтруют.
+// NamePrefix и NameContains сравниваются с именем кошелька без учета регистра.
+type WalletFilter struct {
+	Owner        string
+	Status       *bool
+	NamePrefix   string
+	NameContains string
+	MinBalance   *float64
+	MaxBalance   *float64
+	// UpdatedSince кошельки, измененные не раньше указанного времени(инкрементальная синхронизация).
+	UpdatedSince time.Time
+	// Sort поле сортировки(SortByCreated, SortByUpdated, SortByName, SortByBalance).
+	Sort string
+	Desc bool
+	// Cursor продолжение выборки, берется из WalletPage.NextCursor предыдущей страницы.
+	Cursor string
+	// Limit размер страницы, 0 - без ограничения.
+	Limit int
+}
+
+// WalletPage страница списка кошельков.
+type WalletPage struct {
+	Wallets []Walleter
+	// NextCursor курсор следующей страницы, пустой если страница последняя.
+	NextCursor string
+}
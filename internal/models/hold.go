@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// HoldStatus состояние блокировки средств.
+type HoldStatus string
+
+const (
+	HoldStatusActive   HoldStatus = "active"
+	HoldStatusCaptured HoldStatus = "captured"
+	HoldStatusReleased HoldStatus = "released"
+	// HoldStatusExpired срок блокировки истек, средства снова доступны.
+	HoldStatusExpired HoldStatus = "expired"
+)
+
+// Hold блокировка(авторизация) средств кошелька.
+// Активная блокировка уменьшает доступный баланс, не меняя баланс кошелька.
+type Hold struct {
+	ID       string
+	WalletID string
+	Amount   float64
+	Status   HoldStatus
+	// Captured списанная сумма захваченной блокировки, остаток освобождается.
+	Captured float64
+	// CapturedTo кошелек получателя при захвате переводом, пустой при захвате списанием.
+	CapturedTo string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	// Fee комиссия, списанная при захвате сверх Captured.
+	Fee float64
+	// OperationID операция журнала, которой записан захват.
+	OperationID string
+}
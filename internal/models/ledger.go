@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// OperationType тип операции журнала операций.
+type OperationType string
+
+const (
+	OperationDeposit  OperationType = "deposit"
+	OperationWithdraw OperationType = "withdraw"
+	OperationTransfer OperationType = "transfer"
+	OperationBatch    OperationType = "batch_transfer"
+	// OperationOpening начальный баланс кошелька при создании.
+	OperationOpening OperationType = "opening"
+	// OperationCorrection исправление журнала по результату сверки балансов, баланс кошелька не меняет.
+	OperationCorrection OperationType = "correction"
+	// OperationReversal сторнирование(полный или частичный возврат) другой операции.
+	OperationReversal OperationType = "reversal"
+)
+
+// LedgerEntry проводка: изменение баланса одного кошелька в рамках операции.
+type LedgerEntry struct {
+	OperationID string        `json:"operation_id"`
+	Type        OperationType `json:"type"`
+	WalletID    string        `json:"wallet_id"`
+	// Amount изменение баланса: положительное - зачисление, отрицательное - списание.
+	Amount float64 `json:"amount"`
+	// Balance баланс кошелька после проводки.
+	Balance float64 `json:"balance"`
+	// Counterparty второй кошелек перевода.
+	Counterparty string `json:"counterparty,omitempty"`
+	// Fee проводка комиссии операции.
+	Fee bool `json:"fee,omitempty"`
+	// Reverses операция, которую компенсирует проводка сторнирования.
+	Reverses string    `json:"reverses,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// LedgerFilter параметры выборки проводок, пустые поля не фильтруют.
+type LedgerFilter struct {
+	WalletID    string
+	OperationID string
+	// Reverses проводки сторнирования операции Reverses.
+	Reverses string
+	// From проводки не раньше From.
+	From time.Time
+	// To проводки раньше To.
+	To time.Time
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded операция превышает лимит.
+var ErrLimitExceeded = errors.New("limit_exceeded")
+
+// Limits правила лимитов, нулевое значение правила - без ограничения.
+// Объем и число считаются по списаниям с кошелька за текущие сутки и месяц(UTC).
+type Limits struct {
+	// MaxAmount максимальная сумма одной операции.
+	MaxAmount     float64 `json:"max_amount"`
+	DailyVolume   float64 `json:"daily_volume"`
+	DailyCount    int     `json:"daily_count"`
+	MonthlyVolume float64 `json:"monthly_volume"`
+	MonthlyCount  int     `json:"monthly_count"`
+}
+
+// LimitRules лимиты для всех кошельков и для отдельных кошельков, применяются и те и другие.
+type LimitRules struct {
+	Global Limits `json:"global"`
+	// Wallets лимиты по идентификатору кошелька.
+	Wallets map[string]Limits `json:"wallets"`
+}
+
+// LimitError сработавшее правило лимита.
+type LimitError struct {
+	// Scope global или wallet.
+	Scope    string
+	WalletID string
+	// Rule правило: max_amount, daily_volume, daily_count, monthly_volume, monthly_count.
+	Rule  string
+	Limit float64
+	// Value значение с учетом отклоненной операции.
+	Value float64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s rule %s for wallet %s: %g exceeds %g", ErrLimitExceeded, e.Scope, e.Rule, e.WalletID, e.Value, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
@@ -0,0 +1,36 @@
+package models
+
+// Permission право на операцию, роли клиентов раскрываются в набор прав политикой доступа.
+type Permission string
+
+const (
+	PermWalletCreate     Permission = "wallet:create"
+	PermWalletRead       Permission = "wallet:read"
+	PermWalletList       Permission = "wallet:list"
+	PermWalletDeposit    Permission = "wallet:deposit"
+	PermWalletWithdraw   Permission = "wallet:withdraw"
+	PermWalletTransfer   Permission = "wallet:transfer"
+	PermWalletRename     Permission = "wallet:rename"
+	PermWalletDeactivate Permission = "wallet:deactivate"
+	// PermWalletHold блокировка средств, ее захват и освобождение.
+	PermWalletHold Permission = "wallet:hold"
+	// PermWalletCredit изменение овердрафта кошелька.
+	PermWalletCredit Permission = "wallet:credit"
+	// PermWalletReverse сторнирование операций.
+	PermWalletReverse Permission = "wallet:reverse"
+	// PermWalletAnyOwner доступ к кошелькам других клиентов.
+	PermWalletAnyOwner Permission = "wallet:any_owner"
+
+	PermAuditRead Permission = "audit:read"
+	// PermReconcile запуск сверки балансов и просмотр ее результата.
+	PermReconcile Permission = "reconcile:run"
+	// PermDatasetExport выгрузка всех кошельков с журналом операций.
+	PermDatasetExport Permission = "dataset:export"
+	// PermDatasetImport загрузка выгрузки в хранилище.
+	PermDatasetImport Permission = "dataset:import"
+	// PermRebuild перестроение проекций кошельков по потоку событий.
+	PermRebuild Permission = "projections:rebuild"
+
+	// PermAll все права.
+	PermAll Permission = "*"
+)
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"context"
+	"errors"
+)
+
+// RoleAdmin роль администратора, имеет доступ ко всем кошелькам.
+const RoleAdmin = "admin"
+
+const (
+	// MethodAPIKey клиент аутентифицирован статическим ключом, его роли заданы в настройках.
+	MethodAPIKey = "api_key"
+	// MethodJWT клиент аутентифицирован JWT, его роли известны только из токена.
+	MethodJWT = "jwt"
+)
+
+// ErrForbidden у клиента нет прав на операцию.
+var ErrForbidden = errors.New("forbidden")
+
+// Principal аутентифицированный клиент API.
+type Principal struct {
+	// ID идентификатор клиента(sub из JWT или id API ключа).
+	ID string
+	// Roles роли клиента.
+	Roles []string
+	// Method способ аутентификации(api_key, jwt).
+	Method string
+}
+
+// HasRole есть ли у клиента роль role.
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal сохраняем клиента в контексте запроса.
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext получаем клиента из контекста.
+// Если запрос не аутентифицирован, вернется nil.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// BalanceTolerance расхождение баланса с суммой проводок меньше погрешности их сложения не считается ошибкой.
+const BalanceTolerance = 1e-6
+
+// BalanceMismatch расхождение баланса кошелька с балансом, пересчитанным по журналу операций.
+type BalanceMismatch struct {
+	WalletID string `json:"wallet_id"`
+	// Stored баланс кошелька в хранилище.
+	Stored float64 `json:"stored"`
+	// Expected сумма проводок кошелька в журнале операций.
+	Expected float64 `json:"expected"`
+	// Difference Stored - Expected.
+	Difference float64 `json:"difference"`
+	// CorrectionID операция исправления журнала, пустая если исправление не записывалось.
+	CorrectionID string `json:"correction_id,omitempty"`
+}
+
+// ReconcileReport результат сверки балансов кошельков с журналом операций.
+type ReconcileReport struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Wallets число проверенных кошельков.
+	Wallets    int               `json:"wallets"`
+	Mismatches []BalanceMismatch `json:"mismatches"`
+	// Corrected по расхождениям записаны исправления журнала.
+	Corrected bool `json:"corrected"`
+}
@@ -1,9 +1,51 @@
 package models
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrVersionMismatch версия кошелька изменилась с момента чтения(конкурентное обновление).
+var ErrVersionMismatch = errors.New("wallet version mismatch")
+
+// ErrNotFound запрошенный объект(кошелек, блокировка, операция, регулярный перевод) не найден.
+var ErrNotFound = errors.New("not found")
+
+// WalletUpdate изменения кошелька, поля равные nil не обновляются.
+type WalletUpdate struct {
+	Name    *string
+	Balance *float64
+	Status  *bool
+	// CreditLimit разрешенный овердрафт кошелька.
+	CreditLimit *float64
+	// ExpectedVersion если задана, обновление применяется только при совпадении
+	// с текущей версией кошелька(compare-and-set), иначе ErrVersionMismatch.
+	ExpectedVersion *uint64
+}
+
 type WalletRepository interface {
-	Create(name string, balance float64, status bool) Walleter
+	Create(name string, balance float64, status bool, owner string, metadata map[string]string) Walleter
 	ByID(id string) (Walleter, error)
 	All() []Walleter
-	Transaction(fn func(repo WalletRepository) error) error
-	UpdateByID(id string, name *string, balance *float64, status *bool) error
+	// List выборка кошельков по фильтру с сортировкой и постраничной навигацией.
+	List(filter WalletFilter) (*WalletPage, error)
+	// Transaction выполняем fn атомарно относительно других транзакций.
+	// ids - кошельки, которые затрагивает транзакция, пустой ids - доступ ко всему хранилищу.
+	// Если fn вернула ошибку, изменения транзакции не применяются.
+	// При конфликте хранилище может выполнить fn повторно, поэтому fn не должна
+	// накапливать результат в захваченных переменных: он публикуется только после фиксации.
+	Transaction(ctx context.Context, ids []string, fn func(repo WalletRepository) error) error
+	UpdateByID(id string, upd WalletUpdate) error
+	// CreateHold блокируем amount на кошельке до expiresAt.
+	CreateHold(walletID string, amount float64, expiresAt time.Time) (Hold, error)
+	// HoldByID блокировка по идентификатору, истекшая блокировка имеет статус HoldStatusExpired.
+	HoldByID(id string) (Hold, error)
+	// CloseHold снимаем активную или истекшую блокировку с кошелька и возвращаем ее.
+	CloseHold(id string) (Hold, error)
+	// RecordOperation записываем проводки операции в журнал операций и возвращаем идентификатор операции.
+	// Записывается после изменения балансов: Balance проводок заполняет хранилище.
+	RecordOperation(opType OperationType, entries []LedgerEntry) (string, error)
+	// Ledger проводки по фильтру в порядке записи.
+	Ledger(filter LedgerFilter) ([]LedgerEntry, error)
 }
@@ -0,0 +1,26 @@
+package models
+
+import "context"
+
+// RequestMeta метаданные входящего запроса.
+type RequestMeta struct {
+	ID       string
+	ClientIP string
+}
+
+type requestMetaKey struct{}
+
+// ContextWithRequestMeta сохраняем метаданные запроса в контексте.
+func ContextWithRequestMeta(ctx context.Context, meta *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext получаем метаданные запроса из контекста.
+// Если их нет, вернется пустая структура.
+func RequestMetaFromContext(ctx context.Context) *RequestMeta {
+	meta, ok := ctx.Value(requestMetaKey{}).(*RequestMeta)
+	if !ok {
+		return &RequestMeta{}
+	}
+	return meta
+}
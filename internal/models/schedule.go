@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// ScheduleStatus состояние регулярного перевода.
+type ScheduleStatus string
+
+const (
+	ScheduleStatusActive ScheduleStatus = "active"
+	// ScheduleStatusPaused перевод приостановлен, может быть возобновлен.
+	ScheduleStatusPaused ScheduleStatus = "paused"
+	// ScheduleStatusCancelled перевод отменен и больше не выполняется.
+	ScheduleStatusCancelled ScheduleStatus = "cancelled"
+)
+
+// NewSchedule параметры нового регулярного перевода.
+// Задается либо Interval, либо Cron.
+type NewSchedule struct {
+	FromID string
+	ToID   string
+	Amount float64
+	// Interval период перевода.
+	Interval time.Duration
+	// Cron расписание в формате cron(минута час день месяц день_недели) по UTC.
+	Cron string
+	// StartAt время первого перевода по интервалу, пустое - через Interval от создания.
+	StartAt time.Time
+}
+
+// Schedule регулярный перевод(постоянное поручение).
+// Переводы выполняются от имени клиента, создавшего перевод.
+type Schedule struct {
+	ID string
+	// Owner клиент, создавший перевод, его роли определяются при каждом переводе.
+	Owner    string
+	FromID   string
+	ToID     string
+	Amount   float64
+	Interval time.Duration
+	Cron     string
+	// StartAt от него отсчитываются переводы по интервалу.
+	StartAt time.Time
+	Status  ScheduleStatus
+	// NextRun время следующей попытки перевода(с учетом повторов).
+	NextRun time.Time
+	// Attempt номер повтора текущего перевода, 0 - первая попытка.
+	Attempt int
+	LastRun time.Time
+	// LastOperationID операция журнала последнего успешного перевода.
+	LastOperationID string
+	// LastError ошибка последней попытки, пустая после успешного перевода.
+	LastError string
+	// Failures число переводов, не выполненных после всех повторов.
+	Failures  int
+	CreatedAt time.Time
+	// OwnerMethod способ аутентификации владельца при создании перевода.
+	OwnerMethod string
+	// OwnerRoles роли владельца JWT из токена, которым создан перевод: других источников его ролей нет.
+	// Права по этим ролям проверяются по текущей политике доступа при каждом переводе.
+	// Для владельца API ключа пусто, его роли берутся из настроек.
+	OwnerRoles []string
+}
+
+// ScheduleFilter параметры выборки регулярных переводов, пустые поля не ограничивают выборку.
+type ScheduleFilter struct {
+	Owner  string
+	Status ScheduleStatus
+}
+
+// ScheduleStore хранилище регулярных переводов.
+type ScheduleStore interface {
+	// Create сохраняем перевод, ID заполняет хранилище.
+	Create(schedule Schedule) (Schedule, error)
+	ByID(id string) (Schedule, error)
+	// List переводы в порядке создания.
+	List(filter ScheduleFilter) ([]Schedule, error)
+	// Update изменяем перевод функцией fn, если fn вернула ошибку, перевод не меняется.
+	// Хранилище, общее для экземпляров сервиса, может вызвать fn несколько раз.
+	Update(id string, fn func(schedule *Schedule) error) (Schedule, error)
+	// Due активные переводы, время попытки которых наступило к now.
+	// Хранилище, общее для экземпляров сервиса, отдает каждую попытку только одному экземпляру.
+	Due(now time.Time) ([]Schedule, error)
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Statement выписка по кошельку за период [From, To).
+type Statement struct {
+	WalletID   string    `json:"wallet_id"`
+	WalletName string    `json:"wallet_name"`
+	Owner      string    `json:"owner"`
+	Currency   string    `json:"currency,omitempty"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	// OpeningBalance баланс на начало периода.
+	OpeningBalance float64 `json:"opening_balance"`
+	// ClosingBalance баланс на конец периода.
+	ClosingBalance float64 `json:"closing_balance"`
+	// TotalCredit и TotalDebit суммы зачислений и списаний за период.
+	TotalCredit float64         `json:"total_credit"`
+	TotalDebit  float64         `json:"total_debit"`
+	Lines       []StatementLine `json:"lines"`
+}
+
+// StatementLine проводка выписки с балансом после нее.
+type StatementLine struct {
+	Time         time.Time     `json:"time"`
+	OperationID  string        `json:"operation_id"`
+	Type         OperationType `json:"type"`
+	Counterparty string        `json:"counterparty,omitempty"`
+	Fee          bool          `json:"fee,omitempty"`
+	Reverses     string        `json:"reverses,omitempty"`
+	// Amount положительное - зачисление, отрицательное - списание.
+	Amount  float64 `json:"amount"`
+	Balance float64 `json:"balance"`
+}
@@ -1,21 +1,111 @@
 package models
 
+import (
+	"context"
+	"time"
+)
+
 type Walleter interface {
 	ID() string
 	Name() string
+	// Balance баланс кошелька с учетом заблокированных средств.
 	Balance() float64
+	// Held сумма активных блокировок.
+	Held() float64
+	// CreditLimit разрешенный овердрафт: баланс может уйти в минус не больше чем на CreditLimit.
+	CreditLimit() float64
+	// Available доступная для списания сумма: Balance за вычетом Held плюс CreditLimit.
+	Available() float64
 	Status() bool
+	// Owner идентификатор клиента-владельца кошелька.
+	Owner() string
+	CreatedAt() time.Time
+	UpdatedAt() time.Time
+	// Version увеличивается при каждом изменении кошелька.
+	Version() uint64
+	// Metadata произвольные метки кошелька, задаются при создании.
+	Metadata() map[string]string
 }
 
 type WalletManager interface {
-	Create(name string) (Walleter, error)
-	ByID(id string) (Walleter, error)
-	List() []Walleter
-	IncreaseBalanceBy(id string, amount float64) error
-	DecreaseBalanceBy(id string, amount float64) error
-	TransferBalance(fromID, toID string, amount float64) error
-	DeactivateByID(id string) error
-	UpdateName(id, name string) error
+	Create(ctx context.Context, name string) (Walleter, error)
+	ByID(ctx context.Context, id string) (Walleter, error)
+	List(ctx context.Context, filter WalletFilter) (*WalletPage, error)
+	IncreaseBalanceBy(ctx context.Context, id string, amount float64) (OperationResult, error)
+	// DecreaseBalanceBy и TransferBalance списывают с кошелька сумму вместе с комиссией.
+	DecreaseBalanceBy(ctx context.Context, id string, amount float64) (OperationResult, error)
+	TransferBalance(ctx context.Context, fromID, toID string, amount float64) (OperationResult, error)
+	// BalanceAt баланс кошелька на момент at по журналу операций(сумма проводок, записанных раньше at).
+	BalanceAt(ctx context.Context, id string, at time.Time) (WalletBalance, error)
+	// BalancesAt балансы всех доступных клиенту кошельков, созданных не позже at, на момент at.
+	BalancesAt(ctx context.Context, at time.Time) ([]WalletBalance, error)
+	// CreateBulk создаем все кошельки в одной транзакции или ни одного.
+	CreateBulk(ctx context.Context, items []NewWallet) ([]BulkCreateResult, error)
+	// TransferBatch атомарно выполняем все переводы или ни одного.
+	TransferBatch(ctx context.Context, legs []TransferLeg) (BatchResult, error)
+	// CreateHold блокируем средства кошелька на ttl без списания.
+	CreateHold(ctx context.Context, walletID string, amount float64, ttl time.Duration) (Hold, error)
+	// CaptureHold списываем amount(0 - всю сумму) заблокированных средств, остаток освобождается.
+	// При непустом toID списанная сумма переводится в кошелек toID.
+	CaptureHold(ctx context.Context, holdID string, amount float64, toID string) (Hold, error)
+	// ReleaseHold снимаем блокировку без списания.
+	ReleaseHold(ctx context.Context, holdID string) (Hold, error)
+	// ReverseOperation сторнируем операцию журнала: amount(0 - весь несторнированный остаток)
+	// возвращается компенсирующими проводками, доступно только администраторам.
+	ReverseOperation(ctx context.Context, operationID string, amount float64) (OperationResult, error)
+	// SetCreditLimit меняем овердрафт кошелька, доступно только администраторам.
+	SetCreditLimit(ctx context.Context, id string, limit float64, version *uint64) error
+	// DeactivateByID и UpdateName при заданной version применяются только к этой версии кошелька.
+	DeactivateByID(ctx context.Context, id string, version *uint64) error
+	UpdateName(ctx context.Context, id, name string, version *uint64) error
+}
+
+// NewWallet параметры создаваемого кошелька.
+type NewWallet struct {
+	Name     string
+	Metadata map[string]string
 }
 
+// BulkCreateResult результат создания одного кошелька из пакета.
+// Заполняется либо Wallet, либо Err.
+type BulkCreateResult struct {
+	Wallet Walleter
+	Err    error
+}
+
+// OperationResult результат операции с балансом.
+type OperationResult struct {
+	// OperationID идентификатор операции в журнале операций.
+	OperationID string
+	Amount      float64
+	// Fee комиссия, списанная сверх Amount, для сторнирования - возвращенная комиссия.
+	Fee float64
+	// Reverses сторнированная операция.
+	Reverses string
+}
 
+// BatchResult результат пакетного перевода.
+type BatchResult struct {
+	// OperationID идентификатор операции в журнале операций.
+	OperationID string
+	// Amount сумма переводов пакета без комиссий.
+	Amount float64
+	// Fee сумма комиссий пакета, списанных сверх Amount.
+	Fee float64
+	// Fees комиссия каждого перевода в порядке переводов пакета.
+	Fees []float64
+}
+
+// WalletBalance баланс кошелька на момент времени At.
+type WalletBalance struct {
+	WalletID string
+	Balance  float64
+	At       time.Time
+}
+
+// TransferLeg один перевод в пакетном переводе.
+type TransferLeg struct {
+	FromID string
+	ToID   string
+	Amount float64
+}
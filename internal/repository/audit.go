@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+var errAuditChainBroken = errors.New("audit chain broken")
+
+// AuditStore журнал аудита с цепочкой хешей.
+// Записи дописываются в конец файла(json lines) и дублируются в памяти для выборок.
+type AuditStore struct {
+	// muRecords для конкурентного доступа к records и file
+	muRecords *sync.RWMutex
+	records   []models.AuditRecord
+	// file файл журнала, nil если журнал ведется только в памяти
+	file *os.File
+	path string
+	// err ошибка записи, после нее журнал не принимает записей: в файле может остаться часть строки
+	err error
+}
+
+// NewAuditStore конструктор журнала аудита.
+// path - файл журнала, существующие записи читаются и проверяются,
+// оборванная при сбое последняя запись отрезается.
+// Если path пустой, журнал ведется только в памяти.
+func NewAuditStore(path string) (*AuditStore, error) {
+	store := &AuditStore{
+		muRecords: new(sync.RWMutex),
+		path:      path,
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	records, err := readAuditFile(path, true)
+	if err != nil {
+		return nil, err
+	}
+	store.records = records
+
+	err = verifyAuditChain(records)
+	if err != nil {
+		return nil, fmt.Errorf("audit log %s: %w", path, err)
+	}
+
+	store.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log %s: %w", path, err)
+	}
+
+	return store, nil
+}
+
+// Close закрываем файл журнала.
+func (store *AuditStore) Close() error {
+	if store.file == nil {
+		return nil
+	}
+	return store.file.Close()
+}
+
+// Append дописываем запись в конец цепочки.
+// Запись считается добавленной только после сброса файла на диск.
+// После ошибки записи в файл журнал перестает принимать записи до перезапуска.
+func (store *AuditStore) Append(rec *models.AuditRecord) error {
+	store.muRecords.Lock()
+	defer store.muRecords.Unlock()
+
+	if store.err != nil {
+		return fmt.Errorf("audit log is broken by previous write: %w", store.err)
+	}
+
+	rec.Time = rec.Time.UTC()
+	rec.Seq = uint64(len(store.records)) + 1
+	rec.PrevHash = ""
+	if len(store.records) > 0 {
+		rec.PrevHash = store.records[len(store.records)-1].Hash
+	}
+	hash, err := auditHash(rec)
+	if err != nil {
+		return err
+	}
+	rec.Hash = hash
+
+	if store.file != nil {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("cannot marshal audit record: %w", err)
+		}
+		_, err = store.file.Write(append(line, '\n'))
+		if err != nil {
+			store.err = err
+			return fmt.Errorf("cannot write audit record: %w", err)
+		}
+		err = store.file.Sync()
+		if err != nil {
+			store.err = err
+			return fmt.Errorf("cannot sync audit log: %w", err)
+		}
+	}
+
+	store.records = append(store.records, *rec)
+	return nil
+}
+
+// Query выборка записей по фильтру в порядке добавления.
+func (store *AuditStore) Query(filter models.AuditFilter) []models.AuditRecord {
+	store.muRecords.RLock()
+	defer store.muRecords.RUnlock()
+
+	out := make([]models.AuditRecord, 0)
+	for _, rec := range store.records {
+		if filter.Limit > 0 && len(out) >= filter.Limit {
+			break
+		}
+		if auditMatch(&rec, filter) {
+			out = append(out, rec)
+		}
+	}
+
+	return out
+}
+
+// Verify пересчитываем хеши всех записей и проверяем связность цепочки.
+// Если журнал ведется в файле, проверяется файл: он перечитывается и должен совпадать с записями в памяти.
+func (store *AuditStore) Verify() error {
+	store.muRecords.RLock()
+	defer store.muRecords.RUnlock()
+
+	if store.path == "" {
+		return verifyAuditChain(store.records)
+	}
+
+	records, err := readAuditFile(store.path, false)
+	if err != nil {
+		return fmt.Errorf("%s: %w", err.Error(), errAuditChainBroken)
+	}
+	err = verifyAuditChain(records)
+	if err != nil {
+		return err
+	}
+	if len(records) != len(store.records) {
+		return fmt.Errorf("file has %d records, expected %d: %w", len(records), len(store.records), errAuditChainBroken)
+	}
+	for i := range records {
+		if records[i].Hash != store.records[i].Hash {
+			return fmt.Errorf("record %d: file differs from written record: %w", records[i].Seq, errAuditChainBroken)
+		}
+	}
+
+	return nil
+}
+
+// verifyAuditChain пересчитываем хеши записей и проверяем связность цепочки.
+func verifyAuditChain(records []models.AuditRecord) error {
+	prevHash := ""
+	for i := range records {
+		rec := records[i]
+		if rec.Seq != uint64(i)+1 {
+			return fmt.Errorf("record %d: unexpected seq %d: %w", i+1, rec.Seq, errAuditChainBroken)
+		}
+		if rec.PrevHash != prevHash {
+			return fmt.Errorf("record %d: prev hash mismatch: %w", rec.Seq, errAuditChainBroken)
+		}
+		hash, err := auditHash(&rec)
+		if err != nil {
+			return err
+		}
+		if hash != rec.Hash {
+			return fmt.Errorf("record %d: hash mismatch: %w", rec.Seq, errAuditChainBroken)
+		}
+		prevHash = rec.Hash
+	}
+
+	return nil
+}
+
+// auditHash sha256 от хеша предыдущей записи и содержимого записи(без поля Hash).
+func auditHash(rec *models.AuditRecord) (string, error) {
+	content := *rec
+	content.Hash = ""
+	data, err := json.Marshal(&content)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal audit record: %w", err)
+	}
+
+	sum := sha256.New()
+	sum.Write([]byte(rec.PrevHash))
+	sum.Write(data)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+func auditMatch(rec *models.AuditRecord, filter models.AuditFilter) bool {
+	if rec.Seq <= filter.AfterSeq {
+		return false
+	}
+	if filter.Actor != "" && rec.Actor != filter.Actor {
+		return false
+	}
+	if filter.Action != "" && rec.Action != filter.Action {
+		return false
+	}
+	if !filter.From.IsZero() && rec.Time.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !rec.Time.Before(filter.To) {
+		return false
+	}
+	if filter.WalletID != "" {
+		found := false
+		for _, id := range rec.WalletIDs {
+			if id == filter.WalletID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readAuditFile читаем записи журнала.
+// Если truncateTorn, оборванная при сбое последняя строка отрезается, как в журнале изменений.
+func readAuditFile(path string, truncateTorn bool) ([]models.AuditRecord, error) {
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log %s: %w", path, err)
+	}
+
+	var records []models.AuditRecord
+	err = readFrames(path, 0, truncateTorn, func(line []byte) error {
+		var rec models.AuditRecord
+		err := json.Unmarshal(line, &rec)
+		if err != nil {
+			// строка без конца или с неполным json считается поврежденной записью
+			return fmt.Errorf("record %d: %s: %w", len(records)+1, err.Error(), errCorruptedFrame)
+		}
+		records = append(records, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("audit log: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditStore_chain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	store, err := NewAuditStore(path)
+	assert.Nil(t, err)
+
+	for _, action := range []string{models.AuditActionCreate, models.AuditActionDeposit, models.AuditActionTransfer} {
+		err = store.Append(&models.AuditRecord{
+			Time:      time.Now(),
+			Actor:     "test_actor",
+			Action:    action,
+			WalletIDs: []string{"test_id"},
+			Outcome:   models.AuditOutcomeSuccess,
+		})
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, store.Verify())
+	assert.Nil(t, store.Close())
+
+	reopened, err := NewAuditStore(path)
+	assert.Nil(t, err)
+	records := reopened.Query(models.AuditFilter{})
+	assert.Len(t, records, 3)
+	assert.Equal(t, records[0].Hash, records[1].PrevHash)
+	assert.Nil(t, reopened.Close())
+}
+
+func TestAuditStore_tampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	store, err := NewAuditStore(path)
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Append(&models.AuditRecord{Time: time.Now(), Action: models.AuditActionDeposit, Amount: 10}))
+	assert.Nil(t, store.Append(&models.AuditRecord{Time: time.Now(), Action: models.AuditActionWithdraw, Amount: 5}))
+	assert.Nil(t, store.Close())
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	tampered := strings.Replace(string(data), `"amount":10`, `"amount":1000`, 1)
+	assert.Nil(t, os.WriteFile(path, []byte(tampered), 0o600))
+
+	_, err = NewAuditStore(path)
+	assert.True(t, errors.Is(err, errAuditChainBroken))
+}
+
+func TestAuditStore_query(t *testing.T) {
+	store, err := NewAuditStore("")
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Append(&models.AuditRecord{Time: time.Now(), Actor: "a", Action: models.AuditActionDeposit, WalletIDs: []string{"w1"}}))
+	assert.Nil(t, store.Append(&models.AuditRecord{Time: time.Now(), Actor: "b", Action: models.AuditActionTransfer, WalletIDs: []string{"w1", "w2"}}))
+	assert.Nil(t, store.Append(&models.AuditRecord{Time: time.Now(), Actor: "a", Action: models.AuditActionRename, WalletIDs: []string{"w3"}}))
+
+	assert.Len(t, store.Query(models.AuditFilter{Actor: "a"}), 2)
+	assert.Len(t, store.Query(models.AuditFilter{WalletID: "w2"}), 1)
+	assert.Len(t, store.Query(models.AuditFilter{AfterSeq: 1, Limit: 1}), 1)
+	assert.Equal(t, uint64(2), store.Query(models.AuditFilter{AfterSeq: 1, Limit: 1})[0].Seq)
+}
+
+func TestAuditStore_tornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	store, err := NewAuditStore(path)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Append(&models.AuditRecord{Time: time.Now(), Action: models.AuditActionDeposit, Amount: 10}))
+	assert.Nil(t, store.Close())
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	assert.Nil(t, err)
+	_, err = file.WriteString(`{"seq":2,"action":"with`)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	reopened, err := NewAuditStore(path)
+	assert.Nil(t, err)
+	defer reopened.Close()
+	assert.Len(t, reopened.Query(models.AuditFilter{}), 1)
+	assert.Nil(t, reopened.Append(&models.AuditRecord{Time: time.Now(), Action: models.AuditActionWithdraw, Amount: 5}))
+	assert.Nil(t, reopened.Verify())
+}
+
+func TestAuditStore_failedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	store, err := NewAuditStore(path)
+	assert.Nil(t, err)
+	writable := store.file
+	defer writable.Close()
+
+	store.file, err = os.Open(path)
+	assert.Nil(t, err)
+	assert.NotNil(t, store.Append(&models.AuditRecord{Time: time.Now(), Action: models.AuditActionDeposit}))
+	assert.Nil(t, store.file.Close())
+
+	// после ошибки записи журнал не дописывается, даже если файл снова доступен
+	store.file = writable
+	assert.NotNil(t, store.Append(&models.AuditRecord{Time: time.Now(), Action: models.AuditActionDeposit}))
+	assert.Len(t, store.Query(models.AuditFilter{}), 0)
+}
+
+func TestAuditStore_verifyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	store, err := NewAuditStore(path)
+	assert.Nil(t, err)
+	defer store.Close()
+	assert.Nil(t, store.Append(&models.AuditRecord{Time: time.Now(), Action: models.AuditActionDeposit, Amount: 10}))
+	assert.Nil(t, store.Verify())
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	tampered := strings.Replace(string(data), `"amount":10`, `"amount":1000`, 1)
+	assert.Nil(t, os.WriteFile(path, []byte(tampered), 0o600))
+	assert.True(t, errors.Is(store.Verify(), errAuditChainBroken))
+
+	// удаление записей тоже обнаруживается, хотя пустая цепочка корректна
+	assert.Nil(t, os.WriteFile(path, nil, 0o600))
+	assert.True(t, errors.Is(store.Verify(), errAuditChainBroken))
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+var (
+	errAlreadyExists  = fmt.Errorf("already exists: %w", models.ErrInvalidArgument)
+	errUnknownWallet  = fmt.Errorf("entry of wallet not in dataset: %w", models.ErrInvalidArgument)
+	errEmptyDatasetID = fmt.Errorf("empty id: %w", models.ErrInvalidArgument)
+)
+
+// Dump копия всех кошельков, их действующих блокировок и журнала операций.
+// Хранилище блокируется целиком, поэтому копия согласована.
+func (repo *WalletRepository) Dump() (*models.Dataset, error) {
+	repo.muWallets.Lock()
+	defer repo.muWallets.Unlock()
+
+	return repo.dump(), nil
+}
+
+// dump копия хранилища.
+// Вызывающий должен владеть эксклюзивной блокировкой хранилища.
+func (repo *WalletRepository) dump() *models.Dataset {
+	now := repo.now().UTC()
+	ds := &models.Dataset{
+		Wallets: make([]models.WalletDump, 0, len(repo.wallets)),
+	}
+	for _, rec := range repo.wallets {
+		ds.Wallets = append(ds.Wallets, dumpWallet(rec, now))
+		ds.Ledger = append(ds.Ledger, rec.ledger...)
+	}
+	sortLedger(ds.Ledger)
+	return ds
+}
+
+// Restore загружаем кошельки выгрузки с их идентификаторами, блокировками и журналом.
+// Если хотя бы один кошелек, блокировка или операция уже есть в хранилище, ничего не загружается.
+// Загрузка фиксируется в журнале хранилища как одна эксклюзивная транзакция.
+func (repo *WalletRepository) Restore(ds *models.Dataset) error {
+	return repo.apply(nil, func(tx *transaction) error {
+		return tx.restore(ds)
+	})
+}
+
+// restore добавляем кошельки выгрузки как созданные транзакцией, чтобы откат убрал их целиком.
+// Вызывается в эксклюзивной транзакции.
+func (tx *transaction) restore(ds *models.Dataset) error {
+	repo := tx.repo
+	repo.muIndex.Lock()
+	defer repo.muIndex.Unlock()
+
+	err := repo.checkRestore(ds)
+	if err != nil {
+		return err
+	}
+	err = repo.replay(&walRecord{Wallets: ds.Wallets, Ledger: ds.Ledger})
+	if err != nil {
+		return err
+	}
+
+	for _, dump := range ds.Wallets {
+		tx.created = append(tx.created, repo.index[dump.ID])
+		for _, hold := range dump.Holds {
+			tx.createdHolds = append(tx.createdHolds, hold.ID)
+		}
+	}
+	seen := make(map[string]struct{})
+	for _, entry := range ds.Ledger {
+		if _, ok := seen[entry.OperationID]; !ok {
+			seen[entry.OperationID] = struct{}{}
+			tx.createdOps = append(tx.createdOps, entry.OperationID)
+		}
+	}
+	return nil
+}
+
+// checkRestore загрузка не затрагивает существующие кошельки, блокировки и операции.
+// Вызывающий должен владеть muIndex.
+func (repo *WalletRepository) checkRestore(ds *models.Dataset) error {
+	ids := make(map[string]struct{}, len(ds.Wallets))
+	for _, dump := range ds.Wallets {
+		if dump.ID == "" {
+			return fmt.Errorf("wallet: %w", errEmptyDatasetID)
+		}
+		if _, ok := repo.index[dump.ID]; ok {
+			return fmt.Errorf("wallet %s: %w", dump.ID, errAlreadyExists)
+		}
+		if _, ok := ids[dump.ID]; ok {
+			return fmt.Errorf("wallet %s is duplicated: %w", dump.ID, models.ErrInvalidArgument)
+		}
+		ids[dump.ID] = struct{}{}
+		for _, hold := range dump.Holds {
+			if _, ok := repo.holds[hold.ID]; ok {
+				return fmt.Errorf("hold %s: %w", hold.ID, errAlreadyExists)
+			}
+		}
+	}
+
+	for _, entry := range ds.Ledger {
+		if _, ok := ids[entry.WalletID]; !ok {
+			return fmt.Errorf("operation %s, wallet %s: %w", entry.OperationID, entry.WalletID, errUnknownWallet)
+		}
+		if _, ok := repo.operations[entry.OperationID]; ok {
+			return fmt.Errorf("operation %s: %w", entry.OperationID, errAlreadyExists)
+		}
+	}
+	return nil
+}
+
+// dumpWallet полное состояние кошелька с действующими на момент now блокировками.
+// Вызывающий должен владеть эксклюзивной блокировкой хранилища.
+func dumpWallet(rec *record, now time.Time) models.WalletDump {
+	dump := walletDump(&rec.wallet)
+	holds := dump.Holds[:0]
+	for _, hold := range dump.Holds {
+		if now.Before(hold.ExpiresAt) {
+			holds = append(holds, hold)
+		}
+	}
+	dump.Holds = holds
+	if len(dump.Holds) == 0 {
+		dump.Holds = nil
+	}
+	return dump
+}
+
+// walletDump состояние кошелька со всеми его блокировками, включая истекшие.
+func walletDump(wal *wallet) models.WalletDump {
+	dump := models.WalletDump{
+		ID:          wal.id,
+		Name:        wal.name,
+		Balance:     wal.balance,
+		CreditLimit: wal.creditLimit,
+		Status:      wal.status,
+		Owner:       wal.owner,
+		CreatedAt:   wal.createdAt,
+		UpdatedAt:   wal.updatedAt,
+		Version:     wal.version,
+		Metadata:    wal.Metadata(),
+	}
+	for _, hold := range wal.holds {
+		dump.Holds = append(dump.Holds, hold)
+	}
+	sort.Slice(dump.Holds, func(i, j int) bool {
+		return dump.Holds[i].ID < dump.Holds[j].ID
+	})
+	return dump
+}
+
+// restoreWallet запись хранилища по выгрузке кошелька без журнала операций.
+func restoreWallet(dump models.WalletDump) *record {
+	wal := wallet{
+		id:          dump.ID,
+		name:        dump.Name,
+		balance:     dump.Balance,
+		creditLimit: dump.CreditLimit,
+		status:      dump.Status,
+		owner:       dump.Owner,
+		createdAt:   dump.CreatedAt.UTC(),
+		updatedAt:   dump.UpdatedAt.UTC(),
+		version:     dump.Version,
+	}
+	if len(dump.Metadata) > 0 {
+		wal.metadata = make(map[string]string, len(dump.Metadata))
+		for k, v := range dump.Metadata {
+			wal.metadata[k] = v
+		}
+	}
+	if len(dump.Holds) > 0 {
+		wal.holds = make(map[string]models.Hold, len(dump.Holds))
+		for _, hold := range dump.Holds {
+			hold.WalletID = dump.ID
+			hold.Status = models.HoldStatusActive
+			wal.holds[hold.ID] = hold
+		}
+	}
+	return &record{wallet: wal}
+}
+
+// uniqueSorted убираем повторы из отсортированного списка.
+func uniqueSorted(ids []string) []string {
+	out := ids[:0]
+	for _, id := range ids {
+		if len(out) == 0 || out[len(out)-1] != id {
+			out = append(out, id)
+		}
+	}
+	return out
+}
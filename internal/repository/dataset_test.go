@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpRestore(t *testing.T) {
+	src := NewRepo()
+	a := src.Create("a", 100, true, "owner", map[string]string{"k": "v"})
+	b := src.Create("b", 0, true, "owner", nil)
+	hold, err := src.CreateHold(a.ID(), 10, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	opID, err := src.RecordOperation(models.OperationTransfer, []models.LedgerEntry{
+		{WalletID: a.ID(), Amount: -30, Counterparty: b.ID()},
+		{WalletID: b.ID(), Amount: 30, Counterparty: a.ID()},
+	})
+	assert.Nil(t, err)
+
+	ds, err := src.Dump()
+	assert.Nil(t, err)
+	assert.Len(t, ds.Wallets, 2)
+	assert.Len(t, ds.Ledger, 3)
+	assert.Len(t, ds.Wallets[0].Holds, 1)
+
+	dst := NewRepo()
+	assert.Nil(t, dst.Restore(ds))
+
+	got, err := dst.ByID(a.ID())
+	assert.Nil(t, err)
+	want, _ := src.ByID(a.ID())
+	assert.Equal(t, want, got)
+
+	restored, err := dst.HoldByID(hold.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, hold, restored)
+
+	entries, err := dst.Ledger(models.LedgerFilter{OperationID: opID})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+
+	// повторная загрузка не дублирует кошельки
+	err = dst.Restore(ds)
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+	assert.Len(t, dst.All(), 2)
+}
+
+func TestRestore_unknownWallet(t *testing.T) {
+	repo := NewRepo()
+	err := repo.Restore(&models.Dataset{
+		Wallets: []models.WalletDump{{ID: "a", Version: 1}},
+		Ledger:  []models.LedgerEntry{{OperationID: "op", WalletID: "b", Amount: 1}},
+	})
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+	assert.Empty(t, repo.All())
+}
@@ -1,116 +1,352 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"github.com/Nizom98/wallet/internal/models"
+	"fmt"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	errWalletNotFound = errors.New("wallet not found")
-	seededRand        = rand.New(rand.NewSource(time.Now().UnixNano()))
+	errWalletNotFound  = fmt.Errorf("wallet %w", models.ErrNotFound)
+	errWalletNotLocked = errors.New("wallet is not declared in transaction")
+	seededRand         = rand.New(rand.NewSource(time.Now().UnixNano()))
+	tracer             = otel.Tracer("github.com/Nizom98/wallet/internal/repository")
 )
 
+// muRand seededRand не безопасен для параллельных транзакций.
+var muRand = new(sync.Mutex)
+
 const charset = "abcdefghijklmnopqrstuvwxyz" + "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
-// WalletRepository ...
+// WalletRepository хранилище кошельков в памяти.
+// Транзакции по разным кошелькам выполняются параллельно:
+// каждая транзакция блокирует только объявленные кошельки.
 type WalletRepository struct {
-	// muWallets для конкурентного доступа к wallets
+	// muWallets эксклюзивная транзакция берет Lock, транзакции по кошелькам и чтения - RLock
 	muWallets *sync.RWMutex
-	// wallets хранилище кошелков
-	wallets []*wallet
+	// muIndex для конкурентного доступа к wallets, index и list
+	muIndex *sync.RWMutex
+	// wallets хранилище кошелков в порядке создания
+	wallets []*record
+	// index кошельки по идентификатору
+	index map[string]*record
+	// list кошельки в порядке сортировки списка, зафиксированные транзакциями
+	list *listIndex
+	// holds идентификаторы кошельков по идентификатору блокировки средств
+	holds map[string]string
+	// operations идентификаторы кошельков по идентификатору операции журнала
+	operations map[string][]string
+	// now текущее время для меток создания и изменения
+	now func() time.Time
+	// journal фиксирует изменения транзакций(журнал изменений, поток событий),
+	// nil если хранилище только в памяти
+	journal journal
+}
+
+// journal фиксирует изменения успешной транзакции, вызывается до освобождения ее блокировок.
+// Ошибка откатывает транзакцию.
+type journal interface {
+	commit(tx *transaction) error
 }
 
 // NewRepo конструктор репозитория
 func NewRepo() *WalletRepository {
 	return &WalletRepository{
-		muWallets: new(sync.RWMutex),
-		wallets:   nil,
+		muWallets:  new(sync.RWMutex),
+		muIndex:    new(sync.RWMutex),
+		wallets:    nil,
+		index:      make(map[string]*record),
+		list:       newListIndex(nil),
+		holds:      make(map[string]string),
+		operations: make(map[string][]string),
+		now:        time.Now,
 	}
 }
 
 // Transaction для конкурентной записи в хранилище.
-// Сразу после вызова метода и до окончания доступ к хранилищу может иметь только один писатель.
-func (repo *WalletRepository) Transaction(fn func(repo models.WalletRepository) error) error {
-	repo.muWallets.Lock()
-	defer repo.muWallets.Unlock()
+// ids - кошельки, которые затрагивает транзакция. Они блокируются в порядке возрастания id,
+// поэтому встречные транзакции(перевод A->B и B->A) не приводят к взаимоблокировке.
+// Транзакции с непересекающимися ids выполняются параллельно.
+// Если ids пуст, транзакция получает эксклюзивный доступ ко всему хранилищу.
+// Если fn вернула ошибку, все изменения транзакции откатываются.
+func (repo *WalletRepository) Transaction(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) error {
+	_, span := tracer.Start(ctx, "repository.Transaction", trace.WithAttributes(
+		attribute.StringSlice("wallet.ids", ids),
+	))
+	defer span.End()
 
-	return fn(repo)
+	err := repo.apply(ids, func(tx *transaction) error {
+		return fn(tx)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
 }
 
-// Create создание кошелька.
-func (repo *WalletRepository) Create(name string, balance float64, status bool) models.Walleter {
-	newWallet := &wallet{
-		id:      genNewID(),
-		name:    name,
-		balance: balance,
-		status:  status,
+// apply выполняем fn в транзакции по кошелькам ids.
+// Изменения успешной транзакции записываются в журнал изменений до освобождения блокировок,
+// при ошибке fn или записи журнала изменения откатываются.
+func (repo *WalletRepository) apply(ids []string, fn func(tx *transaction) error) error {
+	tx := repo.begin(ids)
+	defer tx.release()
+
+	err := fn(tx)
+	if err == nil {
+		err = tx.commit()
 	}
+	if err != nil {
+		tx.rollback()
+		return err
+	}
+	tx.pruneHolds()
+	tx.reindex()
+	return nil
+}
 
-	repo.wallets = append(repo.wallets, newWallet)
+// begin берем блокировки транзакции.
+func (repo *WalletRepository) begin(ids []string) *transaction {
+	if len(ids) == 0 {
+		repo.muWallets.Lock()
+		return &transaction{
+			repo:      repo,
+			exclusive: true,
+			undo:      make(map[*record]wallet),
+			ledgerLen: make(map[*record]int),
+		}
+	}
+
+	repo.muWallets.RLock()
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
 
-	return newWallet
+	tx := &transaction{
+		repo:      repo,
+		declared:  make(map[string]struct{}, len(sorted)),
+		held:      make(map[string]*record, len(sorted)),
+		undo:      make(map[*record]wallet, len(sorted)),
+		ledgerLen: make(map[*record]int, len(sorted)),
+	}
+	for _, id := range sorted {
+		if _, ok := tx.declared[id]; ok {
+			continue
+		}
+		tx.declared[id] = struct{}{}
+
+		rec := repo.lookup(id)
+		if rec == nil {
+			continue
+		}
+		rec.mu.Lock()
+		tx.held[id] = rec
+		tx.order = append(tx.order, rec)
+	}
+
+	return tx
+}
+
+// Create создание кошелька, версия нового кошелька 1.
+// Create не возвращает ошибку, поэтому ошибка записи журнала изменений приводит к панике:
+// сохраняемое хранилище создает кошельки внутри Transaction.
+func (repo *WalletRepository) Create(name string, balance float64, status bool, owner string, metadata map[string]string) models.Walleter {
+	var created models.Walleter
+	err := repo.apply(nil, func(tx *transaction) error {
+		created = tx.Create(name, balance, status, owner, metadata)
+		return nil
+	})
+	if err != nil {
+		panic(fmt.Sprintf("cannot create wallet: %s", err.Error()))
+	}
+	return created
 }
 
 // ByID получаем кошелек по идентификатору.
 // При отсутствии кошелка вернется ошибка errWalletNotFound.
 func (repo *WalletRepository) ByID(id string) (models.Walleter, error) {
-	for _, wal := range repo.wallets {
-		if wal.id == id {
-			return wal, nil
-		}
+	repo.muWallets.RLock()
+	defer repo.muWallets.RUnlock()
+
+	rec := repo.lookup(id)
+	if rec == nil {
+		return nil, errWalletNotFound
 	}
 
-	return nil, errWalletNotFound
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.snapshot(repo.now().UTC()), nil
 }
 
 // All получение всего списка кошельков
 func (repo *WalletRepository) All() []models.Walleter {
-	walletList := make([]models.Walleter, 0, len(repo.wallets))
-	for _, wallet := range repo.wallets {
-		walletList = append(walletList, models.Walleter(wallet))
+	snapshots := repo.snapshots()
+
+	walletList := make([]models.Walleter, 0, len(snapshots))
+	for _, wal := range snapshots {
+		walletList = append(walletList, models.Walleter(wal))
 	}
 
 	return walletList
 }
 
-// UpdateByID обновление данных кошелька.
-// Все параметры(кроме id) являются опциональными.
-// Если какой-то параметр отсутствует(равен nil), то данное поле не будет обновлено.
-func (repo *WalletRepository) UpdateByID(id string, name *string, balance *float64, status *bool) error {
-	pos := repo.walletPos(id)
-	if pos == -1 {
-		return errWalletNotFound
+// List выборка кошельков по фильтру.
+// Кошельки владельца выбираются из индекса владельцев, остальные выборки читают страницу
+// из индекса поля сортировки с позиции курсора, границы ключей задают фильтры по этому полю.
+func (repo *WalletRepository) List(filter models.WalletFilter) (*models.WalletPage, error) {
+	if filter.Owner != "" {
+		return listWallets(repo.ownerSnapshots(filter.Owner), filter)
+	}
+	q, err := newListQuery(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	wal := repo.wallets[pos]
+	repo.muWallets.RLock()
+	defer repo.muWallets.RUnlock()
 
-	if name != nil {
-		wal.name = *name
-	}
-	if balance != nil {
-		wal.balance = *balance
+	return q.page(func(lo, hi string, n int) ([]listEntry, error) {
+		// блокировки кошельков берутся после muIndex: транзакция обновляет индекс, владея ими
+		repo.muIndex.RLock()
+		sorted := repo.list.sorted[q.filter.Sort]
+		positions := q.sortedRange(len(sorted), func(i int) string { return sorted[i].key }, lo, hi, n)
+		found := make([]indexEntry, 0, len(positions))
+		for _, i := range positions {
+			found = append(found, sorted[i])
+		}
+		repo.muIndex.RUnlock()
+
+		now := repo.now().UTC()
+		entries := make([]listEntry, 0, len(found))
+		for _, entry := range found {
+			entry.rec.mu.Lock()
+			entries = append(entries, listEntry{key: entry.key, wal: entry.rec.snapshot(now)})
+			entry.rec.mu.Unlock()
+		}
+		return entries, nil
+	})
+}
+
+// ownerSnapshots копии кошельков владельца в порядке создания.
+func (repo *WalletRepository) ownerSnapshots(owner string) []*wallet {
+	repo.muWallets.RLock()
+	defer repo.muWallets.RUnlock()
+
+	repo.muIndex.RLock()
+	records := append([]*record(nil), repo.list.owners[owner]...)
+	repo.muIndex.RUnlock()
+
+	now := repo.now().UTC()
+	snapshots := make([]*wallet, 0, len(records))
+	for _, rec := range records {
+		rec.mu.Lock()
+		snapshots = append(snapshots, rec.snapshot(now))
+		rec.mu.Unlock()
 	}
-	if status != nil {
-		wal.status = *status
+	return snapshots
+}
+
+// UpdateByID обновление данных кошелька.
+// Все поля upd являются опциональными.
+// Если какое-то поле отсутствует(равно nil), то данное поле кошелька не будет обновлено.
+// Каждое обновление увеличивает версию и время изменения кошелька.
+func (repo *WalletRepository) UpdateByID(id string, upd models.WalletUpdate) error {
+	return repo.apply([]string{id}, func(tx *transaction) error {
+		return tx.UpdateByID(id, upd)
+	})
+}
+
+// snapshots копии всех кошельков в порядке создания.
+func (repo *WalletRepository) snapshots() []*wallet {
+	repo.muWallets.RLock()
+	defer repo.muWallets.RUnlock()
+
+	repo.muIndex.RLock()
+	records := append([]*record(nil), repo.wallets...)
+	repo.muIndex.RUnlock()
+
+	now := repo.now().UTC()
+	snapshots := make([]*wallet, 0, len(records))
+	for _, rec := range records {
+		rec.mu.Lock()
+		snapshots = append(snapshots, rec.snapshot(now))
+		rec.mu.Unlock()
 	}
-	return nil
+
+	return snapshots
 }
 
-// walletPos определяем позицию(индекс) кошелька в хранилище
-func (repo *WalletRepository) walletPos(id string) int {
-	for pos, wal := range repo.wallets {
-		if wal.id == id {
-			return pos
+// insert добавляем новый кошелек в хранилище.
+func (repo *WalletRepository) insert(name string, balance float64, status bool, owner string, metadata map[string]string) *record {
+	rec := newRecord(genNewID(), name, balance, status, owner, metadata, repo.now().UTC())
+
+	repo.muIndex.Lock()
+	defer repo.muIndex.Unlock()
+
+	repo.wallets = append(repo.wallets, rec)
+	repo.index[rec.wallet.id] = rec
+
+	return rec
+}
+
+// newRecord новый кошелек версии 1, созданный в now.
+func newRecord(id, name string, balance float64, status bool, owner string, metadata map[string]string, now time.Time) *record {
+	var meta map[string]string
+	if len(metadata) > 0 {
+		meta = make(map[string]string, len(metadata))
+		for k, v := range metadata {
+			meta[k] = v
 		}
 	}
 
-	return -1
+	return &record{
+		wallet: wallet{
+			id:        id,
+			name:      name,
+			balance:   balance,
+			status:    status,
+			owner:     owner,
+			createdAt: now,
+			updatedAt: now,
+			version:   1,
+			metadata:  meta,
+		},
+	}
+}
+
+// open записываем ненулевой начальный баланс нового кошелька в журнал операций,
+// чтобы баланс кошелька всегда можно было пересчитать по журналу.
+// Возвращает идентификатор операции, пустой для нулевого баланса.
+func (repo *WalletRepository) open(rec *record) string {
+	if rec.wallet.balance == 0 {
+		return ""
+	}
+	records := map[string]*record{rec.wallet.id: rec}
+	entries := []models.LedgerEntry{{WalletID: rec.wallet.id, Amount: rec.wallet.balance}}
+	return repo.appendOperation(records, models.OperationOpening, entries)
+}
+
+// lookup ищем кошелек по идентификатору, nil если не найден.
+func (repo *WalletRepository) lookup(id string) *record {
+	repo.muIndex.RLock()
+	defer repo.muIndex.RUnlock()
+
+	return repo.index[id]
 }
 
 func stringWithCharset(length int, charset string) string {
+	muRand.Lock()
+	defer muRand.Unlock()
+
 	b := make([]byte, length)
 	for i := range b {
 		b[i] = charset[seededRand.Intn(len(charset))]
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/Nizom98/wallet/internal/models"
 	"github.com/Nizom98/wallet/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -10,7 +13,7 @@ import (
 func TestCreate(t *testing.T) {
 	repo := NewRepo()
 	name, balance, status := "test_name", float64(9999), true
-	got := repo.Create(name, balance, status)
+	got := repo.Create(name, balance, status, "test_owner", nil)
 
 	assert.NotNil(t, got)
 	assert.True(t, name == got.Name())
@@ -21,11 +24,11 @@ func TestCreate(t *testing.T) {
 func TestUpdateByID(t *testing.T) {
 	repo := NewRepo()
 	name, balance, status := "test_name", float64(9999), true
-	oldWal := repo.Create(name, balance, status)
+	oldWal := repo.Create(name, balance, status, "test_owner", nil)
 
 	expectName := name + "postfix"
 
-	err := repo.UpdateByID(oldWal.ID(), utils.PtrString(expectName), nil, nil)
+	err := repo.UpdateByID(oldWal.ID(), models.WalletUpdate{Name: utils.Ptr[string](expectName)})
 	assert.Nil(t, err)
 	if err != nil {
 		return
@@ -41,8 +44,8 @@ func TestUpdateByID(t *testing.T) {
 func TestByID_found(t *testing.T) {
 	repo := NewRepo()
 
-	repo.Create("test_name", 9999, true)
-	expect := repo.Create("test_name_2", 8888, true)
+	repo.Create("test_name", 9999, true, "test_owner", nil)
+	expect := repo.Create("test_name_2", 8888, true, "test_owner", nil)
 
 	got, err := repo.ByID(expect.ID())
 	assert.Nil(t, err)
@@ -58,7 +61,7 @@ func TestByID_found(t *testing.T) {
 
 func TestByID_notFound(t *testing.T) {
 	repo := NewRepo()
-	repo.Create("test_name_2", 8888, true)
+	repo.Create("test_name_2", 8888, true, "test_owner", nil)
 
 	nonExistsID := "nonExistsID"
 
@@ -66,3 +69,46 @@ func TestByID_notFound(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, got)
 }
+
+func TestUpdateByID_metadata(t *testing.T) {
+	repo := NewRepo()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return clock }
+
+	created := repo.Create("test_name", 0, true, "test_owner", nil)
+	assert.Equal(t, uint64(1), created.Version())
+	assert.Equal(t, clock, created.CreatedAt())
+	assert.Equal(t, clock, created.UpdatedAt())
+
+	clock = clock.Add(time.Hour)
+	err := repo.UpdateByID(created.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](10)})
+	assert.Nil(t, err)
+
+	updated, err := repo.ByID(created.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), updated.Version())
+	assert.Equal(t, clock.Add(-time.Hour), updated.CreatedAt())
+	assert.Equal(t, clock, updated.UpdatedAt())
+}
+
+func TestUpdateByID_versionMismatch(t *testing.T) {
+	repo := NewRepo()
+	created := repo.Create("test_name", 0, true, "test_owner", nil)
+
+	err := repo.UpdateByID(created.ID(), models.WalletUpdate{
+		Name:            utils.Ptr[string]("first"),
+		ExpectedVersion: utils.Ptr[uint64](1),
+	})
+	assert.Nil(t, err)
+
+	err = repo.UpdateByID(created.ID(), models.WalletUpdate{
+		Name:            utils.Ptr[string]("second"),
+		ExpectedVersion: utils.Ptr[uint64](1),
+	})
+	assert.True(t, errors.Is(err, models.ErrVersionMismatch))
+
+	got, err := repo.ByID(created.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, "first", got.Name())
+	assert.Equal(t, uint64(2), got.Version())
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// eventJournal записываем изменения транзакций событиями кошельков в поток событий.
+type eventJournal struct {
+	store models.EventStore
+}
+
+// commit дописываем события транзакции в поток одной записью.
+func (j *eventJournal) commit(tx *transaction) error {
+	events := tx.events()
+	if len(events) == 0 {
+		return nil
+	}
+	return j.store.Append(events)
+}
+
+// events события измененных и созданных транзакцией кошельков.
+// Изменение, после которого состояние кошелька не отличается от исходного, не порождает событий,
+// поэтому кошелек без событий возвращается к исходному состоянию(вместе с версией):
+// проекция всегда совпадает с результатом применения потока событий.
+func (tx *transaction) events() []models.WalletEvent {
+	var events []models.WalletEvent
+	for _, rec := range tx.changed() {
+		before, saved := tx.undo[rec]
+		if !saved {
+			before = rec.wallet
+		}
+		var entries []models.LedgerEntry
+		if n, ok := tx.ledgerLen[rec]; ok {
+			entries = rec.ledger[n:]
+		}
+
+		walletEvents := diffEvents(&before, &rec.wallet, entries)
+		if len(walletEvents) == 0 {
+			rec.wallet = before
+		}
+		events = append(events, walletEvents...)
+	}
+	for _, rec := range tx.created {
+		events = append(events, diffEvents(nil, &rec.wallet, rec.ledger)...)
+	}
+	return events
+}
+
+// diffEvents события, переводящие кошелек из состояния before(nil - кошелек создается) в after.
+// entries - проводки кошелька, записанные между этими состояниями.
+func diffEvents(before, after *wallet, entries []models.LedgerEntry) []models.WalletEvent {
+	var events []models.WalletEvent
+	add := func(ev models.WalletEvent) {
+		ev.WalletID = after.id
+		ev.Version = after.version
+		if ev.Time.IsZero() {
+			ev.Time = after.updatedAt
+		}
+		events = append(events, ev)
+	}
+
+	if before == nil {
+		add(models.WalletEvent{
+			Type:     models.EventCreated,
+			Time:     after.createdAt,
+			Name:     after.name,
+			Owner:    after.owner,
+			Status:   after.status,
+			Metadata: after.Metadata(),
+		})
+		before = &wallet{
+			id:        after.id,
+			name:      after.name,
+			status:    after.status,
+			owner:     after.owner,
+			createdAt: after.createdAt,
+		}
+	}
+
+	balance := before.balance
+	for _, op := range groupOperations(entries) {
+		balance = op[len(op)-1].Balance
+		add(models.WalletEvent{
+			Type:      operationEvent(op[0].Type),
+			Operation: op[0].Type,
+			Entries:   op,
+			Balance:   balance,
+		})
+	}
+	if after.balance != balance {
+		add(models.WalletEvent{Type: models.EventBalanceAdjusted, Amount: after.balance - balance, Balance: after.balance})
+	}
+
+	if after.name != before.name {
+		add(models.WalletEvent{Type: models.EventRenamed, Name: after.name})
+	}
+	if after.status != before.status {
+		evType := models.EventDeactivated
+		if after.status {
+			evType = models.EventActivated
+		}
+		add(models.WalletEvent{Type: evType})
+	}
+	if after.creditLimit != before.creditLimit {
+		add(models.WalletEvent{Type: models.EventCreditLimitChanged, Amount: after.creditLimit})
+	}
+
+	for _, id := range holdIDs(after.holds, before.holds) {
+		hold := after.holds[id]
+		add(models.WalletEvent{Type: models.EventHoldPlaced, Hold: &hold})
+	}
+	for _, id := range holdIDs(before.holds, after.holds) {
+		add(models.WalletEvent{Type: models.EventHoldClosed, Hold: &models.Hold{ID: id}})
+	}
+	return events
+}
+
+// applyEvent применяем событие к проекциям: кошелькам, журналу операций и блокировкам.
+// Вызывающий должен владеть эксклюзивной блокировкой хранилища(или хранилище еще не доступно другим горутинам).
+func (repo *WalletRepository) applyEvent(ev *models.WalletEvent) error {
+	repo.muIndex.Lock()
+	defer repo.muIndex.Unlock()
+
+	if ev.Type == models.EventCreated {
+		if _, ok := repo.index[ev.WalletID]; ok {
+			return fmt.Errorf("event %d: wallet %s already exists", ev.Seq, ev.WalletID)
+		}
+		rec := restoreWallet(models.WalletDump{
+			ID:        ev.WalletID,
+			Name:      ev.Name,
+			Status:    ev.Status,
+			Owner:     ev.Owner,
+			CreatedAt: ev.Time,
+			UpdatedAt: ev.Time,
+			Version:   ev.Version,
+			Metadata:  ev.Metadata,
+		})
+		repo.wallets = append(repo.wallets, rec)
+		repo.index[ev.WalletID] = rec
+		return nil
+	}
+
+	rec, ok := repo.index[ev.WalletID]
+	if !ok {
+		return fmt.Errorf("event %d, wallet %s: %w", ev.Seq, ev.WalletID, errWalletNotFound)
+	}
+	wal := &rec.wallet
+	switch ev.Type {
+	case models.EventDeposited, models.EventWithdrawn, models.EventTransferred, models.EventOperationRecorded:
+		rec.ledger = append(rec.ledger, ev.Entries...)
+		wal.balance = ev.Balance
+		for _, entry := range ev.Entries {
+			repo.operations[entry.OperationID] = addSorted(repo.operations[entry.OperationID], ev.WalletID)
+		}
+	case models.EventBalanceAdjusted:
+		wal.balance = ev.Balance
+	case models.EventRenamed:
+		wal.name = ev.Name
+	case models.EventDeactivated:
+		wal.status = false
+	case models.EventActivated:
+		wal.status = true
+	case models.EventCreditLimitChanged:
+		wal.creditLimit = ev.Amount
+	case models.EventHoldPlaced, models.EventHoldClosed:
+		if ev.Hold == nil {
+			return fmt.Errorf("event %d: %s without hold", ev.Seq, ev.Type)
+		}
+		holds := make(map[string]models.Hold, len(wal.holds)+1)
+		for id, hold := range wal.holds {
+			holds[id] = hold
+		}
+		if ev.Type == models.EventHoldPlaced {
+			holds[ev.Hold.ID] = *ev.Hold
+			repo.holds[ev.Hold.ID] = ev.WalletID
+		} else {
+			delete(holds, ev.Hold.ID)
+			delete(repo.holds, ev.Hold.ID)
+		}
+		if len(holds) == 0 {
+			holds = nil
+		}
+		wal.holds = holds
+	default:
+		return fmt.Errorf("event %d: unknown type %q", ev.Seq, ev.Type)
+	}
+
+	wal.updatedAt = ev.Time
+	wal.version = ev.Version
+	return nil
+}
+
+// operationEvent тип события операции журнала.
+func operationEvent(opType models.OperationType) models.EventType {
+	switch opType {
+	case models.OperationDeposit:
+		return models.EventDeposited
+	case models.OperationWithdraw:
+		return models.EventWithdrawn
+	case models.OperationTransfer, models.OperationBatch:
+		return models.EventTransferred
+	default:
+		return models.EventOperationRecorded
+	}
+}
+
+// groupOperations разбиваем проводки кошелька на операции, проводки операции идут подряд.
+func groupOperations(entries []models.LedgerEntry) [][]models.LedgerEntry {
+	var ops [][]models.LedgerEntry
+	for i, entry := range entries {
+		if i == 0 || entry.OperationID != entries[i-1].OperationID {
+			ops = append(ops, nil)
+		}
+		ops[len(ops)-1] = append(ops[len(ops)-1], entry)
+	}
+	return ops
+}
+
+// holdIDs идентификаторы блокировок из holds, которых нет в other, по возрастанию.
+func holdIDs(holds, other map[string]models.Hold) []string {
+	var ids []string
+	for id := range holds {
+		if _, ok := other[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// addSorted добавляем id в отсортированный список без повторов.
+func addSorted(ids []string, id string) []string {
+	i := sort.SearchStrings(ids, id)
+	if i < len(ids) && ids[i] == id {
+		return ids
+	}
+	ids = append(ids, "")
+	copy(ids[i+1:], ids[i:])
+	ids[i] = id
+	return ids
+}
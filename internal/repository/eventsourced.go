@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// EventRepository хранилище кошельков, состояние которых выводится из потока событий.
+// Проекции потока(кошельки с текущими балансами, журнал операций, блокировки) хранятся в памяти.
+// Транзакция обновляет проекции и до освобождения блокировок дописывает в поток свои события,
+// ошибка записи событий откатывает транзакцию.
+type EventRepository struct {
+	// proj проекции потока событий
+	proj  *WalletRepository
+	store models.EventStore
+}
+
+// NewEventRepo строим проекции по последнему снимку и событиям после него.
+func NewEventRepo(store models.EventStore) (*EventRepository, error) {
+	proj := NewRepo()
+	var seq uint64
+	snap, err := store.LatestSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read snapshot: %w", err)
+	}
+	if snap != nil {
+		err = proj.Restore(&snap.Dataset)
+		if err != nil {
+			return nil, fmt.Errorf("cannot restore snapshot %d: %w", snap.Seq, err)
+		}
+		seq = snap.Seq
+	}
+
+	err = proj.replayEvents(store, seq)
+	if err != nil {
+		return nil, err
+	}
+	proj.journal = &eventJournal{store: store}
+
+	return &EventRepository{
+		proj:  proj,
+		store: store,
+	}, nil
+}
+
+// Rebuild строим проекции заново по всему потоку событий, без снимков.
+// На время перестроения хранилище блокируется целиком, при ошибке проекции не меняются.
+func (repo *EventRepository) Rebuild() error {
+	repo.proj.muWallets.Lock()
+	defer repo.proj.muWallets.Unlock()
+
+	fresh := NewRepo()
+	err := fresh.replayEvents(repo.store, 0)
+	if err != nil {
+		return err
+	}
+
+	repo.proj.muIndex.Lock()
+	defer repo.proj.muIndex.Unlock()
+
+	repo.proj.wallets = fresh.wallets
+	repo.proj.index = fresh.index
+	repo.proj.list = fresh.list
+	repo.proj.holds = fresh.holds
+	repo.proj.operations = fresh.operations
+	return nil
+}
+
+// Snapshot сохраняем снимок проекций, после перезапуска применяются только события после него.
+func (repo *EventRepository) Snapshot() error {
+	repo.proj.muWallets.Lock()
+	snap := &models.DatasetSnapshot{
+		Seq:       repo.store.LastSeq(),
+		CreatedAt: repo.proj.now().UTC(),
+		Dataset:   *repo.proj.dump(),
+	}
+	repo.proj.muWallets.Unlock()
+
+	return repo.store.SaveSnapshot(snap)
+}
+
+// RunSnapshots сохраняем снимок каждые every, пока не отменен ctx.
+func (repo *EventRepository) RunSnapshots(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := repo.Snapshot()
+			if err != nil {
+				log.Errorf("snapshot failed: %s", err.Error())
+			}
+		}
+	}
+}
+
+// ExpireHolds снимаем истекшие блокировки, события EventHoldClosed.
+func (repo *EventRepository) ExpireHolds() (int, error) {
+	return repo.proj.ExpireHolds()
+}
+
+// RunHoldExpiry снимаем истекшие блокировки каждые every, пока не отменен ctx.
+func (repo *EventRepository) RunHoldExpiry(ctx context.Context, every time.Duration) {
+	runHoldExpiry(ctx, every, repo.ExpireHolds)
+}
+
+// replayEvents применяем события потока с номером больше after.
+// Вызывающий должен владеть эксклюзивной блокировкой хранилища(или хранилище еще не доступно другим горутинам).
+// События меняют кошельки в обход транзакций, поэтому индексы списка после них строятся заново.
+func (repo *WalletRepository) replayEvents(store models.EventStore, after uint64) error {
+	seq := after
+	err := store.Events(after, func(ev models.WalletEvent) error {
+		if ev.Seq != seq+1 {
+			return fmt.Errorf("event %d follows %d: %w", ev.Seq, seq, errCorruptedFrame)
+		}
+		seq = ev.Seq
+		return repo.applyEvent(&ev)
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.muIndex.Lock()
+	repo.list = newListIndex(repo.wallets)
+	repo.muIndex.Unlock()
+	return nil
+}
+
+// Create создание кошелька, событие EventCreated.
+func (repo *EventRepository) Create(name string, balance float64, status bool, owner string, metadata map[string]string) models.Walleter {
+	return repo.proj.Create(name, balance, status, owner, metadata)
+}
+
+// ByID кошелек из проекции.
+func (repo *EventRepository) ByID(id string) (models.Walleter, error) {
+	return repo.proj.ByID(id)
+}
+
+// All все кошельки из проекции.
+func (repo *EventRepository) All() []models.Walleter {
+	return repo.proj.All()
+}
+
+// List выборка кошельков из проекции.
+func (repo *EventRepository) List(filter models.WalletFilter) (*models.WalletPage, error) {
+	return repo.proj.List(filter)
+}
+
+// Transaction транзакция по проекциям, события успешной транзакции дописываются в поток.
+func (repo *EventRepository) Transaction(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) error {
+	return repo.proj.Transaction(ctx, ids, fn)
+}
+
+// UpdateByID обновление кошелька, события по изменившимся полям.
+func (repo *EventRepository) UpdateByID(id string, upd models.WalletUpdate) error {
+	return repo.proj.UpdateByID(id, upd)
+}
+
+// CreateHold блокировка средств, событие EventHoldPlaced.
+func (repo *EventRepository) CreateHold(walletID string, amount float64, expiresAt time.Time) (models.Hold, error) {
+	return repo.proj.CreateHold(walletID, amount, expiresAt)
+}
+
+// HoldByID блокировка из проекции.
+func (repo *EventRepository) HoldByID(id string) (models.Hold, error) {
+	return repo.proj.HoldByID(id)
+}
+
+// CloseHold снимаем блокировку, событие EventHoldClosed.
+func (repo *EventRepository) CloseHold(id string) (models.Hold, error) {
+	return repo.proj.CloseHold(id)
+}
+
+// RecordOperation записываем операцию, событие операции по каждому ее кошельку.
+func (repo *EventRepository) RecordOperation(opType models.OperationType, entries []models.LedgerEntry) (string, error) {
+	return repo.proj.RecordOperation(opType, entries)
+}
+
+// Ledger проводки из проекции журнала операций.
+func (repo *EventRepository) Ledger(filter models.LedgerFilter) ([]models.LedgerEntry, error) {
+	return repo.proj.Ledger(filter)
+}
+
+// Dump копия проекций.
+func (repo *EventRepository) Dump() (*models.Dataset, error) {
+	return repo.proj.Dump()
+}
+
+// Restore загружаем выгрузку событиями создания кошельков, их операций и остального состояния.
+func (repo *EventRepository) Restore(ds *models.Dataset) error {
+	return repo.proj.Restore(ds)
+}
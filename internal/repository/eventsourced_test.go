@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func eventTypes(t *testing.T, store models.EventStore, walletID string) []models.EventType {
+	var types []models.EventType
+	err := store.Events(0, func(ev models.WalletEvent) error {
+		if ev.WalletID == walletID {
+			types = append(types, ev.Type)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	return types
+}
+
+func TestEventRepository_events(t *testing.T) {
+	store := NewMemoryEventStore()
+	repo, err := NewEventRepo(store)
+	assert.Nil(t, err)
+	a, b := fillRepo(t, repo.proj)
+	assert.Nil(t, repo.UpdateByID(b.ID(), models.WalletUpdate{Status: utils.Ptr(false)}))
+
+	// откаченная транзакция и изменение без разницы не порождают событий
+	last := store.LastSeq()
+	errFail := errors.New("fail")
+	err = repo.Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+		assert.Nil(t, tx.UpdateByID(a.ID(), models.WalletUpdate{Name: utils.Ptr("x")}))
+		return errFail
+	})
+	assert.True(t, errors.Is(err, errFail))
+	assert.Nil(t, repo.UpdateByID(a.ID(), models.WalletUpdate{Name: utils.Ptr("a")}))
+	assert.Equal(t, last, store.LastSeq())
+
+	assert.Equal(t, []models.EventType{
+		models.EventCreated,
+		models.EventOperationRecorded,
+		models.EventTransferred,
+		models.EventHoldPlaced,
+	}, eventTypes(t, store, a.ID()))
+	assert.Equal(t, []models.EventType{
+		models.EventCreated,
+		models.EventTransferred,
+		models.EventRenamed,
+		models.EventDeactivated,
+	}, eventTypes(t, store, b.ID()))
+
+	replayed, err := NewEventRepo(store)
+	assert.Nil(t, err)
+	assertSameRepo(t, repo.proj, replayed.proj)
+}
+
+func TestEventRepository_Rebuild(t *testing.T) {
+	store := NewMemoryEventStore()
+	repo, err := NewEventRepo(store)
+	assert.Nil(t, err)
+	a, _ := fillRepo(t, repo.proj)
+	hold, err := repo.CreateHold(a.ID(), 5, repo.proj.now().Add(1))
+	assert.Nil(t, err)
+	_, err = repo.CloseHold(hold.ID)
+	assert.Nil(t, err)
+
+	want, err := repo.Dump()
+	assert.Nil(t, err)
+	assert.Nil(t, repo.Rebuild())
+	got, err := repo.Dump()
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+
+	// проекции после перестроения продолжают принимать изменения
+	assert.Nil(t, repo.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](65)}))
+	got2, err := repo.ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(65), got2.Balance())
+	assert.Equal(t, models.EventBalanceAdjusted, eventTypes(t, repo.store, a.ID())[6])
+}
+
+func TestEventRepository_Restore(t *testing.T) {
+	src := NewRepo()
+	fillRepo(t, src)
+	ds, err := src.Dump()
+	assert.Nil(t, err)
+
+	repo, err := NewEventRepo(NewMemoryEventStore())
+	assert.Nil(t, err)
+	assert.Nil(t, repo.Restore(ds))
+	assertSameRepo(t, src, repo.proj)
+
+	assert.Nil(t, repo.Rebuild())
+	assertSameRepo(t, src, repo.proj)
+}
+
+func TestFileEventStore_snapshot(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenFileEventStore(dir, FsyncNever)
+	assert.Nil(t, err)
+	repo, err := NewEventRepo(store)
+	assert.Nil(t, err)
+	a, _ := fillRepo(t, repo.proj)
+	assert.Nil(t, repo.Snapshot())
+	_, err = repo.RecordOperation(models.OperationWithdraw, []models.LedgerEntry{{WalletID: a.ID(), Amount: -1}})
+	assert.Nil(t, err)
+	assert.Nil(t, store.Close())
+
+	reopened, err := OpenFileEventStore(dir, FsyncAlways)
+	assert.Nil(t, err)
+	defer reopened.Close()
+	assert.Equal(t, store.LastSeq(), reopened.LastSeq())
+	snap, err := reopened.LatestSnapshot()
+	assert.Nil(t, err)
+	assert.Equal(t, store.LastSeq()-1, snap.Seq)
+
+	fromSnapshot, err := NewEventRepo(reopened)
+	assert.Nil(t, err)
+	assertSameRepo(t, repo.proj, fromSnapshot.proj)
+
+	assert.Nil(t, fromSnapshot.Rebuild())
+	assertSameRepo(t, repo.proj, fromSnapshot.proj)
+}
+
+func TestFileEventStore_snapshotOffset(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenFileEventStore(dir, FsyncNever)
+	assert.Nil(t, err)
+	repo, err := NewEventRepo(store)
+	assert.Nil(t, err)
+	a, _ := fillRepo(t, repo.proj)
+	assert.Nil(t, repo.Snapshot())
+	_, err = repo.RecordOperation(models.OperationWithdraw, []models.LedgerEntry{{WalletID: a.ID(), Amount: -1}})
+	assert.Nil(t, err)
+	assert.Nil(t, store.Close())
+
+	reopened, err := OpenFileEventStore(dir, FsyncNever)
+	assert.Nil(t, err)
+	defer reopened.Close()
+	snap, err := reopened.LatestSnapshot()
+	assert.Nil(t, err)
+	assert.Greater(t, snap.Offset, int64(0))
+
+	// события до снимка не читаются: их порча не мешает восстановлению
+	file, err := os.OpenFile(filepath.Join(dir, eventsFile), os.O_WRONLY, 0o600)
+	assert.Nil(t, err)
+	_, err = file.WriteAt([]byte("x"), 0)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	fromSnapshot, err := NewEventRepo(reopened)
+	assert.Nil(t, err)
+	assertSameRepo(t, repo.proj, fromSnapshot.proj)
+	assert.NotNil(t, fromSnapshot.Rebuild())
+}
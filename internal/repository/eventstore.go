@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+const (
+	// eventsFile файл потока событий в каталоге FileEventStore.
+	eventsFile = "events.log"
+	// maxRecentFrames сколько последних записей помнит FileEventStore, чтобы найти позицию снимка.
+	maxRecentFrames = 1024
+)
+
+// MemoryEventStore поток событий и снимки в памяти.
+type MemoryEventStore struct {
+	// mu для конкурентного доступа к events и snapshot
+	mu       *sync.RWMutex
+	events   []models.WalletEvent
+	snapshot *models.DatasetSnapshot
+}
+
+// NewMemoryEventStore конструктор потока событий в памяти.
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{
+		mu: new(sync.RWMutex),
+	}
+}
+
+// Append дописываем события с очередными номерами.
+func (store *MemoryEventStore) Append(events []models.WalletEvent) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, ev := range events {
+		ev.Seq = uint64(len(store.events)) + 1
+		store.events = append(store.events, ev)
+	}
+	return nil
+}
+
+// Events события с номером больше after.
+func (store *MemoryEventStore) Events(after uint64, fn func(ev models.WalletEvent) error) error {
+	store.mu.RLock()
+	var events []models.WalletEvent
+	if after < uint64(len(store.events)) {
+		events = store.events[after:]
+	}
+	store.mu.RUnlock()
+
+	for _, ev := range events {
+		err := fn(ev)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LastSeq номер последнего события.
+func (store *MemoryEventStore) LastSeq() uint64 {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return uint64(len(store.events))
+}
+
+// SaveSnapshot запоминаем снимок, хранится только последний.
+func (store *MemoryEventStore) SaveSnapshot(snap *models.DatasetSnapshot) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.snapshot = snap
+	return nil
+}
+
+// LatestSnapshot последний снимок.
+func (store *MemoryEventStore) LatestSnapshot() (*models.DatasetSnapshot, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.snapshot, nil
+}
+
+// FileEventStore поток событий в файле: каждая строка - события одной транзакции с контрольной суммой.
+// Поток не сокращается, снимки хранятся рядом и ускоряют только восстановление проекций:
+// в снимке сохраняется смещение в файле, и события до него не читаются.
+type FileEventStore struct {
+	// mu для конкурентного доступа к file, seq и позициям
+	mu   *sync.Mutex
+	dir  string
+	sync bool
+	file *os.File
+	seq  uint64
+	// offset смещение в файле после последней записи
+	offset int64
+	// recent позиции последних записей
+	recent []framePos
+	// start позиция последнего прочитанного или сохраненного снимка
+	start framePos
+	// err ошибка записи, после нее поток не принимает событий: в файле может остаться часть строки
+	err error
+}
+
+// framePos позиция в файле потока после записи, последнее событие которой имеет номер seq.
+type framePos struct {
+	seq    uint64
+	offset int64
+}
+
+// OpenFileEventStore открываем поток событий в каталоге dir.
+// Оборванная при сбое последняя запись отбрасывается.
+// policy - FsyncAlways(по умолчанию) или FsyncNever, сброс по интервалу не поддерживается.
+func OpenFileEventStore(dir string, policy FsyncPolicy) (*FileEventStore, error) {
+	switch policy {
+	case "", FsyncAlways, FsyncNever:
+	default:
+		return nil, fmt.Errorf("fsync policy %q is not supported by event store", policy)
+	}
+
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create dir %s: %w", dir, err)
+	}
+
+	store := &FileEventStore{
+		mu:   new(sync.Mutex),
+		dir:  dir,
+		sync: policy != FsyncNever,
+	}
+	path := filepath.Join(dir, eventsFile)
+	if _, err = os.Stat(path); err == nil {
+		// проверяются только контрольные суммы, декодируется последняя запись
+		var last []byte
+		err = readFrames(path, 0, true, func(line []byte) error {
+			data, err := frameData(line)
+			if err != nil {
+				return err
+			}
+			last = data
+			store.offset += int64(len(line))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			var events []models.WalletEvent
+			err = json.Unmarshal(last, &events)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode last events: %w", err)
+			}
+			if len(events) > 0 {
+				store.seq = events[len(events)-1].Seq
+			}
+		}
+	}
+
+	store.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open event store: %w", err)
+	}
+	return store, nil
+}
+
+// Close закрываем файл потока.
+func (store *FileEventStore) Close() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	err := store.file.Sync()
+	if errClose := store.file.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// Append дописываем события транзакции одной строкой.
+func (store *FileEventStore) Append(events []models.WalletEvent) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.err != nil {
+		return fmt.Errorf("event store is failed: %w", store.err)
+	}
+
+	batch := make([]models.WalletEvent, len(events))
+	for i, ev := range events {
+		ev.Seq = store.seq + uint64(i) + 1
+		batch[i] = ev
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("cannot encode events: %w", err)
+	}
+	line := frame(data)
+	_, err = store.file.Write(line)
+	if err == nil && store.sync {
+		err = store.file.Sync()
+	}
+	if err != nil {
+		store.err = err
+		return fmt.Errorf("cannot write events: %w", err)
+	}
+
+	store.seq += uint64(len(batch))
+	store.offset += int64(len(line))
+	store.recent = append(store.recent, framePos{seq: store.seq, offset: store.offset})
+	if len(store.recent) > maxRecentFrames {
+		store.recent = store.recent[len(store.recent)-maxRecentFrames:]
+	}
+	return nil
+}
+
+// Events события с номером больше after.
+// Если известна позиция после события after(снимок), чтение начинается с нее.
+func (store *FileEventStore) Events(after uint64, fn func(ev models.WalletEvent) error) error {
+	from, _ := store.position(after)
+	return readFrames(filepath.Join(store.dir, eventsFile), from, false, func(line []byte) error {
+		var events []models.WalletEvent
+		err := unframe(line, &events)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			if ev.Seq <= after {
+				continue
+			}
+			err = fn(ev)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LastSeq номер последнего события.
+func (store *FileEventStore) LastSeq() uint64 {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.seq
+}
+
+// position смещение в файле после события seq, false если оно неизвестно.
+func (store *FileEventStore) position(seq uint64) (int64, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	switch {
+	case seq == 0:
+		return 0, true
+	case seq == store.seq:
+		return store.offset, true
+	case seq == store.start.seq:
+		return store.start.offset, true
+	}
+	for i := len(store.recent) - 1; i >= 0; i-- {
+		if store.recent[i].seq == seq {
+			return store.recent[i].offset, true
+		}
+	}
+	return 0, false
+}
+
+// SaveSnapshot пишем снимок со смещением в файле после его последнего события и удаляем предыдущие.
+func (store *FileEventStore) SaveSnapshot(snap *models.DatasetSnapshot) error {
+	saved := *snap
+	saved.Offset, _ = store.position(snap.Seq)
+	err := writeSnapshot(store.dir, &saved)
+	if err != nil {
+		return err
+	}
+	if saved.Offset > 0 {
+		store.mu.Lock()
+		store.start = framePos{seq: saved.Seq, offset: saved.Offset}
+		store.mu.Unlock()
+	}
+
+	snapshots, err := listSegments(store.dir, snapshotPrefix, snapshotExt)
+	if err != nil {
+		return err
+	}
+	for _, seq := range snapshots {
+		if seq >= snap.Seq {
+			break
+		}
+		err = os.Remove(filepath.Join(store.dir, segmentName(snapshotPrefix, seq, snapshotExt)))
+		if err != nil {
+			return fmt.Errorf("cannot remove snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// LatestSnapshot последний снимок, события после него будут читаться со смещения из снимка.
+func (store *FileEventStore) LatestSnapshot() (*models.DatasetSnapshot, error) {
+	snapshots, err := listSegments(store.dir, snapshotPrefix, snapshotExt)
+	if err != nil || len(snapshots) == 0 {
+		return nil, err
+	}
+	snap, err := readSnapshot(filepath.Join(store.dir, segmentName(snapshotPrefix, snapshots[len(snapshots)-1], snapshotExt)))
+	if err != nil {
+		return nil, err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	// смещение за концом файла - снимок от другого потока, такой позиции не доверяем
+	if snap.Offset > 0 && snap.Seq <= store.seq && snap.Offset <= store.offset {
+		store.start = framePos{seq: snap.Seq, offset: snap.Offset}
+	}
+	return snap, nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	errHoldNotFound  = fmt.Errorf("hold %w", models.ErrNotFound)
+	errHoldNotActive = errors.New("hold is already captured or released")
+)
+
+// CreateHold блокируем amount на кошельке до expiresAt.
+// Достаточность средств проверяет вызывающий.
+func (repo *WalletRepository) CreateHold(walletID string, amount float64, expiresAt time.Time) (hold models.Hold, err error) {
+	err = repo.apply([]string{walletID}, func(tx *transaction) error {
+		hold, err = tx.CreateHold(walletID, amount, expiresAt)
+		return err
+	})
+	return hold, err
+}
+
+// HoldByID блокировка по идентификатору.
+// Снятые блокировки удаляются из индекса, для них вернется ошибка errHoldNotFound.
+func (repo *WalletRepository) HoldByID(id string) (models.Hold, error) {
+	repo.muWallets.RLock()
+	defer repo.muWallets.RUnlock()
+
+	rec := repo.holdRecord(id)
+	if rec == nil {
+		return models.Hold{}, errHoldNotFound
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return holdOf(rec, id, repo.now().UTC())
+}
+
+// CloseHold снимаем блокировку с кошелька.
+// Истекшая блокировка тоже снимается и возвращается со статусом HoldStatusExpired.
+func (repo *WalletRepository) CloseHold(id string) (hold models.Hold, err error) {
+	walletID, ok := repo.holdWallet(id)
+	if !ok {
+		return models.Hold{}, errHoldNotFound
+	}
+
+	err = repo.apply([]string{walletID}, func(tx *transaction) error {
+		hold, err = tx.CloseHold(id)
+		return err
+	})
+	return hold, err
+}
+
+// insertHold добавляем блокировку кошельку.
+// Вызывающий должен владеть блокировкой записи(или эксклюзивной блокировкой хранилища).
+func (repo *WalletRepository) insertHold(rec *record, amount float64, expiresAt time.Time) models.Hold {
+	now := repo.now().UTC()
+	hold := models.Hold{
+		ID:        genNewID(),
+		WalletID:  rec.wallet.id,
+		Amount:    amount,
+		Status:    models.HoldStatusActive,
+		CreatedAt: now,
+		ExpiresAt: expiresAt.UTC(),
+	}
+
+	repo.muIndex.Lock()
+	repo.holds[hold.ID] = hold.WalletID
+	repo.muIndex.Unlock()
+
+	rec.setHolds(&hold, "", now)
+	return hold
+}
+
+// ExpireHolds снимаем истекшие блокировки всех кошельков и удаляем их из индекса.
+// Возвращает число снятых блокировок.
+func (repo *WalletRepository) ExpireHolds() (int, error) {
+	repo.muIndex.RLock()
+	walletIDs := make(map[string]struct{}, len(repo.holds))
+	for _, walletID := range repo.holds {
+		walletIDs[walletID] = struct{}{}
+	}
+	repo.muIndex.RUnlock()
+
+	var expired int
+	for walletID := range walletIDs {
+		err := repo.apply([]string{walletID}, func(tx *transaction) error {
+			rec, err := tx.record(walletID)
+			if errors.Is(err, errWalletNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			now := tx.repo.now().UTC()
+			if !rec.hasExpiredHolds(now) {
+				return nil
+			}
+			tx.save(rec)
+			before := len(rec.wallet.holds)
+			rec.setHolds(nil, "", now)
+			expired += before - len(rec.wallet.holds)
+			return nil
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// RunHoldExpiry снимаем истекшие блокировки каждые every, пока не отменен ctx.
+func (repo *WalletRepository) RunHoldExpiry(ctx context.Context, every time.Duration) {
+	runHoldExpiry(ctx, every, repo.ExpireHolds)
+}
+
+// runHoldExpiry вызываем expire каждые every, пока не отменен ctx.
+func runHoldExpiry(ctx context.Context, every time.Duration, expire func() (int, error)) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := expire()
+			if err != nil {
+				log.Errorf("hold expiry failed: %s", err.Error())
+			}
+			if n > 0 {
+				log.Debugf("holds expired: %d", n)
+			}
+		}
+	}
+}
+
+// holdRecord ищем кошелек блокировки, nil если блокировка не найдена.
+func (repo *WalletRepository) holdRecord(id string) *record {
+	walletID, ok := repo.holdWallet(id)
+	if !ok {
+		return nil
+	}
+	return repo.lookup(walletID)
+}
+
+// holdWallet идентификатор кошелька блокировки.
+func (repo *WalletRepository) holdWallet(id string) (string, bool) {
+	repo.muIndex.RLock()
+	defer repo.muIndex.RUnlock()
+
+	walletID, ok := repo.holds[id]
+	return walletID, ok
+}
+
+func holdOf(rec *record, id string, now time.Time) (models.Hold, error) {
+	hold, ok := rec.hold(id, now)
+	if !ok {
+		return models.Hold{}, errHoldNotActive
+	}
+	return hold, nil
+}
+
+func closeHold(rec *record, id string, now time.Time) (models.Hold, error) {
+	hold, err := holdOf(rec, id, now)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	rec.setHolds(nil, id, now)
+	return hold, nil
+}
+
+// CreateHold блокируем amount на кошельке, доступном транзакции.
+func (tx *transaction) CreateHold(walletID string, amount float64, expiresAt time.Time) (models.Hold, error) {
+	rec, err := tx.record(walletID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	tx.save(rec)
+	hold := tx.repo.insertHold(rec, amount, expiresAt)
+	tx.createdHolds = append(tx.createdHolds, hold.ID)
+	return hold, nil
+}
+
+// HoldByID блокировка кошелька, доступного транзакции.
+func (tx *transaction) HoldByID(id string) (models.Hold, error) {
+	rec, err := tx.holdRecord(id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	return holdOf(rec, id, tx.repo.now().UTC())
+}
+
+// CloseHold снимаем блокировку с кошелька, доступного транзакции.
+func (tx *transaction) CloseHold(id string) (models.Hold, error) {
+	rec, err := tx.holdRecord(id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	tx.save(rec)
+	return closeHold(rec, id, tx.repo.now().UTC())
+}
+
+// holdRecord кошелек блокировки, если он доступен транзакции.
+func (tx *transaction) holdRecord(id string) (*record, error) {
+	walletID, ok := tx.repo.holdWallet(id)
+	if !ok {
+		return nil, errHoldNotFound
+	}
+	return tx.record(walletID)
+}
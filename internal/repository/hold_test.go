@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateHold_expires(t *testing.T) {
+	repo := NewRepo()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return clock }
+
+	created := repo.Create("test_name", 100, true, "test_owner", nil)
+	hold, err := repo.CreateHold(created.ID(), 30, clock.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, models.HoldStatusActive, hold.Status)
+
+	wallet, err := repo.ByID(created.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), wallet.Balance())
+	assert.Equal(t, float64(30), wallet.Held())
+	assert.Equal(t, float64(70), wallet.Available())
+	assert.Equal(t, uint64(2), wallet.Version())
+
+	clock = clock.Add(time.Hour)
+	wallet, err = repo.ByID(created.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), wallet.Available())
+
+	hold, err = repo.HoldByID(hold.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.HoldStatusExpired, hold.Status)
+
+	// истекшая блокировка отбрасывается при следующем изменении блокировок и удаляется из индекса
+	next, err := repo.CreateHold(created.ID(), 10, clock.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{next.ID: created.ID()}, repo.holds)
+}
+
+func TestExpireHolds(t *testing.T) {
+	repo := NewRepo()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return clock }
+
+	created := repo.Create("test_name", 100, true, "test_owner", nil)
+	short, err := repo.CreateHold(created.ID(), 30, clock.Add(time.Minute))
+	assert.Nil(t, err)
+	long, err := repo.CreateHold(created.ID(), 20, clock.Add(time.Hour))
+	assert.Nil(t, err)
+
+	expired, err := repo.ExpireHolds()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, expired)
+
+	clock = clock.Add(time.Minute)
+	expired, err = repo.ExpireHolds()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, expired)
+	_, err = repo.HoldByID(short.ID)
+	assert.True(t, errors.Is(err, errHoldNotFound))
+	assert.Equal(t, map[string]string{long.ID: created.ID()}, repo.holds)
+
+	wallet, err := repo.ByID(created.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(20), wallet.Held())
+}
+
+func TestCloseHold_twice(t *testing.T) {
+	repo := NewRepo()
+	created := repo.Create("test_name", 100, true, "test_owner", nil)
+	hold, err := repo.CreateHold(created.ID(), 30, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+
+	_, err = repo.CloseHold(hold.ID)
+	assert.Nil(t, err)
+	// снятая блокировка удаляется из индекса
+	assert.Empty(t, repo.holds)
+	_, err = repo.CloseHold(hold.ID)
+	assert.True(t, errors.Is(err, errHoldNotFound))
+
+	_, err = repo.HoldByID("unknown")
+	assert.True(t, errors.Is(err, errHoldNotFound))
+}
+
+func TestCreateHold_rollback(t *testing.T) {
+	repo := NewRepo()
+	created := repo.Create("test_name", 100, true, "test_owner", nil)
+	errFail := errors.New("fail")
+
+	var hold models.Hold
+	err := repo.Transaction(context.Background(), []string{created.ID()}, func(tx models.WalletRepository) error {
+		var err error
+		hold, err = tx.CreateHold(created.ID(), 30, time.Now().Add(time.Hour))
+		assert.Nil(t, err)
+		return errFail
+	})
+	assert.True(t, errors.Is(err, errFail))
+
+	wallet, err := repo.ByID(created.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), wallet.Available())
+	_, err = repo.HoldByID(hold.ID)
+	assert.True(t, errors.Is(err, errHoldNotFound))
+}
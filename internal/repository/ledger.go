@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// RecordOperation записываем проводки операции в журналы операций кошельков.
+func (repo *WalletRepository) RecordOperation(opType models.OperationType, entries []models.LedgerEntry) (id string, err error) {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.WalletID)
+	}
+
+	err = repo.apply(ids, func(tx *transaction) error {
+		id, err = tx.RecordOperation(opType, entries)
+		return err
+	})
+	return id, err
+}
+
+// Ledger проводки по фильтру.
+func (repo *WalletRepository) Ledger(filter models.LedgerFilter) ([]models.LedgerEntry, error) {
+	repo.muWallets.RLock()
+	defer repo.muWallets.RUnlock()
+
+	var out []models.LedgerEntry
+	for _, rec := range repo.ledgerRecords(filter) {
+		rec.mu.Lock()
+		out = appendLedger(out, rec, filter)
+		rec.mu.Unlock()
+	}
+	sortLedger(out)
+	return out, nil
+}
+
+// appendOperation добавляем проводки операции в журналы кошельков records.
+// Вызывающий должен владеть блокировками записей(или эксклюзивной блокировкой хранилища).
+func (repo *WalletRepository) appendOperation(records map[string]*record, opType models.OperationType, entries []models.LedgerEntry) string {
+	id := genNewID()
+	balances := make(map[string]float64, len(records))
+	for walletID, rec := range records {
+		balances[walletID] = rec.wallet.balance
+	}
+
+	walletIDs := make([]string, 0, len(records))
+	for _, entry := range operationEntries(id, opType, entries, balances, repo.now().UTC()) {
+		rec := records[entry.WalletID]
+		rec.ledger = append(rec.ledger, entry)
+	}
+	for walletID := range records {
+		walletIDs = append(walletIDs, walletID)
+	}
+	sort.Strings(walletIDs)
+
+	repo.muIndex.Lock()
+	repo.operations[id] = walletIDs
+	repo.muIndex.Unlock()
+
+	return id
+}
+
+// operationEntries проводки операции id, записанной в now.
+// Balance каждой проводки восстанавливается от текущего баланса кошелька(balances) с конца операции,
+// поэтому кошелек может встречаться в операции несколько раз.
+func operationEntries(id string, opType models.OperationType, entries []models.LedgerEntry, balances map[string]float64, now time.Time) []models.LedgerEntry {
+	out := append([]models.LedgerEntry(nil), entries...)
+	after := make(map[string]float64, len(balances))
+	for i := len(out) - 1; i >= 0; i-- {
+		entry := &out[i]
+		balance, ok := after[entry.WalletID]
+		if !ok {
+			balance = balances[entry.WalletID]
+		}
+		entry.OperationID = id
+		entry.Type = opType
+		entry.Time = now
+		entry.Balance = balance
+		after[entry.WalletID] = balance - entry.Amount
+	}
+	return out
+}
+
+// ledgerRecords кошельки, проводки которых могут попасть в выборку.
+func (repo *WalletRepository) ledgerRecords(filter models.LedgerFilter) []*record {
+	repo.muIndex.RLock()
+	defer repo.muIndex.RUnlock()
+
+	var ids []string
+	switch {
+	case filter.WalletID != "":
+		ids = []string{filter.WalletID}
+	case filter.OperationID != "":
+		ids = repo.operations[filter.OperationID]
+	case filter.Reverses != "":
+		// сторнирование затрагивает только кошельки исходной операции
+		ids = repo.operations[filter.Reverses]
+	default:
+		return append([]*record(nil), repo.wallets...)
+	}
+
+	records := make([]*record, 0, len(ids))
+	for _, id := range ids {
+		if rec, ok := repo.index[id]; ok {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// operationWallets кошельки операции.
+func (repo *WalletRepository) operationWallets(id string) []string {
+	repo.muIndex.RLock()
+	defer repo.muIndex.RUnlock()
+
+	return repo.operations[id]
+}
+
+// appendLedger добавляем к out проводки кошелька, подходящие под фильтр.
+// Вызывающий должен владеть блокировкой записи(или эксклюзивной блокировкой хранилища).
+func appendLedger(out []models.LedgerEntry, rec *record, filter models.LedgerFilter) []models.LedgerEntry {
+	for _, entry := range rec.ledger {
+		if matchLedger(entry, filter) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func matchLedger(entry models.LedgerEntry, filter models.LedgerFilter) bool {
+	if filter.WalletID != "" && entry.WalletID != filter.WalletID {
+		return false
+	}
+	if filter.OperationID != "" && entry.OperationID != filter.OperationID {
+		return false
+	}
+	if filter.Reverses != "" && entry.Reverses != filter.Reverses {
+		return false
+	}
+	if !filter.From.IsZero() && entry.Time.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !entry.Time.Before(filter.To) {
+		return false
+	}
+	return true
+}
+
+// sortLedger проводки разных кошельков упорядочиваем по времени записи.
+func sortLedger(entries []models.LedgerEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+}
+
+// RecordOperation записываем проводки операции по кошелькам, доступным транзакции.
+func (tx *transaction) RecordOperation(opType models.OperationType, entries []models.LedgerEntry) (string, error) {
+	records := make(map[string]*record, len(entries))
+	for _, entry := range entries {
+		rec, err := tx.record(entry.WalletID)
+		if err != nil {
+			return "", err
+		}
+		records[entry.WalletID] = rec
+	}
+
+	for _, rec := range records {
+		if _, ok := tx.ledgerLen[rec]; !ok {
+			tx.ledgerLen[rec] = len(rec.ledger)
+		}
+	}
+	id := tx.repo.appendOperation(records, opType, entries)
+	tx.createdOps = append(tx.createdOps, id)
+	return id, nil
+}
+
+// Ledger проводки по кошелькам, доступным транзакции.
+// Выборка по всем кошелькам доступна только в эксклюзивной транзакции.
+func (tx *transaction) Ledger(filter models.LedgerFilter) ([]models.LedgerEntry, error) {
+	var records []*record
+	switch {
+	case tx.exclusive:
+		records = tx.repo.ledgerRecords(filter)
+	case filter.WalletID != "":
+		rec, err := tx.record(filter.WalletID)
+		if err != nil {
+			return nil, err
+		}
+		records = []*record{rec}
+	case filter.OperationID != "" || filter.Reverses != "":
+		opID := filter.OperationID
+		if opID == "" {
+			opID = filter.Reverses
+		}
+		for _, id := range tx.repo.operationWallets(opID) {
+			rec, err := tx.record(id)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, rec)
+		}
+	default:
+		return nil, fmt.Errorf("ledger of all wallets inside wallet transaction: %w", errWalletNotLocked)
+	}
+
+	var out []models.LedgerEntry
+	for _, rec := range records {
+		out = appendLedger(out, rec, filter)
+	}
+	sortLedger(out)
+	return out, nil
+}
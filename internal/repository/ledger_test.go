@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordOperation_balances(t *testing.T) {
+	repo := NewRepo()
+	a := repo.Create("a", 0, true, "owner", nil)
+	b := repo.Create("b", 0, true, "owner", nil)
+
+	err := repo.Transaction(context.Background(), []string{a.ID(), b.ID()}, func(tx models.WalletRepository) error {
+		assert.Nil(t, tx.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](70)}))
+		assert.Nil(t, tx.UpdateByID(b.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](30)}))
+		_, err := tx.RecordOperation(models.OperationBatch, []models.LedgerEntry{
+			{WalletID: a.ID(), Amount: 100},
+			{WalletID: a.ID(), Amount: -30, Counterparty: b.ID()},
+			{WalletID: b.ID(), Amount: 30, Counterparty: a.ID()},
+		})
+		return err
+	})
+	assert.Nil(t, err)
+
+	entries, err := repo.Ledger(models.LedgerFilter{WalletID: a.ID()})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, float64(100), entries[0].Balance)
+	assert.Equal(t, float64(70), entries[1].Balance)
+	assert.Equal(t, models.OperationBatch, entries[1].Type)
+
+	entries, err = repo.Ledger(models.LedgerFilter{OperationID: entries[0].OperationID})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestRecordOperation_rollback(t *testing.T) {
+	repo := NewRepo()
+	a := repo.Create("a", 0, true, "owner", nil)
+	errFail := errors.New("fail")
+
+	err := repo.Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+		_, err := tx.RecordOperation(models.OperationDeposit, []models.LedgerEntry{{WalletID: a.ID(), Amount: 10}})
+		assert.Nil(t, err)
+		return errFail
+	})
+	assert.True(t, errors.Is(err, errFail))
+
+	entries, err := repo.Ledger(models.LedgerFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCreate_openingEntry(t *testing.T) {
+	repo := NewRepo()
+	a := repo.Create("a", 100, true, "owner", nil)
+	b := repo.Create("b", 0, true, "owner", nil)
+
+	entries, err := repo.Ledger(models.LedgerFilter{WalletID: a.ID()})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, models.OperationOpening, entries[0].Type)
+	assert.Equal(t, float64(100), entries[0].Balance)
+
+	entries, err = repo.Ledger(models.LedgerFilter{WalletID: b.ID()})
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// listBatch сколько кошельков читается из индекса хранилища за раз при сборе страницы.
+const listBatch = 256
+
+// keySep разделитель частей ключа сортировки.
+const keySep = "\x00"
+
+// cursor позиция последнего кошелька страницы: порядок сортировки и ключ кошелька в нем.
+type cursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+	Key  string `json:"k"`
+}
+
+// listQuery разобранный фильтр списка кошельков.
+type listQuery struct {
+	filter models.WalletFilter
+	// after ключ последнего кошелька предыдущей страницы, пустой для первой страницы
+	after string
+	// lo, hi границы ключей, заданные фильтром: lo <= ключ < hi, пустая граница не ограничивает
+	lo, hi string
+}
+
+// listEntry кошелек и его ключ в индексе хранилища, nil если кошелька уже нет.
+type listEntry struct {
+	key string
+	wal *wallet
+}
+
+// newListQuery проверяем сортировку и курсор фильтра и вычисляем границы ключей.
+// Границы задают фильтры по полю сортировки: баланс для SortByBalance и UpdatedSince для SortByUpdated.
+func newListQuery(filter models.WalletFilter) (*listQuery, error) {
+	if filter.Sort == "" {
+		filter.Sort = models.SortByCreated
+	}
+	switch filter.Sort {
+	case models.SortByCreated, models.SortByUpdated, models.SortByName, models.SortByBalance:
+	default:
+		return nil, fmt.Errorf("unknown sort %q: %w", filter.Sort, models.ErrInvalidArgument)
+	}
+
+	q := &listQuery{filter: filter}
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != filter.Sort || c.Desc != filter.Desc {
+			return nil, fmt.Errorf("cursor for sort %q desc %t: %w", c.Sort, c.Desc, models.ErrInvalidArgument)
+		}
+		q.after = c.Key
+	}
+
+	switch filter.Sort {
+	case models.SortByBalance:
+		if filter.MinBalance != nil {
+			q.lo = floatKey(*filter.MinBalance)
+		}
+		if filter.MaxBalance != nil {
+			// ключи с балансом MaxBalance продолжаются разделителем, он меньше "\x01"
+			q.hi = floatKey(*filter.MaxBalance) + "\x01"
+		}
+	case models.SortByUpdated:
+		if !filter.UpdatedSince.IsZero() {
+			q.lo = timeKey(filter.UpdatedSince)
+		}
+	}
+	return q, nil
+}
+
+// window границы ключей, которые осталось просмотреть после ключа from(пустой - с начала выборки).
+func (q *listQuery) window(from string) (string, string) {
+	lo, hi := q.lo, q.hi
+	if from == "" {
+		return lo, hi
+	}
+	if q.filter.Desc {
+		if hi == "" || from < hi {
+			hi = from
+		}
+		return lo, hi
+	}
+	// from + keySep - наименьшая строка больше from
+	if next := from + keySep; next > lo {
+		lo = next
+	}
+	return lo, hi
+}
+
+// page собираем страницу, просматривая кошельки в порядке сортировки с позиции курсора.
+// fetch возвращает до n кошельков с ключами из [lo, hi) в направлении сортировки.
+// Кошелек, ключ которого изменился после чтения индекса, пропускается: он уже стоит на другом месте.
+func (q *listQuery) page(fetch func(lo, hi string, n int) ([]listEntry, error)) (*models.WalletPage, error) {
+	n := listBatch
+	if q.filter.Limit >= n {
+		n = q.filter.Limit + 1
+	}
+
+	page := &models.WalletPage{Wallets: make([]models.Walleter, 0)}
+	from, last := q.after, ""
+	for {
+		lo, hi := q.window(from)
+		if hi != "" && lo >= hi {
+			return page, nil
+		}
+		entries, err := fetch(lo, hi, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			from = entry.key
+			if entry.wal == nil || listKey(q.filter.Sort, entry.wal) != entry.key || !matchFilter(entry.wal, q.filter) {
+				continue
+			}
+			if q.filter.Limit > 0 && len(page.Wallets) == q.filter.Limit {
+				page.NextCursor = encodeCursor(&cursor{Sort: q.filter.Sort, Desc: q.filter.Desc, Key: last})
+				return page, nil
+			}
+			page.Wallets = append(page.Wallets, entry.wal)
+			last = entry.key
+		}
+		if len(entries) < n {
+			return page, nil
+		}
+	}
+}
+
+// sortedRange позиции до n ключей из [lo, hi) упорядоченного набора размера size в направлении сортировки.
+func (q *listQuery) sortedRange(size int, key func(i int) string, lo, hi string, n int) []int {
+	start := sort.Search(size, func(i int) bool { return key(i) >= lo })
+	end := size
+	if hi != "" {
+		end = sort.Search(size, func(i int) bool { return key(i) >= hi })
+	}
+
+	var positions []int
+	for i := start; i < end && len(positions) < n; i++ {
+		pos := i
+		if q.filter.Desc {
+			pos = end - 1 - (i - start)
+		}
+		positions = append(positions, pos)
+	}
+	return positions
+}
+
+// listWallets выборка кошельков по фильтру из набора wallets, который сортируется целиком.
+// Используется для небольших наборов(кошельки владельца) и в эксклюзивной транзакции,
+// остальные выборки хранилища читают страницу из индексов.
+func listWallets(wallets []*wallet, filter models.WalletFilter) (*models.WalletPage, error) {
+	q, err := newListQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]listEntry, 0, len(wallets))
+	for _, wal := range wallets {
+		entries = append(entries, listEntry{key: listKey(q.filter.Sort, wal), wal: wal})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	return q.page(func(lo, hi string, n int) ([]listEntry, error) {
+		positions := q.sortedRange(len(entries), func(i int) string { return entries[i].key }, lo, hi, n)
+		found := make([]listEntry, 0, len(positions))
+		for _, i := range positions {
+			found = append(found, entries[i])
+		}
+		return found, nil
+	})
+}
+
+// listKey ключ кошелька в порядке сортировки sort: значение поля сортировки, время создания и id.
+// Ключи сравниваются как строки в том же порядке, что и кошельки, поэтому по ним строятся индексы хранилищ.
+func listKey(sort string, wal *wallet) string {
+	var field string
+	switch sort {
+	case models.SortByUpdated:
+		field = timeKey(wal.updatedAt)
+	case models.SortByName:
+		field = wal.name
+	case models.SortByBalance:
+		field = floatKey(wal.balance)
+	}
+	return field + keySep + timeKey(wal.createdAt) + keySep + wal.id
+}
+
+// listKeyID идентификатор кошелька из ключа сортировки.
+func listKeyID(key string) string {
+	return key[strings.LastIndex(key, keySep)+1:]
+}
+
+// timeKey время в виде строки, сравнение которой совпадает со сравнением времени.
+func timeKey(t time.Time) string {
+	return fmt.Sprintf("%016x", uint64(t.UnixNano())^(1<<63))
+}
+
+// floatKey число в виде строки, сравнение которой совпадает со сравнением чисел.
+func floatKey(f float64) string {
+	if f == 0 {
+		// -0 и 0 равны
+		f = 0
+	}
+	bits := math.Float64bits(f)
+	if bits>>63 == 0 {
+		bits |= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	return fmt.Sprintf("%016x", bits)
+}
+
+func matchFilter(wal *wallet, filter models.WalletFilter) bool {
+	if filter.Owner != "" && wal.owner != filter.Owner {
+		return false
+	}
+	if filter.Status != nil && wal.status != *filter.Status {
+		return false
+	}
+	if filter.NamePrefix != "" && !strings.HasPrefix(strings.ToLower(wal.name), strings.ToLower(filter.NamePrefix)) {
+		return false
+	}
+	if filter.NameContains != "" && !strings.Contains(strings.ToLower(wal.name), strings.ToLower(filter.NameContains)) {
+		return false
+	}
+	if filter.MinBalance != nil && wal.balance < *filter.MinBalance {
+		return false
+	}
+	if filter.MaxBalance != nil && wal.balance > *filter.MaxBalance {
+		return false
+	}
+	if !filter.UpdatedSince.IsZero() && wal.updatedAt.Before(filter.UpdatedSince) {
+		return false
+	}
+
+	return true
+}
+
+func encodeCursor(c *cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", models.ErrInvalidArgument)
+	}
+	c := &cursor{}
+	err = json.Unmarshal(data, c)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", models.ErrInvalidArgument)
+	}
+	return c, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// listSorts поля сортировки, для каждого из которых хранилище ведет упорядоченный индекс.
+var listSorts = []string{models.SortByCreated, models.SortByUpdated, models.SortByName, models.SortByBalance}
+
+// listIndex кошельки хранилища, упорядоченные для выборки списка.
+// Для каждого поля сортировки кошельки упорядочены по listKey, поэтому страница
+// читается с позиции курсора, а не сортировкой всего хранилища.
+type listIndex struct {
+	// sorted кошельки по полю сортировки в порядке ключей
+	sorted map[string][]indexEntry
+	// owners кошельки владельца в порядке создания
+	owners map[string][]*record
+}
+
+// indexEntry кошелек и его ключ в индексе.
+type indexEntry struct {
+	key string
+	rec *record
+}
+
+// newListIndex строим индексы по кошелькам records.
+// Вызывающий должен владеть эксклюзивной блокировкой хранилища(или хранилище еще не доступно другим горутинам).
+func newListIndex(records []*record) *listIndex {
+	idx := &listIndex{
+		sorted: make(map[string][]indexEntry, len(listSorts)),
+		owners: make(map[string][]*record),
+	}
+	for _, by := range listSorts {
+		entries := make([]indexEntry, 0, len(records))
+		for _, rec := range records {
+			entries = append(entries, indexEntry{key: listKey(by, &rec.wallet), rec: rec})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].key < entries[j].key
+		})
+		idx.sorted[by] = entries
+	}
+	for _, rec := range records {
+		idx.owners[rec.wallet.owner] = append(idx.owners[rec.wallet.owner], rec)
+	}
+	return idx
+}
+
+// add добавляем новый кошелек.
+func (idx *listIndex) add(rec *record) {
+	for _, by := range listSorts {
+		idx.insert(by, indexEntry{key: listKey(by, &rec.wallet), rec: rec})
+	}
+	idx.owners[rec.wallet.owner] = append(idx.owners[rec.wallet.owner], rec)
+}
+
+// move переставляем кошелек, изменившийся с состояния before.
+func (idx *listIndex) move(rec *record, before *wallet) {
+	for _, by := range listSorts {
+		oldKey, newKey := listKey(by, before), listKey(by, &rec.wallet)
+		if oldKey == newKey {
+			continue
+		}
+		idx.remove(by, oldKey)
+		idx.insert(by, indexEntry{key: newKey, rec: rec})
+	}
+}
+
+func (idx *listIndex) insert(by string, entry indexEntry) {
+	entries := idx.sorted[by]
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].key >= entry.key })
+	entries = append(entries, indexEntry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = entry
+	idx.sorted[by] = entries
+}
+
+func (idx *listIndex) remove(by, key string) {
+	entries := idx.sorted[by]
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].key >= key })
+	if i == len(entries) || entries[i].key != key {
+		return
+	}
+	idx.sorted[by] = append(entries[:i], entries[i+1:]...)
+}
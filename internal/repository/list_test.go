@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList_filter(t *testing.T) {
+	repo := NewRepo()
+	repo.Create("alpha", 10, true, "owner_1", nil)
+	repo.Create("beta", 20, false, "owner_1", nil)
+	repo.Create("Alphabet", 30, true, "owner_2", nil)
+
+	page, err := repo.List(models.WalletFilter{Owner: "owner_1"})
+	assert.Nil(t, err)
+	assert.Len(t, page.Wallets, 2)
+
+	page, err = repo.List(models.WalletFilter{Status: utils.Ptr[bool](true), NameContains: "alpha"})
+	assert.Nil(t, err)
+	assert.Len(t, page.Wallets, 2)
+
+	page, err = repo.List(models.WalletFilter{MinBalance: utils.Ptr[float64](15), MaxBalance: utils.Ptr[float64](25)})
+	assert.Nil(t, err)
+	assert.Len(t, page.Wallets, 1)
+	assert.Equal(t, "beta", page.Wallets[0].Name())
+
+	page, err = repo.List(models.WalletFilter{NamePrefix: "ALPHA"})
+	assert.Nil(t, err)
+	assert.Len(t, page.Wallets, 2)
+}
+
+func TestList_pagination(t *testing.T) {
+	repo := NewRepo()
+	for _, name := range []string{"e", "b", "d", "a", "c"} {
+		repo.Create(name, 0, true, "owner", nil)
+	}
+
+	var names []string
+	filter := models.WalletFilter{Sort: models.SortByName, Desc: true, Limit: 2}
+	for {
+		page, err := repo.List(filter)
+		assert.Nil(t, err)
+		for _, w := range page.Wallets {
+			names = append(names, w.Name())
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	assert.Equal(t, []string{"e", "d", "c", "b", "a"}, names)
+
+	// курсор действует только в том порядке, в котором получен
+	page, err := repo.List(models.WalletFilter{Sort: models.SortByName, Limit: 2})
+	assert.Nil(t, err)
+	_, err = repo.List(models.WalletFilter{Sort: models.SortByName, Desc: true, Cursor: page.NextCursor})
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+}
+
+func TestList_invalidCursor(t *testing.T) {
+	repo := NewRepo()
+
+	_, err := repo.List(models.WalletFilter{Cursor: "???"})
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+
+	_, err = repo.List(models.WalletFilter{Sort: "unknown"})
+	assert.True(t, errors.Is(err, models.ErrInvalidArgument))
+}
+
+func TestList_updatedSince(t *testing.T) {
+	repo := NewRepo()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return clock }
+
+	first := repo.Create("first", 0, true, "owner", nil)
+	repo.Create("second", 0, true, "owner", nil)
+
+	clock = clock.Add(time.Hour)
+	assert.Nil(t, repo.UpdateByID(first.ID(), models.WalletUpdate{Name: utils.Ptr[string]("renamed")}))
+
+	page, err := repo.List(models.WalletFilter{UpdatedSince: clock, Sort: models.SortByUpdated})
+	assert.Nil(t, err)
+	assert.Len(t, page.Wallets, 1)
+	assert.Equal(t, "renamed", page.Wallets[0].Name())
+}
+
+func TestList_index(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := OpenRepo(PersistOptions{Dir: dir, Fsync: FsyncNever})
+	assert.Nil(t, err)
+
+	var ids []string
+	for i, name := range []string{"delta", "alpha", "charlie", "bravo", "echo", "alpha"} {
+		ids = append(ids, repo.Create(name, float64(i*10), true, "owner", nil).ID())
+	}
+	assert.Nil(t, repo.UpdateByID(ids[0], models.WalletUpdate{Balance: utils.Ptr[float64](35)}))
+	assert.Nil(t, repo.UpdateByID(ids[4], models.WalletUpdate{Name: utils.Ptr("able")}))
+	// откат не меняет индексы
+	err = repo.Transaction(context.Background(), []string{ids[1]}, func(tx models.WalletRepository) error {
+		assert.Nil(t, tx.UpdateByID(ids[1], models.WalletUpdate{Balance: utils.Ptr[float64](1000)}))
+		return errors.New("rollback")
+	})
+	assert.NotNil(t, err)
+	assertListIndex(t, repo)
+
+	assert.Nil(t, repo.Close())
+	reopened, err := OpenRepo(PersistOptions{Dir: dir, Fsync: FsyncNever})
+	assert.Nil(t, err)
+	defer reopened.Close()
+	assertListIndex(t, reopened)
+}
+
+// assertListIndex выборки из индексов постранично совпадают с сортировкой всех кошельков.
+func assertListIndex(t *testing.T, repo models.WalletRepository) {
+	var all []*wallet
+	for _, wal := range repo.All() {
+		all = append(all, wal.(*wallet))
+	}
+
+	for _, by := range listSorts {
+		for _, desc := range []bool{false, true} {
+			filter := models.WalletFilter{Sort: by, Desc: desc, MinBalance: utils.Ptr[float64](10), MaxBalance: utils.Ptr[float64](40)}
+			want, err := listWallets(all, filter)
+			assert.Nil(t, err)
+
+			var got []models.Walleter
+			filter.Limit = 2
+			for {
+				page, err := repo.List(filter)
+				assert.Nil(t, err)
+				got = append(got, page.Wallets...)
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+			assert.Equal(t, walletIDs(want.Wallets), walletIDs(got), "sort %s desc %t", by, desc)
+		}
+	}
+}
+
+func walletIDs(wallets []models.Walleter) []string {
+	ids := make([]string, 0, len(wallets))
+	for _, wal := range wallets {
+		ids = append(ids, wal.ID())
+	}
+	return ids
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultFsyncInterval период сброса журнала для FsyncInterval.
+const defaultFsyncInterval = time.Second
+
+var errNotPersistent = errors.New("repository is not persistent")
+
+// PersistOptions настройки хранения кошельков на диске.
+type PersistOptions struct {
+	// Dir каталог снимков и журнала изменений.
+	Dir string
+	// Fsync когда сбрасывать журнал на диск, по умолчанию FsyncAlways.
+	Fsync FsyncPolicy
+	// FsyncInterval период сброса для FsyncInterval, по умолчанию секунда.
+	FsyncInterval time.Duration
+}
+
+// OpenRepo хранилище в памяти, изменения которого переживают перезапуск.
+// Состояние восстанавливается из последнего снимка и записей журнала после него,
+// оборванная при сбое последняя запись журнала отбрасывается.
+// Каждая успешная транзакция дописывается в журнал до освобождения ее блокировок.
+func OpenRepo(opts PersistOptions) (*WalletRepository, error) {
+	switch opts.Fsync {
+	case "":
+		opts.Fsync = FsyncAlways
+	case FsyncAlways, FsyncInterval, FsyncNever:
+	default:
+		return nil, fmt.Errorf("unknown fsync policy %q", opts.Fsync)
+	}
+	if opts.FsyncInterval <= 0 {
+		opts.FsyncInterval = defaultFsyncInterval
+	}
+
+	err := os.MkdirAll(opts.Dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create dir %s: %w", opts.Dir, err)
+	}
+
+	repo := NewRepo()
+	seq, err := repo.recover(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.journal, err = openWAL(opts.Dir, seq, opts.Fsync, opts.FsyncInterval)
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// Close сбрасываем журнал изменений на диск и закрываем его.
+func (repo *WalletRepository) Close() error {
+	w, ok := repo.journal.(*wal)
+	if !ok {
+		return nil
+	}
+	return w.close()
+}
+
+// Snapshot записываем снимок хранилища и удаляем журнал, вошедший в снимок.
+// На время копирования хранилище блокируется целиком, запись файла идет без блокировки.
+func (repo *WalletRepository) Snapshot() error {
+	w, ok := repo.journal.(*wal)
+	if !ok {
+		return errNotPersistent
+	}
+
+	repo.muWallets.Lock()
+	snap := models.DatasetSnapshot{
+		CreatedAt: repo.now().UTC(),
+		Dataset:   *repo.dump(),
+	}
+	seq, err := w.rotate()
+	repo.muWallets.Unlock()
+	if err != nil {
+		return err
+	}
+
+	snap.Seq = seq
+	err = writeSnapshot(w.dir, &snap)
+	if err != nil {
+		return err
+	}
+	return compact(w.dir, seq)
+}
+
+// RunSnapshots записываем снимок каждые every, пока не отменен ctx.
+func (repo *WalletRepository) RunSnapshots(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := repo.Snapshot()
+			if err != nil {
+				log.Errorf("snapshot failed: %s", err.Error())
+			}
+		}
+	}
+}
+
+// recover загружаем последний снимок и применяем записи журнала после него.
+// Возвращает номер последней примененной записи.
+func (repo *WalletRepository) recover(dir string) (uint64, error) {
+	snapshots, err := listSegments(dir, snapshotPrefix, snapshotExt)
+	if err != nil {
+		return 0, err
+	}
+
+	var seq uint64
+	if len(snapshots) > 0 {
+		snap, err := readSnapshot(filepath.Join(dir, segmentName(snapshotPrefix, snapshots[len(snapshots)-1], snapshotExt)))
+		if err != nil {
+			return 0, err
+		}
+		err = repo.Restore(&snap.Dataset)
+		if err != nil {
+			return 0, fmt.Errorf("cannot restore snapshot %d: %w", snap.Seq, err)
+		}
+		seq = snap.Seq
+	}
+
+	segments, err := listSegments(dir, walPrefix, walExt)
+	if err != nil {
+		return 0, err
+	}
+	for i, first := range segments {
+		records, err := readSegment(filepath.Join(dir, segmentName(walPrefix, first, walExt)), i == len(segments)-1)
+		if err != nil {
+			return 0, err
+		}
+		for j := range records {
+			rec := &records[j]
+			if rec.Seq <= seq {
+				continue
+			}
+			if rec.Seq != seq+1 {
+				return 0, fmt.Errorf("wal record %d follows %d: %w", rec.Seq, seq, errCorruptedFrame)
+			}
+			err = repo.replay(rec)
+			if err != nil {
+				return 0, fmt.Errorf("cannot replay wal record %d: %w", rec.Seq, err)
+			}
+			seq = rec.Seq
+		}
+	}
+
+	// записи журнала меняют кошельки в обход транзакций, индексы списка строятся заново
+	repo.list = newListIndex(repo.wallets)
+	return seq, nil
+}
+
+// replay применяем изменения записи журнала: кошельки заменяются или добавляются, проводки дописываются.
+// Вызывающий должен владеть эксклюзивной блокировкой хранилища и muIndex
+// (или хранилище еще не доступно другим горутинам).
+func (repo *WalletRepository) replay(change *walRecord) error {
+	for _, dump := range change.Wallets {
+		restored := restoreWallet(dump)
+		if rec, ok := repo.index[dump.ID]; ok {
+			rec.wallet = restored.wallet
+		} else {
+			repo.wallets = append(repo.wallets, restored)
+			repo.index[dump.ID] = restored
+		}
+		for _, hold := range dump.Holds {
+			repo.holds[hold.ID] = dump.ID
+		}
+	}
+
+	operations := make(map[string][]string)
+	for _, entry := range change.Ledger {
+		rec, ok := repo.index[entry.WalletID]
+		if !ok {
+			return fmt.Errorf("operation %s, wallet %s: %w", entry.OperationID, entry.WalletID, errWalletNotFound)
+		}
+		rec.ledger = append(rec.ledger, entry)
+		operations[entry.OperationID] = append(operations[entry.OperationID], entry.WalletID)
+	}
+	for id, walletIDs := range operations {
+		walletIDs = append(walletIDs, repo.operations[id]...)
+		sort.Strings(walletIDs)
+		repo.operations[id] = uniqueSorted(walletIDs)
+	}
+	return nil
+}
+
+func readSnapshot(path string) (*models.DatasetSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read snapshot: %w", err)
+	}
+
+	snap := &models.DatasetSnapshot{}
+	err = unframe(data, snap)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// writeSnapshot пишем снимок через временный файл, поэтому файл снимка всегда целый.
+func writeSnapshot(dir string, snap *models.DatasetSnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("cannot encode snapshot: %w", err)
+	}
+
+	err = writeFrameFile(filepath.Join(dir, segmentName(snapshotPrefix, snap.Seq, snapshotExt)), data)
+	if err != nil {
+		return fmt.Errorf("cannot write snapshot: %w", err)
+	}
+	return nil
+}
+
+// writeFrameFile заменяем файл path одним кадром с data через временный файл,
+// поэтому файл всегда целый: старый или новый.
+func writeFrameFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	out := bufio.NewWriter(tmp)
+	_, err = out.Write(frame(data))
+	if err == nil {
+		err = out.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// compact удаляем сегменты журнала и снимки, которые заменяет снимок seq.
+// Сегменты с номером не больше seq содержат только записи, вошедшие в снимок.
+func compact(dir string, seq uint64) error {
+	segments, err := listSegments(dir, walPrefix, walExt)
+	if err != nil {
+		return err
+	}
+	for _, first := range segments {
+		if first > seq {
+			break
+		}
+		err = os.Remove(filepath.Join(dir, segmentName(walPrefix, first, walExt)))
+		if err != nil {
+			return fmt.Errorf("cannot remove wal segment: %w", err)
+		}
+	}
+
+	snapshots, err := listSegments(dir, snapshotPrefix, snapshotExt)
+	if err != nil {
+		return err
+	}
+	for _, old := range snapshots {
+		if old >= seq {
+			break
+		}
+		err = os.Remove(filepath.Join(dir, segmentName(snapshotPrefix, old, snapshotExt)))
+		if err != nil {
+			return fmt.Errorf("cannot remove snapshot: %w", err)
+		}
+	}
+	return syncDir(dir)
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// fillRepo кошельки, блокировка и операции через транзакции и методы хранилища.
+func fillRepo(t *testing.T, repo models.WalletRepository) (a, b models.Walleter) {
+	a = repo.Create("a", 100, true, "owner", map[string]string{"k": "v"})
+	err := repo.Transaction(context.Background(), nil, func(tx models.WalletRepository) error {
+		b = tx.Create("b", 0, true, "owner", nil)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = repo.Transaction(context.Background(), []string{a.ID(), b.ID()}, func(tx models.WalletRepository) error {
+		assert.Nil(t, tx.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](60)}))
+		assert.Nil(t, tx.UpdateByID(b.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](40)}))
+		_, err := tx.RecordOperation(models.OperationTransfer, []models.LedgerEntry{
+			{WalletID: a.ID(), Amount: -40, Counterparty: b.ID()},
+			{WalletID: b.ID(), Amount: 40, Counterparty: a.ID()},
+		})
+		return err
+	})
+	assert.Nil(t, err)
+
+	_, err = repo.CreateHold(a.ID(), 10, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Nil(t, repo.UpdateByID(b.ID(), models.WalletUpdate{Name: utils.Ptr("renamed")}))
+	return a, b
+}
+
+func assertSameRepo(t *testing.T, want, got models.WalletRepository) {
+	assert.Equal(t, want.All(), got.All())
+	wantLedger, _ := want.Ledger(models.LedgerFilter{})
+	gotLedger, _ := got.Ledger(models.LedgerFilter{})
+	assert.Equal(t, wantLedger, gotLedger)
+}
+
+func TestOpenRepo_recover(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := OpenRepo(PersistOptions{Dir: dir})
+	assert.Nil(t, err)
+	a, _ := fillRepo(t, repo)
+
+	// откаченная транзакция не попадает в журнал
+	errFail := errors.New("fail")
+	err = repo.Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+		assert.Nil(t, tx.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](1)}))
+		return errFail
+	})
+	assert.True(t, errors.Is(err, errFail))
+	assert.Nil(t, repo.Close())
+
+	reopened, err := OpenRepo(PersistOptions{Dir: dir})
+	assert.Nil(t, err)
+	defer reopened.Close()
+	assertSameRepo(t, repo, reopened)
+
+	got, err := reopened.ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(10), got.Held())
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := OpenRepo(PersistOptions{Dir: dir, Fsync: FsyncInterval, FsyncInterval: time.Millisecond})
+	assert.Nil(t, err)
+	a, b := fillRepo(t, repo)
+	assert.Nil(t, repo.Snapshot())
+	_, err = repo.RecordOperation(models.OperationCorrection, []models.LedgerEntry{{WalletID: a.ID(), Amount: 1}})
+	assert.Nil(t, err)
+	assert.Nil(t, repo.UpdateByID(b.ID(), models.WalletUpdate{Status: utils.Ptr(false)}))
+	assert.Nil(t, repo.Close())
+
+	// журнал до снимка удален
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, segmentName(snapshotPrefix, 5, snapshotExt)),
+		filepath.Join(dir, segmentName(walPrefix, 6, walExt)),
+	}, files)
+
+	reopened, err := OpenRepo(PersistOptions{Dir: dir})
+	assert.Nil(t, err)
+	defer reopened.Close()
+	assertSameRepo(t, repo, reopened)
+}
+
+func TestRecover_tornRecord(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := OpenRepo(PersistOptions{Dir: dir, Fsync: FsyncNever})
+	assert.Nil(t, err)
+	fillRepo(t, repo)
+	assert.Nil(t, repo.Close())
+
+	path := filepath.Join(dir, segmentName(walPrefix, 1, walExt))
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	assert.Nil(t, err)
+	_, err = file.WriteString(`0badc0de {"seq":6,"wall`)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	reopened, err := OpenRepo(PersistOptions{Dir: dir})
+	assert.Nil(t, err)
+	defer reopened.Close()
+	assertSameRepo(t, repo, reopened)
+
+	truncated, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, info.Size(), truncated.Size())
+}
+
+func TestRecover_corruptedRecord(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := OpenRepo(PersistOptions{Dir: dir})
+	assert.Nil(t, err)
+	fillRepo(t, repo)
+	assert.Nil(t, repo.Close())
+
+	// поврежденная запись в середине журнала не отбрасывается молча
+	path := filepath.Join(dir, segmentName(walPrefix, 1, walExt))
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	data[20] ^= 1
+	assert.Nil(t, os.WriteFile(path, data, 0o600))
+
+	_, err = OpenRepo(PersistOptions{Dir: dir})
+	assert.True(t, errors.Is(err, errCorruptedFrame))
+}
+
+func TestCommit_walFailure(t *testing.T) {
+	repo, err := OpenRepo(PersistOptions{Dir: t.TempDir()})
+	assert.Nil(t, err)
+	a := repo.Create("a", 100, true, "owner", nil)
+	assert.Nil(t, repo.journal.(*wal).file.Close())
+
+	err = repo.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](50)})
+	assert.NotNil(t, err)
+	got, err := repo.ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), got.Balance())
+}
@@ -0,0 +1,554 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultRedisPrefix     = "wallet:"
+	defaultRedisMaxRetries = 16
+)
+
+var errTxConflict = errors.New("transaction conflicts with concurrent transactions")
+
+// RedisOptions настройки хранилища кошельков в Redis.
+type RedisOptions struct {
+	// Prefix префикс ключей хранилища, по умолчанию "wallet:".
+	Prefix string
+	// MaxRetries сколько раз повторять транзакцию, прочитанные ключи которой изменил другой клиент,
+	// по умолчанию 16.
+	MaxRetries int
+}
+
+// RedisRepository хранилище кошельков в Redis, общее для нескольких экземпляров сервиса.
+//
+// Ключи хранилища(после префикса):
+//   - wallets - список идентификаторов кошельков в порядке создания;
+//   - wallet:{id} - состояние кошелька со всеми блокировками средств(JSON);
+//   - ledger:{id} - проводки кошелька в порядке записи(JSON);
+//   - holds - идентификаторы кошельков по идентификатору блокировки;
+//   - operations - идентификаторы кошельков по идентификатору операции(JSON);
+//   - list:{sort} - ключи listKey всех кошельков для поля сортировки sort(sorted set с нулевым весом,
+//     страница списка читается ZRANGEBYLEX с позиции курсора);
+//   - owner:{owner} - идентификаторы кошельков владельца(set).
+//
+// Транзакции оптимистичные: каждый прочитанный транзакцией ключ отслеживается WATCH,
+// изменения записываются одним MULTI/EXEC. Если другой клиент изменил прочитанные ключи,
+// EXEC не выполняется и транзакция повторяется заново, поэтому fn может выполниться несколько раз.
+type RedisRepository struct {
+	client     redis.UniversalClient
+	prefix     string
+	maxRetries int
+	// now текущее время для меток создания и изменения
+	now func() time.Time
+}
+
+// NewRedisRepo конструктор хранилища кошельков в Redis.
+func NewRedisRepo(client redis.UniversalClient, opts RedisOptions) *RedisRepository {
+	if opts.Prefix == "" {
+		opts.Prefix = defaultRedisPrefix
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = defaultRedisMaxRetries
+	}
+	return &RedisRepository{
+		client:     client,
+		prefix:     opts.Prefix,
+		maxRetries: opts.MaxRetries,
+		now:        time.Now,
+	}
+}
+
+// Transaction выполняем fn атомарно относительно транзакций всех экземпляров сервиса.
+// ids - кошельки, которые затрагивает транзакция, пустой ids - доступ ко всему хранилищу.
+// Если fn вернула ошибку, изменения транзакции не записываются.
+func (repo *RedisRepository) Transaction(ctx context.Context, ids []string, fn func(repo models.WalletRepository) error) error {
+	ctx, span := tracer.Start(ctx, "repository.RedisTransaction", trace.WithAttributes(
+		attribute.StringSlice("wallet.ids", ids),
+	))
+	defer span.End()
+
+	err := repo.apply(ctx, ids, func(tx *redisTx) error {
+		return fn(tx)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// apply выполняем fn в транзакции по кошелькам ids и записываем ее изменения.
+// Транзакция, прочитанные ключи которой изменились до записи, выполняется заново.
+func (repo *RedisRepository) apply(ctx context.Context, ids []string, fn func(tx *redisTx) error) error {
+	for i := 0; i < repo.maxRetries; i++ {
+		err := repo.client.Watch(ctx, func(conn *redis.Tx) error {
+			tx, err := repo.begin(ctx, conn, ids)
+			if err != nil {
+				return err
+			}
+			err = fn(tx)
+			if err != nil {
+				return err
+			}
+			return tx.commit()
+		})
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("%d attempts: %w", repo.maxRetries, errTxConflict)
+}
+
+// Create создание кошелька, версия нового кошелька 1.
+// Create не возвращает ошибку, поэтому ошибка Redis приводит к панике:
+// сервис создает кошельки внутри Transaction.
+func (repo *RedisRepository) Create(name string, balance float64, status bool, owner string, metadata map[string]string) models.Walleter {
+	var created models.Walleter
+	err := repo.apply(context.Background(), nil, func(tx *redisTx) error {
+		created = tx.Create(name, balance, status, owner, metadata)
+		return tx.err
+	})
+	if err != nil {
+		panic(fmt.Sprintf("cannot create wallet: %s", err.Error()))
+	}
+	return created
+}
+
+// ByID получаем кошелек по идентификатору.
+// При отсутствии кошелка вернется ошибка errWalletNotFound.
+func (repo *RedisRepository) ByID(id string) (models.Walleter, error) {
+	rec, err := repo.getWallet(context.Background(), repo.client, id)
+	if err != nil {
+		return nil, err
+	}
+	return rec.snapshot(repo.now().UTC()), nil
+}
+
+// All получение всего списка кошельков в порядке создания.
+// Ошибка Redis возвращает пустой список.
+func (repo *RedisRepository) All() []models.Walleter {
+	snapshots, _ := repo.snapshots(context.Background())
+
+	walletList := make([]models.Walleter, 0, len(snapshots))
+	for _, wal := range snapshots {
+		walletList = append(walletList, models.Walleter(wal))
+	}
+	return walletList
+}
+
+// List выборка кошельков по фильтру.
+// Кошельки владельца читаются по индексу владельца, остальные выборки читают страницу
+// из индекса поля сортировки(ZRANGEBYLEX) с позиции курсора, границы ключей задают фильтры по этому полю.
+func (repo *RedisRepository) List(filter models.WalletFilter) (*models.WalletPage, error) {
+	ctx := context.Background()
+	if filter.Owner != "" {
+		ids, err := repo.client.SMembers(ctx, repo.ownerKey(filter.Owner)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get wallets of owner %s: %w", filter.Owner, err)
+		}
+		records, err := repo.getWallets(ctx, repo.client, ids)
+		if err != nil {
+			return nil, err
+		}
+		now := repo.now().UTC()
+		snapshots := make([]*wallet, 0, len(records))
+		for _, rec := range records {
+			if rec != nil {
+				snapshots = append(snapshots, rec.snapshot(now))
+			}
+		}
+		return listWallets(snapshots, filter)
+	}
+
+	q, err := newListQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	return q.page(func(lo, hi string, n int) ([]listEntry, error) {
+		by := &redis.ZRangeBy{Min: "-", Max: "+", Count: int64(n)}
+		if lo != "" {
+			by.Min = "[" + lo
+		}
+		if hi != "" {
+			by.Max = "(" + hi
+		}
+		index := repo.listKey(q.filter.Sort)
+		var keys []string
+		if q.filter.Desc {
+			keys, err = repo.client.ZRevRangeByLex(ctx, index, by).Result()
+		} else {
+			keys, err = repo.client.ZRangeByLex(ctx, index, by).Result()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot get wallet list: %w", err)
+		}
+
+		ids := make([]string, 0, len(keys))
+		for _, key := range keys {
+			ids = append(ids, listKeyID(key))
+		}
+		records, err := repo.getWallets(ctx, repo.client, ids)
+		if err != nil {
+			return nil, err
+		}
+		now := repo.now().UTC()
+		entries := make([]listEntry, 0, len(keys))
+		for i, key := range keys {
+			entry := listEntry{key: key}
+			if records[i] != nil {
+				entry.wal = records[i].snapshot(now)
+			}
+			entries = append(entries, entry)
+		}
+		return entries, nil
+	})
+}
+
+// UpdateByID обновление данных кошелька, поля равные nil не обновляются.
+func (repo *RedisRepository) UpdateByID(id string, upd models.WalletUpdate) error {
+	return repo.apply(context.Background(), []string{id}, func(tx *redisTx) error {
+		return tx.UpdateByID(id, upd)
+	})
+}
+
+// CreateHold блокируем amount на кошельке до expiresAt.
+// Достаточность средств проверяет вызывающий.
+func (repo *RedisRepository) CreateHold(walletID string, amount float64, expiresAt time.Time) (hold models.Hold, err error) {
+	err = repo.apply(context.Background(), []string{walletID}, func(tx *redisTx) error {
+		hold, err = tx.CreateHold(walletID, amount, expiresAt)
+		return err
+	})
+	return hold, err
+}
+
+// HoldByID блокировка по идентификатору.
+// Снятые блокировки удаляются из индекса, для них вернется ошибка errHoldNotFound.
+func (repo *RedisRepository) HoldByID(id string) (models.Hold, error) {
+	ctx := context.Background()
+	walletID, err := repo.holdWallet(ctx, repo.client, id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	rec, err := repo.getWallet(ctx, repo.client, walletID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	return holdOf(rec, id, repo.now().UTC())
+}
+
+// CloseHold снимаем блокировку с кошелька.
+// Истекшая блокировка тоже снимается и возвращается со статусом HoldStatusExpired.
+func (repo *RedisRepository) CloseHold(id string) (hold models.Hold, err error) {
+	ctx := context.Background()
+	walletID, err := repo.holdWallet(ctx, repo.client, id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	err = repo.apply(ctx, []string{walletID}, func(tx *redisTx) error {
+		hold, err = tx.CloseHold(id)
+		return err
+	})
+	return hold, err
+}
+
+// ExpireHolds снимаем истекшие блокировки всех кошельков и удаляем их из индекса.
+// Каждый кошелек обрабатывается своей транзакцией, поэтому экземпляры сервиса могут снимать блокировки одновременно.
+// Возвращает число снятых блокировок.
+func (repo *RedisRepository) ExpireHolds() (int, error) {
+	ctx := context.Background()
+	holds, err := repo.client.HGetAll(ctx, repo.holdsKey()).Result()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get holds: %w", err)
+	}
+	walletIDs := make(map[string]struct{}, len(holds))
+	for _, walletID := range holds {
+		walletIDs[walletID] = struct{}{}
+	}
+
+	var expired int
+	for walletID := range walletIDs {
+		var closed []string
+		err = repo.apply(ctx, []string{walletID}, func(tx *redisTx) error {
+			closed = nil
+			rec, err := tx.record(walletID)
+			if errors.Is(err, errWalletNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			now := repo.now().UTC()
+			if !rec.hasExpiredHolds(now) {
+				return nil
+			}
+			before := rec.wallet.holds
+			rec.setHolds(nil, "", now)
+			closed = holdIDs(before, rec.wallet.holds)
+			tx.closedHolds = append(tx.closedHolds, closed...)
+			tx.change(rec)
+			return nil
+		})
+		if err != nil {
+			return expired, err
+		}
+		expired += len(closed)
+	}
+	return expired, nil
+}
+
+// RunHoldExpiry снимаем истекшие блокировки каждые every, пока не отменен ctx.
+func (repo *RedisRepository) RunHoldExpiry(ctx context.Context, every time.Duration) {
+	runHoldExpiry(ctx, every, repo.ExpireHolds)
+}
+
+// RecordOperation записываем проводки операции в журналы операций кошельков.
+func (repo *RedisRepository) RecordOperation(opType models.OperationType, entries []models.LedgerEntry) (id string, err error) {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.WalletID)
+	}
+
+	err = repo.apply(context.Background(), ids, func(tx *redisTx) error {
+		id, err = tx.RecordOperation(opType, entries)
+		return err
+	})
+	return id, err
+}
+
+// Ledger проводки по фильтру.
+func (repo *RedisRepository) Ledger(filter models.LedgerFilter) ([]models.LedgerEntry, error) {
+	ctx := context.Background()
+	var ids []string
+	var err error
+	switch {
+	case filter.WalletID != "":
+		ids = []string{filter.WalletID}
+	case filter.OperationID != "":
+		ids, err = repo.operationWallets(ctx, repo.client, filter.OperationID)
+	case filter.Reverses != "":
+		// сторнирование затрагивает только кошельки исходной операции
+		ids, err = repo.operationWallets(ctx, repo.client, filter.Reverses)
+	default:
+		ids, err = repo.walletIDs(ctx, repo.client)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []models.LedgerEntry
+	for _, id := range ids {
+		entries, err := repo.getLedger(ctx, repo.client, id)
+		if err != nil {
+			return nil, err
+		}
+		out = filterLedger(out, entries, filter)
+	}
+	sortLedger(out)
+	return out, nil
+}
+
+// Dump копия всех кошельков, их действующих блокировок и журнала операций.
+// Копия читается в транзакции, поэтому согласована.
+func (repo *RedisRepository) Dump() (ds *models.Dataset, err error) {
+	err = repo.apply(context.Background(), nil, func(tx *redisTx) error {
+		ds, err = tx.dump()
+		return err
+	})
+	return ds, err
+}
+
+// Restore загружаем кошельки выгрузки с их идентификаторами, блокировками и журналом.
+// Если хотя бы один кошелек, блокировка или операция уже есть в хранилище, ничего не загружается.
+func (repo *RedisRepository) Restore(ds *models.Dataset) error {
+	return repo.apply(context.Background(), nil, func(tx *redisTx) error {
+		return tx.restore(ds)
+	})
+}
+
+// snapshots копии всех кошельков в порядке создания.
+func (repo *RedisRepository) snapshots(ctx context.Context) ([]*wallet, error) {
+	ids, err := repo.walletIDs(ctx, repo.client)
+	if err != nil {
+		return nil, err
+	}
+	records, err := repo.getWallets(ctx, repo.client, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := repo.now().UTC()
+	snapshots := make([]*wallet, 0, len(records))
+	for _, rec := range records {
+		if rec != nil {
+			snapshots = append(snapshots, rec.snapshot(now))
+		}
+	}
+	return snapshots, nil
+}
+
+// getWallet читаем кошелек, errWalletNotFound если его нет.
+func (repo *RedisRepository) getWallet(ctx context.Context, cmd redis.Cmdable, id string) (*record, error) {
+	data, err := cmd.Get(ctx, repo.walletKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, errWalletNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get wallet %s: %w", id, err)
+	}
+	return decodeWallet(data)
+}
+
+// getWallets читаем кошельки ids, на месте отсутствующих кошельков nil.
+func (repo *RedisRepository) getWallets(ctx context.Context, cmd redis.Cmdable, ids []string) ([]*record, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, repo.walletKey(id))
+	}
+	values, err := cmd.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get wallets: %w", err)
+	}
+
+	records := make([]*record, len(values))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		records[i], err = decodeWallet([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// walletIDs идентификаторы всех кошельков в порядке создания.
+func (repo *RedisRepository) walletIDs(ctx context.Context, cmd redis.Cmdable) ([]string, error) {
+	ids, err := cmd.LRange(ctx, repo.walletsKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get wallet ids: %w", err)
+	}
+	return ids, nil
+}
+
+// getLedger проводки кошелька в порядке записи.
+func (repo *RedisRepository) getLedger(ctx context.Context, cmd redis.Cmdable, walletID string) ([]models.LedgerEntry, error) {
+	values, err := cmd.LRange(ctx, repo.ledgerKey(walletID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get ledger of wallet %s: %w", walletID, err)
+	}
+
+	entries := make([]models.LedgerEntry, len(values))
+	for i, value := range values {
+		err = json.Unmarshal([]byte(value), &entries[i])
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode ledger of wallet %s: %w", walletID, err)
+		}
+	}
+	return entries, nil
+}
+
+// holdWallet идентификатор кошелька блокировки, errHoldNotFound если блокировки нет.
+// Кошелек блокировки не меняется, поэтому ключ не отслеживается транзакциями.
+func (repo *RedisRepository) holdWallet(ctx context.Context, cmd redis.Cmdable, id string) (string, error) {
+	walletID, err := cmd.HGet(ctx, repo.holdsKey(), id).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", errHoldNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot get hold %s: %w", id, err)
+	}
+	return walletID, nil
+}
+
+// operationWallets кошельки операции, пустой список если операции нет.
+// Кошельки операции не меняются, поэтому ключ не отслеживается транзакциями.
+func (repo *RedisRepository) operationWallets(ctx context.Context, cmd redis.Cmdable, id string) ([]string, error) {
+	data, err := cmd.HGet(ctx, repo.operationsKey(), id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get operation %s: %w", id, err)
+	}
+
+	var ids []string
+	err = json.Unmarshal(data, &ids)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode operation %s: %w", id, err)
+	}
+	return ids, nil
+}
+
+func (repo *RedisRepository) walletsKey() string {
+	return repo.prefix + "wallets"
+}
+
+func (repo *RedisRepository) walletKey(id string) string {
+	return repo.prefix + "wallet:" + id
+}
+
+func (repo *RedisRepository) ledgerKey(walletID string) string {
+	return repo.prefix + "ledger:" + walletID
+}
+
+func (repo *RedisRepository) holdsKey() string {
+	return repo.prefix + "holds"
+}
+
+func (repo *RedisRepository) operationsKey() string {
+	return repo.prefix + "operations"
+}
+
+func (repo *RedisRepository) listKey(sort string) string {
+	return repo.prefix + "list:" + sort
+}
+
+func (repo *RedisRepository) ownerKey(owner string) string {
+	return repo.prefix + "owner:" + owner
+}
+
+// encodeWallet состояние кошелька для записи в Redis.
+func encodeWallet(wal *wallet) ([]byte, error) {
+	data, err := json.Marshal(walletDump(wal))
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode wallet %s: %w", wal.id, err)
+	}
+	return data, nil
+}
+
+func decodeWallet(data []byte) (*record, error) {
+	var dump models.WalletDump
+	err := json.Unmarshal(data, &dump)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode wallet: %w", err)
+	}
+
+	return restoreWallet(dump), nil
+}
+
+// filterLedger добавляем к out проводки entries, подходящие под фильтр.
+func filterLedger(out, entries []models.LedgerEntry, filter models.LedgerFilter) []models.LedgerEntry {
+	for _, entry := range entries {
+		if matchLedger(entry, filter) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
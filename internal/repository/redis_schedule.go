@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// scheduleClaimTTL сколько попытка перевода закреплена за экземпляром, получившим ее в Due.
+// Если экземпляр не записал результат попытки за это время, попытку получит другой экземпляр.
+const scheduleClaimTTL = 10 * time.Minute
+
+// RedisScheduleStore хранилище регулярных переводов в Redis, общее для нескольких экземпляров сервиса.
+//
+// Ключи хранилища(после префикса хранилища кошельков):
+//   - schedules - список идентификаторов переводов в порядке создания;
+//   - schedule:{id} - перевод(JSON);
+//   - schedule-claim:{id}:{next_run} - экземпляр получил попытку перевода, ключ истекает через scheduleClaimTTL.
+//
+// Изменения оптимистичные, как транзакции кошельков: при конкурентном изменении перевода fn выполняется заново.
+type RedisScheduleStore struct {
+	client     redis.UniversalClient
+	prefix     string
+	maxRetries int
+}
+
+// Schedules хранилище регулярных переводов в том же Redis с тем же префиксом.
+func (repo *RedisRepository) Schedules() *RedisScheduleStore {
+	return &RedisScheduleStore{
+		client:     repo.client,
+		prefix:     repo.prefix,
+		maxRetries: repo.maxRetries,
+	}
+}
+
+// Create сохраняем новый перевод под новым идентификатором.
+func (store *RedisScheduleStore) Create(schedule models.Schedule) (models.Schedule, error) {
+	ctx := context.Background()
+	schedule.ID = genNewID()
+	data, err := json.Marshal(&schedule)
+	if err != nil {
+		return models.Schedule{}, fmt.Errorf("cannot encode schedule: %w", err)
+	}
+
+	_, err = store.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, store.scheduleKey(schedule.ID), data, 0)
+		pipe.RPush(ctx, store.schedulesKey(), schedule.ID)
+		return nil
+	})
+	if err != nil {
+		return models.Schedule{}, fmt.Errorf("cannot save schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// ByID перевод по идентификатору.
+func (store *RedisScheduleStore) ByID(id string) (models.Schedule, error) {
+	return store.get(context.Background(), store.client, id)
+}
+
+// List переводы по фильтру в порядке создания.
+func (store *RedisScheduleStore) List(filter models.ScheduleFilter) ([]models.Schedule, error) {
+	schedules, err := store.all(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.Schedule, 0, len(schedules))
+	for i := range schedules {
+		if matchSchedule(&schedules[i], filter) {
+			out = append(out, schedules[i])
+		}
+	}
+	return out, nil
+}
+
+// Update изменяем копию перевода функцией fn и сохраняем ее, если fn не вернула ошибку.
+// Если перевод изменил другой экземпляр, fn выполняется заново над новым состоянием.
+func (store *RedisScheduleStore) Update(id string, fn func(schedule *models.Schedule) error) (models.Schedule, error) {
+	ctx := context.Background()
+	key := store.scheduleKey(id)
+	for i := 0; i < store.maxRetries; i++ {
+		var updated models.Schedule
+		err := store.client.Watch(ctx, func(conn *redis.Tx) error {
+			cur, err := store.get(ctx, conn, id)
+			if err != nil {
+				return err
+			}
+			updated = cur
+			err = fn(&updated)
+			if err != nil {
+				updated = cur
+				return err
+			}
+			updated.ID = id
+			data, err := json.Marshal(&updated)
+			if err != nil {
+				return fmt.Errorf("cannot encode schedule: %w", err)
+			}
+			_, err = conn.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, data, 0)
+				return nil
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return updated, err
+		}
+	}
+	return models.Schedule{}, fmt.Errorf("%d attempts: %w", store.maxRetries, errTxConflict)
+}
+
+// Due активные переводы, время попытки которых не позже now.
+// Каждая попытка закрепляется за одним экземпляром(SET NX), поэтому перевод не выполняется дважды.
+func (store *RedisScheduleStore) Due(now time.Time) ([]models.Schedule, error) {
+	ctx := context.Background()
+	schedules, err := store.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.Schedule, 0)
+	for i := range schedules {
+		if !isDue(&schedules[i], now) {
+			continue
+		}
+		claimed, err := store.client.SetNX(ctx, store.claimKey(&schedules[i]), 1, scheduleClaimTTL).Result()
+		if err != nil {
+			return out, fmt.Errorf("cannot claim schedule %s: %w", schedules[i].ID, err)
+		}
+		if claimed {
+			out = append(out, schedules[i])
+		}
+	}
+	return out, nil
+}
+
+// get читаем перевод, errScheduleNotFound если его нет.
+func (store *RedisScheduleStore) get(ctx context.Context, cmd redis.Cmdable, id string) (models.Schedule, error) {
+	data, err := cmd.Get(ctx, store.scheduleKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.Schedule{}, errScheduleNotFound
+	}
+	if err != nil {
+		return models.Schedule{}, fmt.Errorf("cannot get schedule %s: %w", id, err)
+	}
+
+	var schedule models.Schedule
+	err = json.Unmarshal(data, &schedule)
+	if err != nil {
+		return models.Schedule{}, fmt.Errorf("cannot decode schedule %s: %w", id, err)
+	}
+	return schedule, nil
+}
+
+// all все переводы в порядке создания.
+func (store *RedisScheduleStore) all(ctx context.Context) ([]models.Schedule, error) {
+	ids, err := store.client.LRange(ctx, store.schedulesKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get schedule ids: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, store.scheduleKey(id))
+	}
+	values, err := store.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get schedules: %w", err)
+	}
+
+	schedules := make([]models.Schedule, 0, len(values))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var schedule models.Schedule
+		err = json.Unmarshal([]byte(data), &schedule)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode schedule %s: %w", ids[i], err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func (store *RedisScheduleStore) schedulesKey() string {
+	return store.prefix + "schedules"
+}
+
+func (store *RedisScheduleStore) scheduleKey(id string) string {
+	return store.prefix + "schedule:" + id
+}
+
+// claimKey ключ попытки перевода: следующая попытка(перевод по расписанию или повтор) имеет другой NextRun.
+func (store *RedisScheduleStore) claimKey(schedule *models.Schedule) string {
+	return store.prefix + "schedule-claim:" + schedule.ID + ":" + strconv.FormatInt(schedule.NextRun.UnixNano(), 10)
+}
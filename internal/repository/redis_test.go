@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRedisRepos хранилища нескольких экземпляров сервиса над одним Redis.
+func newRedisRepos(t *testing.T, n int) []*RedisRepository {
+	server := miniredis.RunT(t)
+	repos := make([]*RedisRepository, n)
+	for i := range repos {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		repos[i] = NewRedisRepo(client, RedisOptions{MaxRetries: 100})
+	}
+	return repos
+}
+
+func TestRedisRepository(t *testing.T) {
+	repos := newRedisRepos(t, 2)
+	a, b := fillRepo(t, repos[0])
+
+	// второй экземпляр видит те же кошельки
+	assertSameRepo(t, repos[0], repos[1])
+	gotA, err := repos[1].ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(60), gotA.Balance())
+	assert.Equal(t, float64(10), gotA.Held())
+	assert.Equal(t, map[string]string{"k": "v"}, gotA.Metadata())
+	gotB, err := repos[1].ByID(b.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, "renamed", gotB.Name())
+	assert.Equal(t, float64(40), gotB.Balance())
+	assert.Equal(t, uint64(3), gotB.Version())
+
+	transfers, err := repos[1].Ledger(models.LedgerFilter{WalletID: b.ID()})
+	assert.Nil(t, err)
+	assert.Len(t, transfers, 1)
+	assert.Equal(t, float64(40), transfers[0].Balance)
+	operation, err := repos[1].Ledger(models.LedgerFilter{OperationID: transfers[0].OperationID})
+	assert.Nil(t, err)
+	assert.Len(t, operation, 2)
+
+	page, err := repos[1].List(models.WalletFilter{Sort: models.SortByName})
+	assert.Nil(t, err)
+	assert.Len(t, page.Wallets, 2)
+	assert.Equal(t, "a", page.Wallets[0].Name())
+}
+
+func TestRedisRepository_holds(t *testing.T) {
+	repo := newRedisRepos(t, 1)[0]
+	a := repo.Create("a", 100, true, "owner", nil)
+
+	hold, err := repo.CreateHold(a.ID(), 30, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	got, err := repo.HoldByID(hold.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, hold, got)
+	wal, err := repo.ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(70), wal.Available())
+
+	closed, err := repo.CloseHold(hold.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, hold.ID, closed.ID)
+	// снятая блокировка удаляется из индекса
+	_, err = repo.HoldByID(hold.ID)
+	assert.True(t, errors.Is(err, errHoldNotFound))
+	_, err = repo.CloseHold("unknown")
+	assert.True(t, errors.Is(err, errHoldNotFound))
+
+	expired, err := repo.CreateHold(a.ID(), 30, time.Now().Add(-time.Second))
+	assert.Nil(t, err)
+	got, err = repo.HoldByID(expired.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.HoldStatusExpired, got.Status)
+
+	swept, err := repo.ExpireHolds()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, swept)
+	_, err = repo.HoldByID(expired.ID)
+	assert.True(t, errors.Is(err, errHoldNotFound))
+	wal, err = repo.ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), wal.Held())
+}
+
+func TestRedisRepository_Transaction(t *testing.T) {
+	repo := newRedisRepos(t, 1)[0]
+	a := repo.Create("a", 0, true, "owner", nil)
+	b := repo.Create("b", 0, true, "owner", nil)
+
+	err := repo.Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+		_, err := tx.ByID(b.ID())
+		return err
+	})
+	assert.True(t, errors.Is(err, errWalletNotLocked))
+	err = repo.Transaction(context.Background(), []string{"unknown"}, func(tx models.WalletRepository) error {
+		_, err := tx.ByID("unknown")
+		return err
+	})
+	assert.True(t, errors.Is(err, errWalletNotFound))
+
+	// изменения транзакции с ошибкой не записываются
+	errFail := errors.New("fail")
+	err = repo.Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+		assert.Nil(t, tx.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](5)}))
+		_, err := tx.RecordOperation(models.OperationDeposit, []models.LedgerEntry{{WalletID: a.ID(), Amount: 5}})
+		assert.Nil(t, err)
+		tx.Create("c", 0, true, "owner", nil)
+		return errFail
+	})
+	assert.True(t, errors.Is(err, errFail))
+	got, err := repo.ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), got.Balance())
+	assert.Len(t, repo.All(), 2)
+	ledger, err := repo.Ledger(models.LedgerFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, ledger)
+
+	// транзакция видит свои изменения
+	err = repo.Transaction(context.Background(), nil, func(tx models.WalletRepository) error {
+		c := tx.Create("c", 7, true, "owner", nil)
+		assert.Len(t, tx.All(), 3)
+		entries, err := tx.Ledger(models.LedgerFilter{WalletID: c.ID()})
+		assert.Nil(t, err)
+		assert.Len(t, entries, 1)
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func TestRedisRepository_conflictRetry(t *testing.T) {
+	repos := newRedisRepos(t, 2)
+	a := repos[0].Create("a", 0, true, "owner", nil)
+
+	attempts := 0
+	err := repos[0].Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+		attempts++
+		wal, err := tx.ByID(a.ID())
+		if err != nil {
+			return err
+		}
+		if attempts == 1 {
+			// другой экземпляр меняет кошелек после чтения
+			assert.Nil(t, repos[1].UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](10)}))
+		}
+		return tx.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr(wal.Balance() + 1)})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+
+	got, err := repos[1].ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(11), got.Balance())
+	assert.Equal(t, uint64(3), got.Version())
+}
+
+func TestRedisRepository_concurrentTransfers(t *testing.T) {
+	repos := newRedisRepos(t, 3)
+	a := repos[0].Create("a", 100, true, "owner", nil)
+	b := repos[0].Create("b", 0, true, "owner", nil)
+
+	const transfers = 30
+	var wg sync.WaitGroup
+	for i := 0; i < transfers; i++ {
+		wg.Add(1)
+		go func(repo *RedisRepository) {
+			defer wg.Done()
+			err := repo.Transaction(context.Background(), []string{a.ID(), b.ID()}, func(tx models.WalletRepository) error {
+				from, err := tx.ByID(a.ID())
+				if err != nil {
+					return err
+				}
+				to, err := tx.ByID(b.ID())
+				if err != nil {
+					return err
+				}
+				err = tx.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr(from.Balance() - 1)})
+				if err != nil {
+					return err
+				}
+				err = tx.UpdateByID(b.ID(), models.WalletUpdate{Balance: utils.Ptr(to.Balance() + 1)})
+				if err != nil {
+					return err
+				}
+				_, err = tx.RecordOperation(models.OperationTransfer, []models.LedgerEntry{
+					{WalletID: a.ID(), Amount: -1, Counterparty: b.ID()},
+					{WalletID: b.ID(), Amount: 1, Counterparty: a.ID()},
+				})
+				return err
+			})
+			assert.Nil(t, err)
+		}(repos[i%len(repos)])
+	}
+	wg.Wait()
+
+	gotA, err := repos[1].ByID(a.ID())
+	assert.Nil(t, err)
+	gotB, err := repos[2].ByID(b.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100-transfers), gotA.Balance())
+	assert.Equal(t, float64(transfers), gotB.Balance())
+
+	// балансы проводок совпадают с балансами кошельков
+	ledger, err := repos[0].Ledger(models.LedgerFilter{WalletID: a.ID()})
+	assert.Nil(t, err)
+	assert.Len(t, ledger, transfers+1)
+	assert.Equal(t, gotA.Balance(), ledger[len(ledger)-1].Balance)
+}
+
+func TestRedisRepository_DumpRestore(t *testing.T) {
+	repos := newRedisRepos(t, 2)
+	fillRepo(t, repos[0])
+	ds, err := repos[0].Dump()
+	assert.Nil(t, err)
+	assert.Len(t, ds.Wallets, 2)
+
+	// второй экземпляр с другим префиксом - отдельное хранилище
+	restored := NewRedisRepo(repos[1].client, RedisOptions{Prefix: "restored:"})
+	assert.Nil(t, restored.Restore(ds))
+	assertSameRepo(t, repos[0], restored)
+
+	err = restored.Restore(ds)
+	assert.True(t, errors.Is(err, errAlreadyExists))
+}
+
+func TestRedisRepository_listIndex(t *testing.T) {
+	repos := newRedisRepos(t, 2)
+	var ids []string
+	for i, name := range []string{"delta", "alpha", "charlie", "bravo", "echo", "alpha"} {
+		ids = append(ids, repos[i%2].Create(name, float64(i*10), true, "owner", nil).ID())
+	}
+	assert.Nil(t, repos[0].UpdateByID(ids[0], models.WalletUpdate{Balance: utils.Ptr[float64](35)}))
+	assert.Nil(t, repos[1].UpdateByID(ids[4], models.WalletUpdate{Name: utils.Ptr("able")}))
+	err := repos[0].Transaction(context.Background(), []string{ids[1]}, func(tx models.WalletRepository) error {
+		assert.Nil(t, tx.UpdateByID(ids[1], models.WalletUpdate{Balance: utils.Ptr[float64](1000)}))
+		return errors.New("rollback")
+	})
+	assert.NotNil(t, err)
+
+	assertListIndex(t, repos[1])
+	page, err := repos[0].List(models.WalletFilter{Owner: "owner", Sort: models.SortByName, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "able", page.Wallets[0].Name())
+}
+
+func TestRedisScheduleStore(t *testing.T) {
+	repos := newRedisRepos(t, 2)
+	a, b := repos[0].Schedules(), repos[1].Schedules()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	created, err := a.Create(models.Schedule{Owner: "owner", Status: models.ScheduleStatusActive, NextRun: clock, OwnerRoles: []string{"customer"}})
+	assert.Nil(t, err)
+	_, err = a.Create(models.Schedule{Owner: "other", Status: models.ScheduleStatusPaused, NextRun: clock})
+	assert.Nil(t, err)
+
+	// второй экземпляр видит те же переводы
+	got, err := b.ByID(created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, created.OwnerRoles, got.OwnerRoles)
+	list, err := b.List(models.ScheduleFilter{Owner: "owner"})
+	assert.Nil(t, err)
+	assert.Len(t, list, 1)
+
+	// попытка перевода достается только одному экземпляру
+	due, err := a.Due(clock)
+	assert.Nil(t, err)
+	assert.Len(t, due, 1)
+	due, err = b.Due(clock)
+	assert.Nil(t, err)
+	assert.Len(t, due, 0)
+
+	_, err = a.Update(created.ID, func(schedule *models.Schedule) error {
+		schedule.NextRun = clock.Add(time.Hour)
+		return nil
+	})
+	assert.Nil(t, err)
+	due, err = b.Due(clock.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Len(t, due, 1)
+
+	_, err = b.ByID("unknown")
+	assert.True(t, errors.Is(err, errScheduleNotFound))
+}
@@ -0,0 +1,724 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTx представление хранилища внутри транзакции Redis.
+// Ключи читаются через соединение транзакции после WATCH, прочитанные кошельки кешируются,
+// изменения копятся в памяти и записываются в commit.
+// Эксклюзивная транзакция видит все кошельки, иначе доступны только объявленные и созданные.
+type redisTx struct {
+	repo      *RedisRepository
+	ctx       context.Context
+	conn      *redis.Tx
+	exclusive bool
+	// declared объявленные идентификаторы кошельков
+	declared map[string]struct{}
+	// watched отслеживаемые ключи
+	watched map[string]struct{}
+	// records прочитанные и созданные транзакцией кошельки, nil - кошелька нет в хранилище
+	records map[string]*record
+	// loaded прочитанное состояние кошельков, по нему из индексов списка удаляются старые ключи
+	loaded map[string]wallet
+	// order доступные транзакции кошельки: объявленные по возрастанию id, затем созданные
+	order []string
+	// created созданные транзакцией кошельки в порядке создания
+	created []string
+	// isNew созданные транзакцией кошельки
+	isNew map[string]struct{}
+	// changed измененные транзакцией кошельки, существовавшие до нее
+	changed map[string]struct{}
+	// ledgers прочитанные проводки кошельков
+	ledgers map[string][]models.LedgerEntry
+	// entries записанные транзакцией проводки в порядке записи
+	entries []models.LedgerEntry
+	// holds кошельки созданных транзакцией блокировок
+	holds map[string]string
+	// closedHolds снятые или отброшенные как истекшие блокировки, удаляются из индекса
+	closedHolds []string
+	// operations кошельки записанных транзакцией операций
+	operations map[string][]string
+	// err ошибка Redis в методе без возврата ошибки(Create), транзакция не записывается
+	err error
+}
+
+// begin начинаем транзакцию на соединении conn: объявленные кошельки отслеживаются и читаются сразу.
+func (repo *RedisRepository) begin(ctx context.Context, conn *redis.Tx, ids []string) (*redisTx, error) {
+	tx := &redisTx{
+		repo:       repo,
+		ctx:        ctx,
+		conn:       conn,
+		exclusive:  len(ids) == 0,
+		declared:   make(map[string]struct{}, len(ids)),
+		watched:    make(map[string]struct{}),
+		records:    make(map[string]*record, len(ids)),
+		loaded:     make(map[string]wallet, len(ids)),
+		isNew:      make(map[string]struct{}),
+		changed:    make(map[string]struct{}),
+		ledgers:    make(map[string][]models.LedgerEntry),
+		holds:      make(map[string]string),
+		operations: make(map[string][]string),
+	}
+	if tx.exclusive {
+		return tx, nil
+	}
+
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+	sorted = uniqueSorted(sorted)
+	for _, id := range sorted {
+		tx.declared[id] = struct{}{}
+	}
+	records, err := tx.loadWallets(sorted)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range sorted {
+		if records[i] != nil {
+			tx.order = append(tx.order, id)
+		}
+	}
+	return tx, nil
+}
+
+// commit записываем изменения транзакции одним MULTI/EXEC.
+// Если отслеживаемые ключи изменились, EXEC не выполняется и возвращается redis.TxFailedErr.
+// Транзакция без изменений тоже проверяет прочитанные ключи, поэтому ее чтения согласованы.
+func (tx *redisTx) commit() error {
+	if tx.err != nil {
+		return tx.err
+	}
+	// изменения есть только у транзакции, прочитавшей хотя бы один кошелек
+	if len(tx.watched) == 0 {
+		return nil
+	}
+
+	wallets := make(map[string][]byte, len(tx.created)+len(tx.changed))
+	for _, id := range append(tx.created, tx.changedIDs()...) {
+		data, err := encodeWallet(&tx.records[id].wallet)
+		if err != nil {
+			return err
+		}
+		wallets[id] = data
+	}
+	ledgers := make(map[string][]interface{})
+	for _, entry := range tx.entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("cannot encode ledger entry: %w", err)
+		}
+		ledgers[entry.WalletID] = append(ledgers[entry.WalletID], data)
+	}
+	lists := tx.listChanges()
+	operations := make(map[string]interface{}, len(tx.operations))
+	for id, walletIDs := range tx.operations {
+		data, err := json.Marshal(walletIDs)
+		if err != nil {
+			return fmt.Errorf("cannot encode operation %s: %w", id, err)
+		}
+		operations[id] = data
+	}
+
+	repo := tx.repo
+	_, err := tx.conn.TxPipelined(tx.ctx, func(pipe redis.Pipeliner) error {
+		pipe.Ping(tx.ctx)
+		for id, data := range wallets {
+			pipe.Set(tx.ctx, repo.walletKey(id), data, 0)
+		}
+		if len(tx.created) > 0 {
+			ids := make([]interface{}, 0, len(tx.created))
+			for _, id := range tx.created {
+				ids = append(ids, id)
+				pipe.SAdd(tx.ctx, repo.ownerKey(tx.records[id].wallet.owner), id)
+			}
+			pipe.RPush(tx.ctx, repo.walletsKey(), ids...)
+		}
+		for by, change := range lists {
+			if len(change.removed) > 0 {
+				pipe.ZRem(tx.ctx, repo.listKey(by), change.removed...)
+			}
+			if len(change.added) > 0 {
+				pipe.ZAdd(tx.ctx, repo.listKey(by), change.added...)
+			}
+		}
+		for walletID, entries := range ledgers {
+			pipe.RPush(tx.ctx, repo.ledgerKey(walletID), entries...)
+		}
+		if len(tx.holds) > 0 {
+			pipe.HSet(tx.ctx, repo.holdsKey(), tx.holds)
+		}
+		if len(tx.closedHolds) > 0 {
+			pipe.HDel(tx.ctx, repo.holdsKey(), tx.closedHolds...)
+		}
+		if len(operations) > 0 {
+			pipe.HSet(tx.ctx, repo.operationsKey(), operations)
+		}
+		return nil
+	})
+	return err
+}
+
+// listChange изменение индекса списка одного поля сортировки.
+type listChange struct {
+	removed []interface{}
+	added   []redis.Z
+}
+
+// listChanges ключи индексов списка созданных кошельков и измененных кошельков, ключ которых сдвинулся.
+// Индексы меняются вместе с кошельками, ключи которых отслеживаются, поэтому не расходятся с ними.
+func (tx *redisTx) listChanges() map[string]*listChange {
+	changes := make(map[string]*listChange, len(listSorts))
+	for _, by := range listSorts {
+		change := &listChange{}
+		for _, id := range tx.created {
+			change.added = append(change.added, redis.Z{Member: listKey(by, &tx.records[id].wallet)})
+		}
+		for _, id := range tx.changedIDs() {
+			before := tx.loaded[id]
+			oldKey, newKey := listKey(by, &before), listKey(by, &tx.records[id].wallet)
+			if oldKey != newKey {
+				change.removed = append(change.removed, oldKey)
+				change.added = append(change.added, redis.Z{Member: newKey})
+			}
+		}
+		changes[by] = change
+	}
+	return changes
+}
+
+// changedIDs измененные транзакцией существовавшие кошельки по возрастанию id.
+func (tx *redisTx) changedIDs() []string {
+	ids := make([]string, 0, len(tx.changed))
+	for id := range tx.changed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// watch отслеживаем ключи, которые транзакция еще не отслеживает.
+// Ключ отслеживается до чтения, поэтому изменение после чтения не даст записать транзакцию.
+func (tx *redisTx) watch(keys ...string) error {
+	var fresh []string
+	for _, key := range keys {
+		if _, ok := tx.watched[key]; !ok {
+			tx.watched[key] = struct{}{}
+			fresh = append(fresh, key)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	err := tx.conn.Watch(tx.ctx, fresh...).Err()
+	if err != nil {
+		return fmt.Errorf("cannot watch keys: %w", err)
+	}
+	return nil
+}
+
+// loadWallets читаем еще не прочитанные кошельки ids.
+// Возвращает кошельки в порядке ids, на месте отсутствующих nil.
+func (tx *redisTx) loadWallets(ids []string) ([]*record, error) {
+	var missing []string
+	for _, id := range ids {
+		if _, ok := tx.records[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		keys := make([]string, 0, len(missing))
+		for _, id := range missing {
+			keys = append(keys, tx.repo.walletKey(id))
+		}
+		err := tx.watch(keys...)
+		if err != nil {
+			return nil, err
+		}
+		loaded, err := tx.repo.getWallets(tx.ctx, tx.conn, missing)
+		if err != nil {
+			return nil, err
+		}
+		for i, id := range missing {
+			tx.records[id] = loaded[i]
+			if loaded[i] != nil {
+				tx.loaded[id] = loaded[i].wallet
+			}
+		}
+	}
+
+	records := make([]*record, 0, len(ids))
+	for _, id := range ids {
+		records = append(records, tx.records[id])
+	}
+	return records, nil
+}
+
+// allIDs идентификаторы всех кошельков, включая созданные транзакцией, в порядке создания.
+// Доступно только в эксклюзивной транзакции.
+func (tx *redisTx) allIDs() ([]string, error) {
+	err := tx.watch(tx.repo.walletsKey())
+	if err != nil {
+		return nil, err
+	}
+	ids, err := tx.repo.walletIDs(tx.ctx, tx.conn)
+	if err != nil {
+		return nil, err
+	}
+	return append(ids, tx.created...), nil
+}
+
+// record кошелек, доступный транзакции.
+func (tx *redisTx) record(id string) (*record, error) {
+	if !tx.exclusive {
+		if _, ok := tx.declared[id]; !ok {
+			return nil, fmt.Errorf("wallet %s: %w", id, errWalletNotLocked)
+		}
+	}
+
+	records, err := tx.loadWallets([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	if records[0] == nil {
+		return nil, errWalletNotFound
+	}
+	return records[0], nil
+}
+
+// snapshots копии кошельков ids, отсутствующие кошельки пропускаются.
+func (tx *redisTx) snapshots(ids []string) ([]*wallet, error) {
+	records, err := tx.loadWallets(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := tx.repo.now().UTC()
+	snapshots := make([]*wallet, 0, len(records))
+	for _, rec := range records {
+		if rec != nil {
+			snapshots = append(snapshots, rec.snapshot(now))
+		}
+	}
+	return snapshots, nil
+}
+
+// change отмечаем изменение существовавшего до транзакции кошелька.
+func (tx *redisTx) change(rec *record) {
+	if _, ok := tx.isNew[rec.wallet.id]; !ok {
+		tx.changed[rec.wallet.id] = struct{}{}
+	}
+}
+
+// Transaction вложенная транзакция выполняется в рамках текущей,
+// если все ids уже доступны текущей транзакции.
+func (tx *redisTx) Transaction(_ context.Context, ids []string, fn func(repo models.WalletRepository) error) error {
+	if !tx.exclusive {
+		if len(ids) == 0 {
+			return fmt.Errorf("exclusive transaction inside wallet transaction: %w", errWalletNotLocked)
+		}
+		for _, id := range ids {
+			if _, ok := tx.declared[id]; !ok {
+				return fmt.Errorf("wallet %s: %w", id, errWalletNotLocked)
+			}
+		}
+	}
+
+	return fn(tx)
+}
+
+// Create создание кошелька.
+// Идентификатор проверяется на уникальность: ключ нового кошелька отслеживается до записи.
+func (tx *redisTx) Create(name string, balance float64, status bool, owner string, metadata map[string]string) models.Walleter {
+	now := tx.repo.now().UTC()
+	id, err := tx.newID()
+	if err != nil && tx.err == nil {
+		tx.err = err
+	}
+
+	rec := newRecord(id, name, balance, status, owner, metadata, now)
+	tx.records[id] = rec
+	tx.created = append(tx.created, id)
+	tx.isNew[id] = struct{}{}
+	tx.order = append(tx.order, id)
+	if !tx.exclusive {
+		tx.declared[id] = struct{}{}
+	}
+	if balance != 0 {
+		tx.appendOperation(models.OperationOpening, []models.LedgerEntry{{WalletID: id, Amount: balance}})
+	}
+
+	return rec.snapshot(now)
+}
+
+// newID свободный идентификатор кошелька.
+func (tx *redisTx) newID() (string, error) {
+	for {
+		id := genNewID()
+		if _, ok := tx.records[id]; ok {
+			continue
+		}
+		records, err := tx.loadWallets([]string{id})
+		if err != nil {
+			return id, err
+		}
+		if records[0] == nil {
+			return id, nil
+		}
+	}
+}
+
+// ByID получаем кошелек по идентификатору.
+func (tx *redisTx) ByID(id string) (models.Walleter, error) {
+	rec, err := tx.record(id)
+	if err != nil {
+		return nil, err
+	}
+	return rec.snapshot(tx.repo.now().UTC()), nil
+}
+
+// All кошельки, доступные транзакции.
+// Для транзакции по кошелькам это только объявленные кошельки.
+// Ошибка Redis возвращает пустой список и не дает записать транзакцию.
+func (tx *redisTx) All() []models.Walleter {
+	ids := tx.order
+	if tx.exclusive {
+		var err error
+		ids, err = tx.allIDs()
+		if err != nil {
+			tx.err = err
+			return nil
+		}
+	}
+
+	snapshots, err := tx.snapshots(ids)
+	if err != nil {
+		tx.err = err
+		return nil
+	}
+	walletList := make([]models.Walleter, 0, len(snapshots))
+	for _, wal := range snapshots {
+		walletList = append(walletList, wal)
+	}
+	return walletList
+}
+
+// List выборка кошельков по фильтру, доступна только в эксклюзивной транзакции.
+// Транзакция видит свои несохраненные изменения, поэтому сортирует все кошельки, а не читает индексы.
+func (tx *redisTx) List(filter models.WalletFilter) (*models.WalletPage, error) {
+	if !tx.exclusive {
+		return nil, fmt.Errorf("list inside wallet transaction: %w", errWalletNotLocked)
+	}
+
+	ids, err := tx.allIDs()
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := tx.snapshots(ids)
+	if err != nil {
+		return nil, err
+	}
+	return listWallets(snapshots, filter)
+}
+
+// UpdateByID обновление данных кошелька.
+func (tx *redisTx) UpdateByID(id string, upd models.WalletUpdate) error {
+	rec, err := tx.record(id)
+	if err != nil {
+		return err
+	}
+	err = rec.update(upd, tx.repo.now().UTC())
+	if err != nil {
+		return err
+	}
+	tx.change(rec)
+	return nil
+}
+
+// CreateHold блокируем amount на кошельке, доступном транзакции.
+func (tx *redisTx) CreateHold(walletID string, amount float64, expiresAt time.Time) (models.Hold, error) {
+	rec, err := tx.record(walletID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	now := tx.repo.now().UTC()
+	hold := models.Hold{
+		ID:        genNewID(),
+		WalletID:  walletID,
+		Amount:    amount,
+		Status:    models.HoldStatusActive,
+		CreatedAt: now,
+		ExpiresAt: expiresAt.UTC(),
+	}
+	before := rec.wallet.holds
+	rec.setHolds(&hold, "", now)
+	tx.holds[hold.ID] = walletID
+	tx.closedHolds = append(tx.closedHolds, holdIDs(before, rec.wallet.holds)...)
+	tx.change(rec)
+	return hold, nil
+}
+
+// HoldByID блокировка кошелька, доступного транзакции.
+func (tx *redisTx) HoldByID(id string) (models.Hold, error) {
+	rec, err := tx.holdRecord(id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	return holdOf(rec, id, tx.repo.now().UTC())
+}
+
+// CloseHold снимаем блокировку с кошелька, доступного транзакции.
+func (tx *redisTx) CloseHold(id string) (models.Hold, error) {
+	rec, err := tx.holdRecord(id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	before := rec.wallet.holds
+	hold, err := closeHold(rec, id, tx.repo.now().UTC())
+	if err != nil {
+		return models.Hold{}, err
+	}
+	tx.closedHolds = append(tx.closedHolds, holdIDs(before, rec.wallet.holds)...)
+	tx.change(rec)
+	return hold, nil
+}
+
+// holdRecord кошелек блокировки, если он доступен транзакции.
+func (tx *redisTx) holdRecord(id string) (*record, error) {
+	walletID, ok := tx.holds[id]
+	if !ok {
+		var err error
+		walletID, err = tx.repo.holdWallet(tx.ctx, tx.conn, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tx.record(walletID)
+}
+
+// RecordOperation записываем проводки операции по кошелькам, доступным транзакции.
+func (tx *redisTx) RecordOperation(opType models.OperationType, entries []models.LedgerEntry) (string, error) {
+	for _, entry := range entries {
+		_, err := tx.record(entry.WalletID)
+		if err != nil {
+			return "", err
+		}
+	}
+	return tx.appendOperation(opType, entries), nil
+}
+
+// appendOperation добавляем проводки операции по прочитанным кошелькам.
+func (tx *redisTx) appendOperation(opType models.OperationType, entries []models.LedgerEntry) string {
+	id := genNewID()
+	balances := make(map[string]float64, len(entries))
+	for _, entry := range entries {
+		balances[entry.WalletID] = tx.records[entry.WalletID].wallet.balance
+	}
+	tx.entries = append(tx.entries, operationEntries(id, opType, entries, balances, tx.repo.now().UTC())...)
+
+	walletIDs := make([]string, 0, len(balances))
+	for walletID := range balances {
+		walletIDs = append(walletIDs, walletID)
+	}
+	sort.Strings(walletIDs)
+	tx.operations[id] = walletIDs
+	return id
+}
+
+// Ledger проводки по кошелькам, доступным транзакции.
+// Выборка по всем кошелькам доступна только в эксклюзивной транзакции.
+func (tx *redisTx) Ledger(filter models.LedgerFilter) ([]models.LedgerEntry, error) {
+	var ids []string
+	var err error
+	switch {
+	case filter.WalletID != "":
+		ids = []string{filter.WalletID}
+	case filter.OperationID != "" || filter.Reverses != "":
+		opID := filter.OperationID
+		if opID == "" {
+			opID = filter.Reverses
+		}
+		ids, err = tx.operationWallets(opID)
+	case tx.exclusive:
+		ids, err = tx.allIDs()
+	default:
+		return nil, fmt.Errorf("ledger of all wallets inside wallet transaction: %w", errWalletNotLocked)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []models.LedgerEntry
+	for _, id := range ids {
+		_, err = tx.record(id)
+		if errors.Is(err, errWalletNotFound) && tx.exclusive {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries, err := tx.ledger(id)
+		if err != nil {
+			return nil, err
+		}
+		out = filterLedger(out, entries, filter)
+	}
+	sortLedger(out)
+	return out, nil
+}
+
+// ledger проводки кошелька в порядке записи, включая записанные транзакцией.
+func (tx *redisTx) ledger(walletID string) ([]models.LedgerEntry, error) {
+	entries, ok := tx.ledgers[walletID]
+	_, isNew := tx.isNew[walletID]
+	if !ok && !isNew {
+		err := tx.watch(tx.repo.ledgerKey(walletID))
+		if err != nil {
+			return nil, err
+		}
+		entries, err = tx.repo.getLedger(tx.ctx, tx.conn, walletID)
+		if err != nil {
+			return nil, err
+		}
+		tx.ledgers[walletID] = entries
+	}
+
+	out := append([]models.LedgerEntry(nil), entries...)
+	for _, entry := range tx.entries {
+		if entry.WalletID == walletID {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+// operationWallets кошельки операции, включая записанные транзакцией операции.
+func (tx *redisTx) operationWallets(id string) ([]string, error) {
+	if ids, ok := tx.operations[id]; ok {
+		return ids, nil
+	}
+	return tx.repo.operationWallets(tx.ctx, tx.conn, id)
+}
+
+// dump копия всех кошельков хранилища и журнала операций.
+// Вызывается в эксклюзивной транзакции.
+func (tx *redisTx) dump() (*models.Dataset, error) {
+	ids, err := tx.allIDs()
+	if err != nil {
+		return nil, err
+	}
+	records, err := tx.loadWallets(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := tx.repo.now().UTC()
+	ds := &models.Dataset{
+		Wallets: make([]models.WalletDump, 0, len(records)),
+	}
+	for i, rec := range records {
+		if rec == nil {
+			continue
+		}
+		ds.Wallets = append(ds.Wallets, dumpWallet(rec, now))
+		entries, err := tx.ledger(ids[i])
+		if err != nil {
+			return nil, err
+		}
+		ds.Ledger = append(ds.Ledger, entries...)
+	}
+	sortLedger(ds.Ledger)
+	return ds, nil
+}
+
+// restore добавляем кошельки выгрузки как созданные транзакцией.
+// Вызывается в эксклюзивной транзакции.
+func (tx *redisTx) restore(ds *models.Dataset) error {
+	err := tx.checkRestore(ds)
+	if err != nil {
+		return err
+	}
+
+	for _, dump := range ds.Wallets {
+		rec := restoreWallet(dump)
+		tx.records[dump.ID] = rec
+		tx.created = append(tx.created, dump.ID)
+		tx.isNew[dump.ID] = struct{}{}
+		tx.order = append(tx.order, dump.ID)
+		for _, hold := range dump.Holds {
+			tx.holds[hold.ID] = dump.ID
+		}
+	}
+	for _, entry := range ds.Ledger {
+		tx.entries = append(tx.entries, entry)
+		tx.operations[entry.OperationID] = addSorted(tx.operations[entry.OperationID], entry.WalletID)
+	}
+	return nil
+}
+
+// checkRestore загрузка не затрагивает существующие кошельки, блокировки и операции.
+func (tx *redisTx) checkRestore(ds *models.Dataset) error {
+	ids := make(map[string]struct{}, len(ds.Wallets))
+	for _, dump := range ds.Wallets {
+		if dump.ID == "" {
+			return fmt.Errorf("wallet: %w", errEmptyDatasetID)
+		}
+		if _, ok := ids[dump.ID]; ok {
+			return fmt.Errorf("wallet %s is duplicated: %w", dump.ID, models.ErrInvalidArgument)
+		}
+		ids[dump.ID] = struct{}{}
+		for _, hold := range dump.Holds {
+			_, err := tx.repo.holdWallet(tx.ctx, tx.conn, hold.ID)
+			if err == nil {
+				return fmt.Errorf("hold %s: %w", hold.ID, errAlreadyExists)
+			}
+			if !errors.Is(err, errHoldNotFound) {
+				return err
+			}
+		}
+	}
+
+	existing := make([]string, 0, len(ids))
+	for _, dump := range ds.Wallets {
+		existing = append(existing, dump.ID)
+	}
+	records, err := tx.loadWallets(existing)
+	if err != nil {
+		return err
+	}
+	for i, rec := range records {
+		if rec != nil {
+			return fmt.Errorf("wallet %s: %w", existing[i], errAlreadyExists)
+		}
+	}
+
+	checked := make(map[string]struct{})
+	for _, entry := range ds.Ledger {
+		if _, ok := ids[entry.WalletID]; !ok {
+			return fmt.Errorf("operation %s, wallet %s: %w", entry.OperationID, entry.WalletID, errUnknownWallet)
+		}
+		if _, ok := checked[entry.OperationID]; ok {
+			continue
+		}
+		checked[entry.OperationID] = struct{}{}
+		walletIDs, err := tx.repo.operationWallets(tx.ctx, tx.conn, entry.OperationID)
+		if err != nil {
+			return err
+		}
+		if len(walletIDs) > 0 {
+			return fmt.Errorf("operation %s: %w", entry.OperationID, errAlreadyExists)
+		}
+	}
+	return nil
+}
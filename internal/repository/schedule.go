@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// schedulesFile файл регулярных переводов в каталоге хранения.
+const schedulesFile = "schedules.json"
+
+var errScheduleNotFound = fmt.Errorf("schedule %w", models.ErrNotFound)
+
+// ScheduleStore хранилище регулярных переводов в памяти.
+// С каталогом хранения каждое изменение сохраняется в файл до возврата, поэтому переводы переживают перезапуск.
+type ScheduleStore struct {
+	// muSchedules для конкурентного доступа к schedules и index
+	muSchedules *sync.RWMutex
+	// schedules переводы в порядке создания
+	schedules []*models.Schedule
+	// index переводы по идентификатору
+	index map[string]*models.Schedule
+	// path файл переводов, пустой - переводы только в памяти
+	path string
+}
+
+// NewScheduleStore конструктор хранилища регулярных переводов в памяти.
+func NewScheduleStore() *ScheduleStore {
+	return &ScheduleStore{
+		muSchedules: new(sync.RWMutex),
+		index:       make(map[string]*models.Schedule),
+	}
+}
+
+// OpenScheduleStore хранилище регулярных переводов, сохраняемых в каталоге dir.
+// Переводы загружаются из файла, сохраненного до перезапуска.
+func OpenScheduleStore(dir string) (*ScheduleStore, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create schedules dir: %w", err)
+	}
+
+	store := NewScheduleStore()
+	store.path = filepath.Join(dir, schedulesFile)
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schedules: %w", err)
+	}
+
+	var schedules []*models.Schedule
+	err = unframe(data, &schedules)
+	if err != nil {
+		return nil, fmt.Errorf("schedules %s: %w", store.path, err)
+	}
+	for _, schedule := range schedules {
+		store.schedules = append(store.schedules, schedule)
+		store.index[schedule.ID] = schedule
+	}
+	return store, nil
+}
+
+// Create сохраняем новый перевод под новым идентификатором.
+func (store *ScheduleStore) Create(schedule models.Schedule) (models.Schedule, error) {
+	store.muSchedules.Lock()
+	defer store.muSchedules.Unlock()
+
+	schedule.ID = genNewID()
+	saved := copySchedule(&schedule)
+	store.schedules = append(store.schedules, &saved)
+	store.index[saved.ID] = &saved
+
+	err := store.save()
+	if err != nil {
+		store.schedules = store.schedules[:len(store.schedules)-1]
+		delete(store.index, saved.ID)
+		return models.Schedule{}, err
+	}
+	return copySchedule(&saved), nil
+}
+
+// ByID перевод по идентификатору.
+func (store *ScheduleStore) ByID(id string) (models.Schedule, error) {
+	store.muSchedules.RLock()
+	defer store.muSchedules.RUnlock()
+
+	schedule, ok := store.index[id]
+	if !ok {
+		return models.Schedule{}, errScheduleNotFound
+	}
+	return copySchedule(schedule), nil
+}
+
+// List переводы по фильтру в порядке создания.
+func (store *ScheduleStore) List(filter models.ScheduleFilter) ([]models.Schedule, error) {
+	store.muSchedules.RLock()
+	defer store.muSchedules.RUnlock()
+
+	out := make([]models.Schedule, 0)
+	for _, schedule := range store.schedules {
+		if matchSchedule(schedule, filter) {
+			out = append(out, copySchedule(schedule))
+		}
+	}
+	return out, nil
+}
+
+// Update изменяем копию перевода функцией fn и сохраняем ее, если fn не вернула ошибку.
+func (store *ScheduleStore) Update(id string, fn func(schedule *models.Schedule) error) (models.Schedule, error) {
+	store.muSchedules.Lock()
+	defer store.muSchedules.Unlock()
+
+	schedule, ok := store.index[id]
+	if !ok {
+		return models.Schedule{}, errScheduleNotFound
+	}
+
+	updated := copySchedule(schedule)
+	err := fn(&updated)
+	if err != nil {
+		return copySchedule(schedule), err
+	}
+	updated.ID = schedule.ID
+	before := *schedule
+	*schedule = updated
+
+	err = store.save()
+	if err != nil {
+		*schedule = before
+		return copySchedule(schedule), err
+	}
+	return copySchedule(schedule), nil
+}
+
+// Due активные переводы, время попытки которых не позже now.
+func (store *ScheduleStore) Due(now time.Time) ([]models.Schedule, error) {
+	store.muSchedules.RLock()
+	defer store.muSchedules.RUnlock()
+
+	out := make([]models.Schedule, 0)
+	for _, schedule := range store.schedules {
+		if isDue(schedule, now) {
+			out = append(out, copySchedule(schedule))
+		}
+	}
+	return out, nil
+}
+
+// save сохраняем все переводы в файл, без файла ничего не делаем.
+// Вызывающий должен владеть эксклюзивной блокировкой хранилища.
+func (store *ScheduleStore) save() error {
+	if store.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(store.schedules)
+	if err != nil {
+		return fmt.Errorf("cannot encode schedules: %w", err)
+	}
+	err = writeFrameFile(store.path, data)
+	if err != nil {
+		return fmt.Errorf("cannot write schedules: %w", err)
+	}
+	return nil
+}
+
+// matchSchedule подходит ли перевод под фильтр.
+func matchSchedule(schedule *models.Schedule, filter models.ScheduleFilter) bool {
+	if filter.Owner != "" && schedule.Owner != filter.Owner {
+		return false
+	}
+	if filter.Status != "" && schedule.Status != filter.Status {
+		return false
+	}
+	return true
+}
+
+// isDue активен ли перевод и наступило ли к now время его попытки.
+func isDue(schedule *models.Schedule, now time.Time) bool {
+	return schedule.Status == models.ScheduleStatusActive && !schedule.NextRun.After(now)
+}
+
+// copySchedule копия перевода, которую вызывающий может менять.
+func copySchedule(schedule *models.Schedule) models.Schedule {
+	cp := *schedule
+	cp.OwnerRoles = append([]string(nil), schedule.OwnerRoles...)
+	return cp
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleStore_due(t *testing.T) {
+	store := NewScheduleStore()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	due, err := store.Create(models.Schedule{Owner: "a", Status: models.ScheduleStatusActive, NextRun: clock})
+	assert.Nil(t, err)
+	_, err = store.Create(models.Schedule{Owner: "a", Status: models.ScheduleStatusActive, NextRun: clock.Add(time.Minute)})
+	assert.Nil(t, err)
+	_, err = store.Create(models.Schedule{Owner: "b", Status: models.ScheduleStatusPaused, NextRun: clock})
+	assert.Nil(t, err)
+
+	got, err := store.Due(clock)
+	assert.Nil(t, err)
+	assert.Len(t, got, 1)
+	assert.Equal(t, due.ID, got[0].ID)
+
+	list, err := store.List(models.ScheduleFilter{Owner: "a"})
+	assert.Nil(t, err)
+	assert.Len(t, list, 2)
+	list, err = store.List(models.ScheduleFilter{Status: models.ScheduleStatusPaused})
+	assert.Nil(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestScheduleStore_updateRollback(t *testing.T) {
+	store := NewScheduleStore()
+	created, err := store.Create(models.Schedule{Status: models.ScheduleStatusActive})
+	assert.Nil(t, err)
+	expectErr := errors.New("test_err")
+
+	_, err = store.Update(created.ID, func(schedule *models.Schedule) error {
+		schedule.Status = models.ScheduleStatusCancelled
+		return expectErr
+	})
+	assert.True(t, errors.Is(err, expectErr))
+
+	got, err := store.ByID(created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.ScheduleStatusActive, got.Status)
+
+	_, err = store.Update("unknown", func(schedule *models.Schedule) error { return nil })
+	assert.True(t, errors.Is(err, errScheduleNotFound))
+}
+
+func TestOpenScheduleStore_reopen(t *testing.T) {
+	dir := t.TempDir()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store, err := OpenScheduleStore(dir)
+	assert.Nil(t, err)
+	first, err := store.Create(models.Schedule{Owner: "a", Status: models.ScheduleStatusActive, NextRun: clock, OwnerRoles: []string{"customer"}})
+	assert.Nil(t, err)
+	second, err := store.Create(models.Schedule{Owner: "b", Status: models.ScheduleStatusActive, NextRun: clock})
+	assert.Nil(t, err)
+	_, err = store.Update(second.ID, func(schedule *models.Schedule) error {
+		schedule.Status = models.ScheduleStatusPaused
+		return nil
+	})
+	assert.Nil(t, err)
+
+	reopened, err := OpenScheduleStore(dir)
+	assert.Nil(t, err)
+	list, err := reopened.List(models.ScheduleFilter{})
+	assert.Nil(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, first, list[0])
+	assert.Equal(t, models.ScheduleStatusPaused, list[1].Status)
+
+	due, err := reopened.Due(clock)
+	assert.Nil(t, err)
+	assert.Len(t, due, 1)
+	assert.Equal(t, first.ID, due[0].ID)
+}
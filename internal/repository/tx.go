@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// transaction представление хранилища внутри Transaction.
+// Эксклюзивная транзакция видит все кошельки, иначе доступны только объявленные.
+type transaction struct {
+	repo      *WalletRepository
+	exclusive bool
+	// declared объявленные идентификаторы кошельков
+	declared map[string]struct{}
+	// held заблокированные транзакцией кошельки
+	held map[string]*record
+	// order порядок взятия блокировок, освобождаются в обратном порядке
+	order []*record
+	// undo исходное состояние измененных транзакцией кошельков
+	undo map[*record]wallet
+	// created созданные транзакцией кошельки
+	created []*record
+	// createdHolds созданные транзакцией блокировки средств
+	createdHolds []string
+	// ledgerLen исходная длина журнала операций измененных кошельков
+	ledgerLen map[*record]int
+	// createdOps записанные транзакцией операции
+	createdOps []string
+}
+
+// rollback откатываем изменения транзакции: восстанавливаем измененные и удаляем созданные кошельки.
+// Вызывается до освобождения блокировок.
+func (tx *transaction) rollback() {
+	for rec, orig := range tx.undo {
+		rec.wallet = orig
+	}
+	for rec, n := range tx.ledgerLen {
+		rec.ledger = rec.ledger[:n]
+	}
+	if len(tx.created) == 0 && len(tx.createdHolds) == 0 && len(tx.createdOps) == 0 {
+		return
+	}
+
+	removed := make(map[*record]struct{}, len(tx.created))
+	for _, rec := range tx.created {
+		removed[rec] = struct{}{}
+	}
+
+	tx.repo.muIndex.Lock()
+	defer tx.repo.muIndex.Unlock()
+
+	for _, id := range tx.createdHolds {
+		delete(tx.repo.holds, id)
+	}
+	for _, id := range tx.createdOps {
+		delete(tx.repo.operations, id)
+	}
+	if len(removed) == 0 {
+		return
+	}
+	wallets := tx.repo.wallets[:0]
+	for _, rec := range tx.repo.wallets {
+		if _, ok := removed[rec]; ok {
+			delete(tx.repo.index, rec.wallet.id)
+			continue
+		}
+		wallets = append(wallets, rec)
+	}
+	tx.repo.wallets = wallets
+}
+
+// pruneHolds удаляем из индекса блокировки, которые транзакция сняла или отбросила как истекшие.
+// Вызывается после фиксации и до освобождения блокировок.
+func (tx *transaction) pruneHolds() {
+	var closed []string
+	for rec, orig := range tx.undo {
+		closed = append(closed, holdIDs(orig.holds, rec.wallet.holds)...)
+	}
+	if len(closed) == 0 {
+		return
+	}
+
+	tx.repo.muIndex.Lock()
+	defer tx.repo.muIndex.Unlock()
+	for _, id := range closed {
+		delete(tx.repo.holds, id)
+	}
+}
+
+// reindex переставляем в индексах списка измененные и добавляем созданные транзакцией кошельки.
+// Вызывается после фиксации и до освобождения блокировок.
+func (tx *transaction) reindex() {
+	if len(tx.undo) == 0 && len(tx.created) == 0 {
+		return
+	}
+	created := make(map[*record]struct{}, len(tx.created))
+	for _, rec := range tx.created {
+		created[rec] = struct{}{}
+	}
+
+	tx.repo.muIndex.Lock()
+	defer tx.repo.muIndex.Unlock()
+	for rec, orig := range tx.undo {
+		if _, ok := created[rec]; !ok {
+			orig := orig
+			tx.repo.list.move(rec, &orig)
+		}
+	}
+	for _, rec := range tx.created {
+		tx.repo.list.add(rec)
+	}
+}
+
+// commit фиксируем изменения транзакции в журнале хранилища.
+// Вызывается до освобождения блокировок, поэтому изменения одного кошелька
+// попадают в журнал в порядке выполнения транзакций.
+func (tx *transaction) commit() error {
+	if tx.repo.journal == nil {
+		return nil
+	}
+	return tx.repo.journal.commit(tx)
+}
+
+// changes состояние измененных и созданных транзакцией кошельков и записанные ею проводки.
+func (tx *transaction) changes() *walRecord {
+	now := tx.repo.now().UTC()
+	change := &walRecord{}
+	for _, rec := range tx.changed() {
+		change.Wallets = append(change.Wallets, dumpWallet(rec, now))
+		if n, ok := tx.ledgerLen[rec]; ok {
+			change.Ledger = append(change.Ledger, rec.ledger[n:]...)
+		}
+	}
+	// созданные кошельки в порядке создания, чтобы при восстановлении сохранился порядок хранилища
+	for _, rec := range tx.created {
+		change.Wallets = append(change.Wallets, dumpWallet(rec, now))
+		change.Ledger = append(change.Ledger, rec.ledger...)
+	}
+	sortLedger(change.Ledger)
+	return change
+}
+
+// changed существовавшие до транзакции кошельки, которые она изменила, по возрастанию id.
+func (tx *transaction) changed() []*record {
+	created := make(map[*record]struct{}, len(tx.created))
+	for _, rec := range tx.created {
+		created[rec] = struct{}{}
+	}
+
+	changed := make([]*record, 0, len(tx.undo)+len(tx.ledgerLen))
+	for rec := range tx.undo {
+		if _, ok := created[rec]; !ok {
+			changed = append(changed, rec)
+		}
+	}
+	for rec := range tx.ledgerLen {
+		_, saved := tx.undo[rec]
+		_, isNew := created[rec]
+		if !saved && !isNew {
+			changed = append(changed, rec)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].wallet.id < changed[j].wallet.id
+	})
+	return changed
+}
+
+// release освобождаем блокировки транзакции.
+func (tx *transaction) release() {
+	if tx.exclusive {
+		tx.repo.muWallets.Unlock()
+		return
+	}
+
+	for i := len(tx.order) - 1; i >= 0; i-- {
+		tx.order[i].mu.Unlock()
+	}
+	tx.repo.muWallets.RUnlock()
+}
+
+// record кошелек, доступный транзакции.
+func (tx *transaction) record(id string) (*record, error) {
+	if tx.exclusive {
+		rec := tx.repo.lookup(id)
+		if rec == nil {
+			return nil, errWalletNotFound
+		}
+		return rec, nil
+	}
+
+	if rec, ok := tx.held[id]; ok {
+		return rec, nil
+	}
+	if _, ok := tx.declared[id]; ok {
+		return nil, errWalletNotFound
+	}
+	return nil, fmt.Errorf("wallet %s: %w", id, errWalletNotLocked)
+}
+
+// Transaction вложенная транзакция выполняется в рамках текущей,
+// если все ids уже доступны текущей транзакции.
+func (tx *transaction) Transaction(_ context.Context, ids []string, fn func(repo models.WalletRepository) error) error {
+	if !tx.exclusive {
+		if len(ids) == 0 {
+			return fmt.Errorf("exclusive transaction inside wallet transaction: %w", errWalletNotLocked)
+		}
+		for _, id := range ids {
+			if _, ok := tx.declared[id]; !ok {
+				return fmt.Errorf("wallet %s: %w", id, errWalletNotLocked)
+			}
+		}
+	}
+
+	return fn(tx)
+}
+
+// Create создание кошелька.
+// Новый кошелек блокируется текущей транзакцией до ее окончания.
+func (tx *transaction) Create(name string, balance float64, status bool, owner string, metadata map[string]string) models.Walleter {
+	rec := tx.repo.insert(name, balance, status, owner, metadata)
+	tx.created = append(tx.created, rec)
+	if !tx.exclusive {
+		rec.mu.Lock()
+		tx.declared[rec.wallet.id] = struct{}{}
+		tx.held[rec.wallet.id] = rec
+		tx.order = append(tx.order, rec)
+	}
+	if id := tx.repo.open(rec); id != "" {
+		tx.createdOps = append(tx.createdOps, id)
+	}
+
+	return rec.snapshot(tx.repo.now().UTC())
+}
+
+// ByID получаем кошелек по идентификатору.
+func (tx *transaction) ByID(id string) (models.Walleter, error) {
+	rec, err := tx.record(id)
+	if err != nil {
+		return nil, err
+	}
+	return rec.snapshot(tx.repo.now().UTC()), nil
+}
+
+// All кошельки, доступные транзакции.
+// Для транзакции по кошелькам это только объявленные кошельки.
+func (tx *transaction) All() []models.Walleter {
+	records := tx.order
+	if tx.exclusive {
+		tx.repo.muIndex.RLock()
+		records = append([]*record(nil), tx.repo.wallets...)
+		tx.repo.muIndex.RUnlock()
+	}
+
+	now := tx.repo.now().UTC()
+	walletList := make([]models.Walleter, 0, len(records))
+	for _, rec := range records {
+		walletList = append(walletList, rec.snapshot(now))
+	}
+	return walletList
+}
+
+// List выборка кошельков по фильтру, доступна только в эксклюзивной транзакции.
+func (tx *transaction) List(filter models.WalletFilter) (*models.WalletPage, error) {
+	if !tx.exclusive {
+		return nil, fmt.Errorf("list inside wallet transaction: %w", errWalletNotLocked)
+	}
+
+	tx.repo.muIndex.RLock()
+	now := tx.repo.now().UTC()
+	snapshots := make([]*wallet, 0, len(tx.repo.wallets))
+	for _, rec := range tx.repo.wallets {
+		snapshots = append(snapshots, rec.snapshot(now))
+	}
+	tx.repo.muIndex.RUnlock()
+
+	return listWallets(snapshots, filter)
+}
+
+// UpdateByID обновление данных кошелька.
+func (tx *transaction) UpdateByID(id string, upd models.WalletUpdate) error {
+	rec, err := tx.record(id)
+	if err != nil {
+		return err
+	}
+	tx.save(rec)
+	return rec.update(upd, tx.repo.now().UTC())
+}
+
+// save запоминаем исходное состояние кошелька перед первым изменением в транзакции.
+func (tx *transaction) save(rec *record) {
+	if _, ok := tx.undo[rec]; !ok {
+		tx.undo[rec] = rec.wallet
+	}
+}
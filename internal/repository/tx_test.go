@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+	"github.com/Nizom98/wallet/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_undeclaredWallet(t *testing.T) {
+	repo := NewRepo()
+	a := repo.Create("a", 0, true, "owner", nil)
+	b := repo.Create("b", 0, true, "owner", nil)
+
+	err := repo.Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+		_, err := tx.ByID(a.ID())
+		assert.Nil(t, err)
+
+		_, err = tx.ByID(b.ID())
+		return err
+	})
+	assert.True(t, errors.Is(err, errWalletNotLocked))
+
+	err = repo.Transaction(context.Background(), []string{"unknown"}, func(tx models.WalletRepository) error {
+		_, err := tx.ByID("unknown")
+		return err
+	})
+	assert.True(t, errors.Is(err, errWalletNotFound))
+}
+
+func TestTransaction_disjointWalletsInParallel(t *testing.T) {
+	repo := NewRepo()
+	a := repo.Create("a", 0, true, "owner", nil)
+	c := repo.Create("c", 0, true, "owner", nil)
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = repo.Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+
+	done := make(chan struct{})
+	go func() {
+		_ = repo.Transaction(context.Background(), []string{c.ID()}, func(tx models.WalletRepository) error {
+			return tx.UpdateByID(c.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](1)})
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("transaction on unrelated wallet is blocked")
+	}
+	close(release)
+}
+
+func TestTransaction_crossTransfersNoDeadlock(t *testing.T) {
+	repo := NewRepo()
+	a := repo.Create("a", 1000, true, "owner", nil)
+	b := repo.Create("b", 1000, true, "owner", nil)
+
+	transfer := func(fromID, toID string) {
+		_ = repo.Transaction(context.Background(), []string{fromID, toID}, func(tx models.WalletRepository) error {
+			from, _ := tx.ByID(fromID)
+			to, _ := tx.ByID(toID)
+			_ = tx.UpdateByID(fromID, models.WalletUpdate{Balance: utils.Ptr[float64](from.Balance() - 1)})
+			return tx.UpdateByID(toID, models.WalletUpdate{Balance: utils.Ptr[float64](to.Balance() + 1)})
+		})
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); transfer(a.ID(), b.ID()) }()
+		go func() { defer wg.Done(); transfer(b.ID(), a.ID()) }()
+	}
+	wg.Wait()
+
+	gotA, _ := repo.ByID(a.ID())
+	gotB, _ := repo.ByID(b.ID())
+	assert.Equal(t, float64(2000), gotA.Balance()+gotB.Balance())
+	assert.Equal(t, float64(1000), gotA.Balance())
+}
+
+// BenchmarkTransaction_perWallet депозиты в разные кошельки с блокировкой только своего кошелька.
+// Запуск с -cpu 1,2,4,8 показывает рост пропускной способности с числом ядер.
+func BenchmarkTransaction_perWallet(b *testing.B) {
+	benchmarkTransactions(b, false)
+}
+
+// BenchmarkTransaction_exclusive те же депозиты под эксклюзивной блокировкой хранилища.
+func BenchmarkTransaction_exclusive(b *testing.B) {
+	benchmarkTransactions(b, true)
+}
+
+func benchmarkTransactions(b *testing.B, exclusive bool) {
+	repo := NewRepo()
+	ids := make([]string, 1024)
+	for i := range ids {
+		ids[i] = repo.Create("bench", 0, true, "owner", nil).ID()
+	}
+
+	payload := make([]byte, 256)
+	var mu sync.Mutex
+	next := 0
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		mu.Lock()
+		id := ids[next%len(ids)]
+		next++
+		mu.Unlock()
+
+		var lockIDs []string
+		if !exclusive {
+			lockIDs = []string{id}
+		}
+		for pb.Next() {
+			_ = repo.Transaction(context.Background(), lockIDs, func(tx models.WalletRepository) error {
+				wal, err := tx.ByID(id)
+				if err != nil {
+					return err
+				}
+				// имитация проверок бизнес логики внутри транзакции
+				sum := sha256.Sum256(payload)
+				for i := 0; i < 16; i++ {
+					sum = sha256.Sum256(sum[:])
+				}
+				return tx.UpdateByID(id, models.WalletUpdate{Balance: utils.Ptr[float64](wal.Balance() + 1)})
+			})
+		}
+	})
+}
+
+func TestTransaction_rollback(t *testing.T) {
+	repo := NewRepo()
+	a := repo.Create("a", 100, true, "owner", nil)
+	expectErr := errors.New("test_err")
+
+	var created models.Walleter
+	err := repo.Transaction(context.Background(), []string{a.ID()}, func(tx models.WalletRepository) error {
+		created = tx.Create("new", 0, true, "owner", nil)
+		err := tx.UpdateByID(a.ID(), models.WalletUpdate{Balance: utils.Ptr[float64](0)})
+		assert.Nil(t, err)
+		return expectErr
+	})
+	assert.True(t, errors.Is(err, expectErr))
+
+	got, err := repo.ByID(a.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), got.Balance())
+	assert.Equal(t, uint64(1), got.Version())
+
+	_, err = repo.ByID(created.ID())
+	assert.True(t, errors.Is(err, errWalletNotFound))
+	assert.Len(t, repo.All(), 1)
+}
@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// FsyncPolicy когда журнал изменений сбрасывается на диск.
+type FsyncPolicy string
+
+const (
+	// FsyncAlways транзакция выполнена только после сброса журнала на диск.
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval сброс раз в интервал, при сбое питания теряются изменения последнего интервала.
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever сброс на усмотрение ОС.
+	FsyncNever FsyncPolicy = "never"
+)
+
+const (
+	walPrefix      = "wal-"
+	walExt         = ".log"
+	snapshotPrefix = "snapshot-"
+	snapshotExt    = ".snap"
+	// frameHeaderSize crc32 в hex и пробел перед данными строки
+	frameHeaderSize = 9
+)
+
+var errCorruptedFrame = errors.New("corrupted frame")
+
+// walRecord изменения одной транзакции: состояние измененных и созданных кошельков после нее
+// и записанные ею проводки.
+type walRecord struct {
+	Seq     uint64               `json:"seq"`
+	Wallets []models.WalletDump  `json:"wallets,omitempty"`
+	Ledger  []models.LedgerEntry `json:"ledger,omitempty"`
+}
+
+// wal журнал изменений(write-ahead log) хранилища: файлы-сегменты из строк walRecord.
+// Сегмент называется по номеру своей первой записи, новый сегмент начинается после каждого снимка.
+type wal struct {
+	// mu для конкурентного доступа к file, seq, dirty и err
+	mu     sync.Mutex
+	dir    string
+	policy FsyncPolicy
+	file   *os.File
+	// first номер первой записи текущего сегмента
+	first uint64
+	// seq номер последней записи
+	seq uint64
+	// dirty есть записи, не сброшенные на диск
+	dirty bool
+	// err ошибка фонового сброса, после нее журнал не принимает записей
+	err  error
+	stop chan struct{}
+	done chan struct{}
+}
+
+// openWAL открываем сегмент для записей после seq.
+// Для FsyncInterval журнал сбрасывается на диск каждые interval.
+func openWAL(dir string, seq uint64, policy FsyncPolicy, interval time.Duration) (*wal, error) {
+	w := &wal{
+		dir:    dir,
+		policy: policy,
+		seq:    seq,
+	}
+	err := w.openSegment()
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == FsyncInterval {
+		w.stop = make(chan struct{})
+		w.done = make(chan struct{})
+		go w.syncEvery(interval)
+	}
+	return w, nil
+}
+
+// commit дописываем изменения транзакции.
+func (w *wal) commit(tx *transaction) error {
+	change := tx.changes()
+	if len(change.Wallets) == 0 && len(change.Ledger) == 0 {
+		return nil
+	}
+	return w.append(change)
+}
+
+// append дописываем запись с очередным номером.
+func (w *wal) append(rec *walRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return fmt.Errorf("wal is failed: %w", w.err)
+	}
+
+	rec.Seq = w.seq + 1
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("cannot encode wal record: %w", err)
+	}
+	_, err = w.file.Write(frame(data))
+	if err != nil {
+		// часть строки могла попасть в файл, продолжать сегмент нельзя
+		w.err = err
+		return fmt.Errorf("cannot write wal: %w", err)
+	}
+	if w.policy == FsyncAlways {
+		err = w.file.Sync()
+		if err != nil {
+			w.err = err
+			return fmt.Errorf("cannot sync wal: %w", err)
+		}
+	} else {
+		w.dirty = true
+	}
+
+	w.seq = rec.Seq
+	return nil
+}
+
+// rotate начинаем новый сегмент для записей после последней.
+// Вызывающий должен владеть эксклюзивной блокировкой хранилища, чтобы номер не менялся.
+func (w *wal) rotate() (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return 0, fmt.Errorf("wal is failed: %w", w.err)
+	}
+	if w.first == w.seq+1 {
+		return w.seq, nil
+	}
+
+	err := w.closeSegment()
+	if err != nil {
+		w.err = err
+		return 0, err
+	}
+	err = w.openSegment()
+	if err != nil {
+		w.err = err
+		return 0, err
+	}
+	return w.seq, nil
+}
+
+// close сбрасываем журнал на диск и закрываем сегмент.
+func (w *wal) close() error {
+	if w.stop != nil {
+		close(w.stop)
+		<-w.done
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.closeSegment()
+}
+
+// syncEvery фоновый сброс журнала для FsyncInterval.
+func (w *wal) syncEvery(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			if w.dirty && w.err == nil {
+				w.err = w.file.Sync()
+				w.dirty = false
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// openSegment создаем сегмент для записей после seq.
+// Вызывающий должен владеть mu.
+func (w *wal) openSegment() error {
+	path := filepath.Join(w.dir, segmentName(walPrefix, w.seq+1, walExt))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot open wal segment: %w", err)
+	}
+	err = syncDir(w.dir)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file = file
+	w.first = w.seq + 1
+	w.dirty = false
+	return nil
+}
+
+// closeSegment сбрасываем на диск и закрываем текущий сегмент.
+// Вызывающий должен владеть mu.
+func (w *wal) closeSegment() error {
+	err := w.file.Sync()
+	if errClose := w.file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("cannot close wal segment: %w", err)
+	}
+	return nil
+}
+
+// readSegment читаем записи сегмента.
+// Оборванная при сбое последняя строка последнего сегмента(last) отрезается,
+// в остальных случаях поврежденная строка - ошибка.
+func readSegment(path string, last bool) ([]walRecord, error) {
+	var records []walRecord
+	err := readFrames(path, 0, last, func(line []byte) error {
+		var rec walRecord
+		err := unframe(line, &rec)
+		if err != nil {
+			return err
+		}
+		records = append(records, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// readFrames передаем fn строки файла path по порядку, начиная со смещения from(начала строки).
+// Если truncateTorn, оборванная при сбое последняя строка отрезается, иначе поврежденная строка - ошибка.
+func readFrames(path string, from int64, truncateTorn bool, fn func(line []byte) error) error {
+	flag := os.O_RDONLY
+	if truncateTorn {
+		flag = os.O_RDWR
+	}
+	file, err := os.OpenFile(path, flag, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	offset := from
+	if from > 0 {
+		_, err = file.Seek(from, io.SeekStart)
+		if err != nil {
+			return fmt.Errorf("cannot seek %s: %w", path, err)
+		}
+	}
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return nil
+		}
+		if err == nil {
+			err = fn(line)
+		}
+		if err != nil {
+			if !truncateTorn || !torn(reader, err) {
+				return fmt.Errorf("%s at offset %d: %w", path, offset, err)
+			}
+			return truncate(file, offset)
+		}
+		offset += int64(len(line))
+	}
+}
+
+// torn оборвана ли запись сбоем: поврежденная строка последняя в файле.
+// Поврежденная строка, за которой есть данные, - порча файла, а не оборванная запись.
+func torn(reader *bufio.Reader, err error) bool {
+	if err == io.EOF {
+		return true
+	}
+	if !errors.Is(err, errCorruptedFrame) {
+		return false
+	}
+	_, errPeek := reader.Peek(1)
+	return errPeek == io.EOF
+}
+
+// truncate отрезаем оборванный конец файла.
+func truncate(file *os.File, offset int64) error {
+	err := file.Truncate(offset)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("cannot truncate %s: %w", file.Name(), err)
+	}
+	return nil
+}
+
+// frame строка файла: crc32 данных в hex, пробел, данные.
+func frame(data []byte) []byte {
+	out := make([]byte, 0, frameHeaderSize+len(data)+1)
+	out = append(out, fmt.Sprintf("%08x ", crc32.ChecksumIEEE(data))...)
+	out = append(out, data...)
+	return append(out, '\n')
+}
+
+// unframe проверяем контрольную сумму строки и декодируем ее данные в v.
+func unframe(line []byte, v interface{}) error {
+	data, err := frameData(line)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// frameData проверяем контрольную сумму строки и возвращаем ее данные без декодирования.
+func frameData(line []byte) ([]byte, error) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	if len(line) < frameHeaderSize || line[frameHeaderSize-1] != ' ' {
+		return nil, errCorruptedFrame
+	}
+	sum, err := strconv.ParseUint(string(line[:frameHeaderSize-1]), 16, 32)
+	if err != nil {
+		return nil, errCorruptedFrame
+	}
+	data := line[frameHeaderSize:]
+	if crc32.ChecksumIEEE(data) != uint32(sum) {
+		return nil, errCorruptedFrame
+	}
+	return data, nil
+}
+
+// segmentName имя файла с номером записи, имена упорядочиваются по номеру.
+func segmentName(prefix string, seq uint64, ext string) string {
+	return fmt.Sprintf("%s%020d%s", prefix, seq, ext)
+}
+
+// listSegments номера файлов с префиксом prefix по возрастанию.
+func listSegments(dir, prefix, ext string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read dir %s: %w", dir, err)
+	}
+
+	var seqs []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+// syncDir сбрасываем на диск каталог после создания, переименования и удаления файлов.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("cannot open dir %s: %w", dir, err)
+	}
+	defer d.Close()
+
+	err = d.Sync()
+	if err != nil {
+		return fmt.Errorf("cannot sync dir %s: %w", dir, err)
+	}
+	return nil
+}
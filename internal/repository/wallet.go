@@ -1,10 +1,123 @@
 package repository
 
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Nizom98/wallet/internal/models"
+)
+
+// wallet снимок состояния кошелька, наружу отдаются только копии.
 type wallet struct {
 	id      string
 	name    string
 	balance float64
-	status  bool
+	// creditLimit разрешенный овердрафт
+	creditLimit float64
+	status      bool
+	owner       string
+	createdAt   time.Time
+	updatedAt   time.Time
+	version     uint64
+	// metadata не изменяется после создания, поэтому копии кошелька разделяют одну карту
+	metadata map[string]string
+	// holds блокировки средств кошелька, включая истекшие до следующего изменения блокировок.
+	// Карта не изменяется на месте, а заменяется копией, поэтому копии кошелька разделяют ее.
+	holds map[string]models.Hold
+	// held сумма действующих блокировок на момент снимка
+	held float64
+}
+
+// record кошелек в хранилище вместе со своей блокировкой.
+type record struct {
+	// mu для конкурентного доступа к wallet
+	mu     sync.Mutex
+	wallet wallet
+	// ledger проводки кошелька в порядке записи
+	ledger []models.LedgerEntry
+}
+
+// snapshot копия кошелька на момент now, истекшие к этому моменту блокировки не учитываются.
+// Вызывающий должен владеть блокировкой записи(или эксклюзивной блокировкой хранилища).
+func (rec *record) snapshot(now time.Time) *wallet {
+	wal := rec.wallet
+	wal.held = 0
+	for _, hold := range wal.holds {
+		if now.Before(hold.ExpiresAt) {
+			wal.held += hold.Amount
+		}
+	}
+	return &wal
+}
+
+// hold блокировка кошелька на момент now, false если блокировка уже снята.
+// Вызывающий должен владеть блокировкой записи(или эксклюзивной блокировкой хранилища).
+func (rec *record) hold(id string, now time.Time) (models.Hold, bool) {
+	hold, ok := rec.wallet.holds[id]
+	if ok && !now.Before(hold.ExpiresAt) {
+		hold.Status = models.HoldStatusExpired
+	}
+	return hold, ok
+}
+
+// hasExpiredHolds есть ли у кошелька блокировки, истекшие к моменту now.
+// Вызывающий должен владеть блокировкой записи(или эксклюзивной блокировкой хранилища).
+func (rec *record) hasExpiredHolds(now time.Time) bool {
+	for _, hold := range rec.wallet.holds {
+		if !now.Before(hold.ExpiresAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// setHolds заменяем блокировки кошелька: к действующим на момент now блокировкам,
+// кроме removeID, добавляется add(если задана).
+// Изменение блокировок меняет доступный баланс, поэтому увеличивает версию кошелька.
+// Вызывающий должен владеть блокировкой записи(или эксклюзивной блокировкой хранилища).
+func (rec *record) setHolds(add *models.Hold, removeID string, now time.Time) {
+	wal := &rec.wallet
+	holds := make(map[string]models.Hold, len(wal.holds)+1)
+	for id, hold := range wal.holds {
+		if id != removeID && now.Before(hold.ExpiresAt) {
+			holds[id] = hold
+		}
+	}
+	if add != nil {
+		holds[add.ID] = *add
+	}
+	if len(holds) == 0 {
+		holds = nil
+	}
+
+	wal.holds = holds
+	wal.updatedAt = now
+	wal.version++
+}
+
+// update применяем изменения к кошельку.
+// Вызывающий должен владеть блокировкой записи(или эксклюзивной блокировкой хранилища).
+func (rec *record) update(upd models.WalletUpdate, now time.Time) error {
+	wal := &rec.wallet
+	if upd.ExpectedVersion != nil && *upd.ExpectedVersion != wal.version {
+		return fmt.Errorf("wallet %s has version %d, expected %d: %w", wal.id, wal.version, *upd.ExpectedVersion, models.ErrVersionMismatch)
+	}
+	if upd.Name != nil {
+		wal.name = *upd.Name
+	}
+	if upd.Balance != nil {
+		wal.balance = *upd.Balance
+	}
+	if upd.Status != nil {
+		wal.status = *upd.Status
+	}
+	if upd.CreditLimit != nil {
+		wal.creditLimit = *upd.CreditLimit
+	}
+	wal.updatedAt = now
+	wal.version++
+	return nil
 }
 
 func (wal *wallet) ID() string {
@@ -19,6 +132,46 @@ func (wal *wallet) Balance() float64 {
 	return wal.balance
 }
 
+func (wal *wallet) Held() float64 {
+	return wal.held
+}
+
+func (wal *wallet) CreditLimit() float64 {
+	return wal.creditLimit
+}
+
+func (wal *wallet) Available() float64 {
+	return wal.balance - wal.held + wal.creditLimit
+}
+
 func (wal *wallet) Status() bool {
 	return wal.status
 }
+
+func (wal *wallet) Owner() string {
+	return wal.owner
+}
+
+func (wal *wallet) CreatedAt() time.Time {
+	return wal.createdAt
+}
+
+func (wal *wallet) UpdatedAt() time.Time {
+	return wal.updatedAt
+}
+
+func (wal *wallet) Version() uint64 {
+	return wal.version
+}
+
+// Metadata копия меток кошелька.
+func (wal *wallet) Metadata() map[string]string {
+	if wal.metadata == nil {
+		return nil
+	}
+	out := make(map[string]string, len(wal.metadata))
+	for k, v := range wal.metadata {
+		out[k] = v
+	}
+	return out
+}